@@ -7,13 +7,19 @@ toolchain go1.24.6
 require (
 	github.com/go-chi/chi/v5 v5.2.3
 	github.com/go-chi/httprate v0.15.0
-	github.com/gorilla/mux v1.8.1
+	github.com/graphql-go/graphql v0.8.1
 	github.com/mattn/go-sqlite3 v1.14.22
-	golang.org/x/crypto v0.31.0
+	golang.org/x/crypto v0.39.0
+	golang.org/x/image v0.25.0
+	google.golang.org/grpc v1.75.0
+	google.golang.org/protobuf v1.36.9
 )
 
 require (
 	github.com/klauspost/cpuid/v2 v2.2.10 // indirect
 	github.com/zeebo/xxh3 v1.0.2 // indirect
-	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/net v0.41.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/text v0.26.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250707201910-8d1bb00bc6a7 // indirect
 )
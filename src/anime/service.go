@@ -1,10 +1,7 @@
 package anime
 
 import (
-	"encoding/json"
-	"fmt"
-	"math/rand"
-	"time"
+	"github.com/apimgr/quotes/src/collection"
 )
 
 // AnimeQuote represents a single anime quote
@@ -16,89 +13,76 @@ type AnimeQuote struct {
 	Category  string `json:"category"`
 }
 
-var (
-	quotes []AnimeQuote
-	rng    *rand.Rand
-)
-
-func init() {
-	// Initialize random number generator with seed
-	rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+// GetID returns the anime quote ID
+func (q AnimeQuote) GetID() int {
+	return q.ID
 }
 
-// LoadQuotes loads anime quotes from embedded JSON data
-func LoadQuotes(jsonData []byte) error {
-	err := json.Unmarshal(jsonData, &quotes)
-	if err != nil {
-		return fmt.Errorf("failed to parse anime.json: %w", err)
+// Field returns the value of a named anime quote field
+func (q AnimeQuote) Field(name string) string {
+	switch name {
+	case "quote":
+		return q.Quote
+	case "character":
+		return q.Character
+	case "anime":
+		return q.Anime
+	case "category":
+		return q.Category
 	}
+	return ""
+}
 
-	if len(quotes) == 0 {
-		return fmt.Errorf("no anime quotes found in anime.json")
-	}
+// Collection is the anime quotes collection
+var Collection = collection.New[AnimeQuote](collection.Info{
+	Name:      "anime",
+	Title:     "anime quotes",
+	ItemName:  "anime quote",
+	TextField: "quote",
+	Fields: []collection.Field{
+		{Name: "category", Path: "category"},
+		{Name: "anime", Path: "show"},
+		{Name: "character", Path: "character"},
+	},
+	Attribution: []string{"character", "anime"},
+})
 
-	return nil
+// LoadQuotes loads anime quotes from embedded JSON data
+func LoadQuotes(jsonData []byte) error {
+	return Collection.Load(jsonData)
 }
 
 // GetRandomQuote returns a random anime quote from the loaded quotes
 func GetRandomQuote() (*AnimeQuote, error) {
-	if len(quotes) == 0 {
-		return nil, fmt.Errorf("no anime quotes available, please load quotes first")
-	}
-
-	index := rng.Intn(len(quotes))
-	return &quotes[index], nil
+	return Collection.Random()
 }
 
 // GetAllQuotes returns all loaded anime quotes
 func GetAllQuotes() []AnimeQuote {
-	return quotes
+	return Collection.All()
 }
 
 // GetQuoteByID returns an anime quote by its ID
 func GetQuoteByID(id int) (*AnimeQuote, error) {
-	for _, quote := range quotes {
-		if quote.ID == id {
-			return &quote, nil
-		}
-	}
-	return nil, fmt.Errorf("anime quote with ID %d not found", id)
+	return Collection.ByID(id)
 }
 
 // GetQuotesByCategory returns all anime quotes in a specific category
 func GetQuotesByCategory(category string) []AnimeQuote {
-	var result []AnimeQuote
-	for _, quote := range quotes {
-		if quote.Category == category {
-			result = append(result, quote)
-		}
-	}
-	return result
+	return Collection.ByField("category", category)
 }
 
 // GetQuotesByAnime returns all quotes from a specific anime
 func GetQuotesByAnime(animeName string) []AnimeQuote {
-	var result []AnimeQuote
-	for _, quote := range quotes {
-		if quote.Anime == animeName {
-			result = append(result, quote)
-		}
-	}
-	return result
+	return Collection.ByField("anime", animeName)
 }
 
 // GetQuotesByCharacter returns all quotes by a specific character
 func GetQuotesByCharacter(character string) []AnimeQuote {
-	var result []AnimeQuote
-	for _, quote := range quotes {
-		if quote.Character == character {
-			result = append(result, quote)
-		}
-	}
-	return result
+	return Collection.ByField("character", character)
 }
 
 // GetTotalCount returns the total number of loaded anime quotes
 func GetTotalCount() int {
-	return len(quotes)
+	return Collection.Count()
 }
@@ -0,0 +1,61 @@
+package anime
+
+import (
+	"os"
+	"testing"
+)
+
+// loadTestQuotes loads the embedded anime dataset used by the server
+func loadTestQuotes(tb testing.TB) {
+	tb.Helper()
+
+	data, err := os.ReadFile("../data/anime.json")
+	if err != nil {
+		tb.Fatalf("failed to read anime.json: %v", err)
+	}
+	if err := LoadQuotes(data); err != nil {
+		tb.Fatalf("LoadQuotes failed: %v", err)
+	}
+}
+
+func TestGetQuotesByAnimeCaseInsensitive(t *testing.T) {
+	loadTestQuotes(t)
+
+	exact := GetQuotesByAnime("Dragon Ball Z")
+	if len(exact) == 0 {
+		t.Fatal("expected quotes for Dragon Ball Z")
+	}
+	if got := len(GetQuotesByAnime("dragon ball z")); got != len(exact) {
+		t.Errorf("lowercase lookup returned %d quotes, want %d", got, len(exact))
+	}
+}
+
+func BenchmarkGetQuoteByID(b *testing.B) {
+	loadTestQuotes(b)
+	total := GetTotalCount()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetQuoteByID(i%total + 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetQuotesByAnime(b *testing.B) {
+	loadTestQuotes(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GetQuotesByAnime("naruto")
+	}
+}
+
+func BenchmarkGetQuotesByCharacter(b *testing.B) {
+	loadTestQuotes(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GetQuotesByCharacter("Character 7")
+	}
+}
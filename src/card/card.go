@@ -0,0 +1,277 @@
+package card
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// maxScale is the largest number of image pixels per font pixel for the main text
+	maxScale = 12
+
+	// minScale is the smallest, below which the text is truncated instead
+	minScale = 2
+)
+
+// Size is an image size preset
+type Size struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+// Sizes lists the size presets, the first being the default
+var Sizes = []Size{
+	{"og", 1200, 630},
+	{"square", 1080, 1080},
+	{"story", 1080, 1920},
+}
+
+// Theme is a color scheme
+type Theme struct {
+	Name       string
+	Background color.RGBA
+	Text       color.RGBA
+	Accent     color.RGBA
+}
+
+// Themes lists the color schemes, the first being the default
+var Themes = []Theme{
+	{"light", color.RGBA{0xfa, 0xf8, 0xf5, 0xff}, color.RGBA{0x22, 0x22, 0x22, 0xff}, color.RGBA{0xc2, 0x41, 0x0c, 0xff}},
+	{"dark", color.RGBA{0x1a, 0x1b, 0x26, 0xff}, color.RGBA{0xe6, 0xe6, 0xe6, 0xff}, color.RGBA{0xf5, 0x9e, 0x0b, 0xff}},
+}
+
+// SizeByName returns the size preset with the given name
+func SizeByName(name string) (Size, bool) {
+	for _, s := range Sizes {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Size{}, false
+}
+
+// ThemeByName returns the theme with the given name
+func ThemeByName(name string) (Theme, bool) {
+	for _, t := range Themes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// Card is an image showing a text with its attribution
+type Card struct {
+	Text        string
+	Attribution string
+	Footer      string
+	Size        Size
+	Theme       Theme
+}
+
+// block is a run of wrapped lines drawn at one scale and color
+type block struct {
+	lines []string
+	x, y  int
+	scale int
+	color color.RGBA
+}
+
+// layout positions the text, attribution and footer of a card. The text
+// gets the largest scale at which it fits without splitting words; when
+// even the smallest scale is too large, the text is cut and ends with an
+// ellipsis.
+func (c Card) layout() (blocks []block, bar image.Rectangle) {
+	w, h := c.Size.Width, c.Size.Height
+	pad := w / 12
+	footerScale := max(minScale, w/300)
+	footerHeight := 2 * lineHeight * footerScale
+	available := h - 2*pad - footerHeight
+
+	longest := 0
+	for _, word := range strings.Fields(c.Text) {
+		longest = max(longest, utf8.RuneCountInString(word))
+	}
+
+	var text, attribution block
+	for scale := maxScale; scale >= minScale; scale-- {
+		cols := (w - 2*pad) / (advance * scale)
+		if longest > cols && scale > minScale {
+			continue
+		}
+		text = block{lines: wrap(c.Text, cols), scale: scale, color: c.Theme.Text}
+		attribution = block{scale: max(minScale, scale*2/3), color: c.Theme.Accent}
+		if c.Attribution != "" {
+			attribution.lines = wrap("— "+c.Attribution, (w-2*pad)/(advance*attribution.scale))
+		}
+		if text.height()+attribution.gap()+attribution.height() <= available {
+			break
+		}
+	}
+
+	// Cut the text if it does not fit even at the smallest scale
+	for len(text.lines) > 1 && text.height()+attribution.gap()+attribution.height() > available {
+		text.lines = text.lines[:len(text.lines)-1]
+		last := []rune(text.lines[len(text.lines)-1])
+		if len(last) > 1 {
+			last = last[:len(last)-1]
+		}
+		text.lines[len(text.lines)-1] = strings.TrimRightFunc(string(last), unicode.IsSpace) + "…"
+	}
+
+	// Center the text and attribution vertically above the footer
+	total := text.height() + attribution.gap() + attribution.height()
+	text.x, text.y = pad, pad+(available-total)/2
+	attribution.x, attribution.y = pad, text.y+text.height()+attribution.gap()
+	bar = image.Rect(pad-3*text.scale, text.y, pad-2*text.scale, text.y+text.height())
+
+	footer := block{
+		lines: []string{c.Footer},
+		x:     pad,
+		y:     h - pad/2 - capHeight*footerScale,
+		scale: footerScale,
+		color: c.Theme.Accent,
+	}
+	return []block{text, attribution, footer}, bar
+}
+
+// height returns the height of the block in image pixels
+func (b block) height() int {
+	if len(b.lines) == 0 {
+		return 0
+	}
+	return (len(b.lines)-1)*lineHeight*b.scale + capHeight*b.scale
+}
+
+// gap returns the space left above the block when it has lines
+func (b block) gap() int {
+	if len(b.lines) == 0 {
+		return 0
+	}
+	return 2 * lineHeight * b.scale
+}
+
+// wrap splits text into lines of at most cols characters, breaking at
+// spaces and splitting words longer than a line
+func wrap(text string, cols int) []string {
+	cols = max(cols, 1)
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		var line []rune
+		for _, word := range strings.Fields(paragraph) {
+			runes := []rune(word)
+			for len(runes) > cols {
+				if len(line) > 0 {
+					lines = append(lines, string(line))
+					line = nil
+				}
+				lines = append(lines, string(runes[:cols]))
+				runes = runes[cols:]
+			}
+			switch {
+			case len(line) == 0:
+				line = runes
+			case len(line)+1+len(runes) <= cols:
+				line = append(append(line, ' '), runes...)
+			default:
+				lines = append(lines, string(line))
+				line = runes
+			}
+		}
+		if len(line) > 0 {
+			lines = append(lines, string(line))
+		}
+	}
+	return lines
+}
+
+// hexColor returns a color in CSS hex notation
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// SVG renders the card as an SVG document
+func (c Card) SVG() []byte {
+	blocks, bar := c.layout()
+	w, h := c.Size.Width, c.Size.Height
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" role="img">`+"\n", w, h, w, h)
+	title := c.Text
+	if c.Attribution != "" {
+		title += "\n— " + c.Attribution
+	}
+	b.WriteString("<title>")
+	xml.EscapeText(&b, []byte(title))
+	b.WriteString("</title>\n")
+	fmt.Fprintf(&b, "<defs><style>@font-face { font-family: '%s'; src: url(%s) format('truetype'); }</style></defs>\n",
+		fontFamily, fontDataURL())
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="%s"/>`+"\n", w, h, hexColor(c.Theme.Background))
+	fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+		bar.Min.X, bar.Min.Y, bar.Dx(), bar.Dy(), hexColor(c.Theme.Accent))
+
+	for _, blk := range blocks {
+		if len(blk.lines) == 0 {
+			continue
+		}
+		// The baseline sits at the bottom of the capital letters
+		fmt.Fprintf(&b, `<text font-family="'%s', monospace" font-size="%d" fill="%s" xml:space="preserve">`,
+			fontFamily, emSize*blk.scale, hexColor(blk.color))
+		for i, line := range blk.lines {
+			fmt.Fprintf(&b, `<tspan x="%d" y="%d">`, blk.x, blk.y+(i*lineHeight+capHeight)*blk.scale)
+			xml.EscapeText(&b, []byte(line))
+			b.WriteString("</tspan>")
+		}
+		b.WriteString("</text>\n")
+	}
+
+	b.WriteString("</svg>\n")
+	return b.Bytes()
+}
+
+// PNG renders the card as a PNG image with the embedded font
+func (c Card) PNG() ([]byte, error) {
+	blocks, bar := c.layout()
+
+	img := image.NewRGBA(image.Rect(0, 0, c.Size.Width, c.Size.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c.Theme.Background), image.Point{}, draw.Src)
+	draw.Draw(img, bar, image.NewUniform(c.Theme.Accent), image.Point{}, draw.Src)
+
+	for _, blk := range blocks {
+		if len(blk.lines) == 0 {
+			continue
+		}
+		face, err := newFace(blk.scale)
+		if err != nil {
+			return nil, err
+		}
+		d := font.Drawer{Dst: img, Src: image.NewUniform(blk.color), Face: face}
+		for i, line := range blk.lines {
+			// Every character takes one cell, even where the font lacks it
+			y := blk.y + (i*lineHeight+capHeight)*blk.scale
+			for j, r := range []rune(line) {
+				d.Dot = fixed.P(blk.x+j*advance*blk.scale, y)
+				d.DrawString(string(r))
+			}
+		}
+		face.Close()
+	}
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, fmt.Errorf("failed to encode card: %w", err)
+	}
+	return b.Bytes(), nil
+}
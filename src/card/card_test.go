@@ -0,0 +1,110 @@
+package card
+
+import (
+	"bytes"
+	"image/png"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		text string
+		cols int
+		want []string
+	}{
+		{"the quick brown fox", 10, []string{"the quick", "brown fox"}},
+		{"  spaced   out  ", 20, []string{"spaced out"}},
+		{"first\nsecond line", 20, []string{"first", "second line"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"to abcdefgh", 4, []string{"to", "abcd", "efgh"}},
+		{"", 10, nil},
+	}
+
+	for _, tt := range tests {
+		if got := wrap(tt.text, tt.cols); !slices.Equal(got, tt.want) {
+			t.Errorf("wrap(%q, %d) = %q, want %q", tt.text, tt.cols, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutFits(t *testing.T) {
+	long := strings.Repeat("All work and no play makes Jack a dull boy. ", 200)
+	for _, size := range Sizes {
+		for _, text := range []string{"Short.", long} {
+			c := Card{Text: text, Attribution: "Someone", Footer: "Quotes", Size: size, Theme: Themes[0]}
+			blocks, _ := c.layout()
+			for _, b := range blocks {
+				if b.y < 0 || b.y+b.height() > size.Height {
+					t.Errorf("%s: block %q overflows the image height", size.Name, b.lines)
+				}
+				for _, line := range b.lines {
+					if right := b.x + len([]rune(line))*advance*b.scale; right > size.Width {
+						t.Errorf("%s: line %q overflows the image width", size.Name, line)
+					}
+				}
+			}
+			if text == long && !strings.HasSuffix(blocks[0].lines[len(blocks[0].lines)-1], "…") {
+				t.Errorf("%s: truncated text does not end with an ellipsis", size.Name)
+			}
+		}
+	}
+}
+
+func TestPNG(t *testing.T) {
+	size, _ := SizeByName("square")
+	theme, _ := ThemeByName("dark")
+	data, err := Card{Text: "Hello, world", Attribution: "Someone", Size: size, Theme: theme}.PNG()
+	if err != nil {
+		t.Fatalf("PNG() error = %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to decode card: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != size.Width || b.Dy() != size.Height {
+		t.Errorf("card is %dx%d, want %dx%d", b.Dx(), b.Dy(), size.Width, size.Height)
+	}
+	if got := img.At(0, 0); got != theme.Background {
+		t.Errorf("background = %v, want %v", got, theme.Background)
+	}
+}
+
+func TestSVGEscapes(t *testing.T) {
+	svg := string(Card{Text: "1 < 2 & <b>bold</b>", Size: Sizes[0], Theme: Themes[0]}.SVG())
+	if strings.Contains(svg, "<b>") {
+		t.Errorf("SVG contains unescaped markup: %s", svg)
+	}
+	if !strings.Contains(svg, "1 &lt; 2 &amp; &lt;b&gt;bold&lt;/b&gt;") {
+		t.Errorf("SVG does not contain the escaped text: %s", svg)
+	}
+}
+
+func TestPNGDrawsNonASCII(t *testing.T) {
+	render := func(text string) []byte {
+		data, err := Card{Text: text, Size: Sizes[0], Theme: Themes[0]}.PNG()
+		if err != nil {
+			t.Fatalf("PNG() error = %v", err)
+		}
+		return data
+	}
+
+	// Each of these was drawn as a question mark or an unaccented letter
+	question, plain := render("?"), render("e")
+	for _, text := range []string{"é", "ж", "λ"} {
+		if data := render(text); bytes.Equal(data, question) || bytes.Equal(data, plain) {
+			t.Errorf("%q is not drawn with its own glyph", text)
+		}
+	}
+}
+
+func TestSVGEmbedsFont(t *testing.T) {
+	svg := string(Card{Text: "Crème brûlée", Size: Sizes[0], Theme: Themes[0]}.SVG())
+	for _, want := range []string{"@font-face", "font-family: 'Go Mono'", "data:font/ttf;base64,", `font-family="'Go Mono', monospace"`, "Crème brûlée"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG does not contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package card
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+)
+
+// Cards are drawn in Go Mono, a monospaced font covering Latin, Greek and
+// Cyrillic, embedded in both the binary and the SVG cards. Lengths are in
+// font pixels, of which there are emSize per em.
+const (
+	// fontFamily is the name the SVG cards give the embedded font
+	fontFamily = "Go Mono"
+
+	// emSize is the font size in font pixels
+	emSize = 10
+
+	// advance is the horizontal distance between characters: Go Mono
+	// advances 0.6em per character
+	advance = 6
+
+	// capHeight is the height of capital letters above the baseline
+	capHeight = 7
+
+	// lineHeight is the vertical distance between baselines
+	lineHeight = capHeight + 4
+)
+
+// monoFont parses the embedded Go Mono font once
+var monoFont = sync.OnceValues(func() (*opentype.Font, error) {
+	return opentype.Parse(gomono.TTF)
+})
+
+// fontDataURL is the embedded font as a data URL, for @font-face rules
+var fontDataURL = sync.OnceValue(func() string {
+	return "data:font/ttf;base64," + base64.StdEncoding.EncodeToString(gomono.TTF)
+})
+
+// newFace returns a Go Mono face drawing text at scale image pixels per
+// font pixel. Faces are not safe for concurrent use.
+func newFace(scale int) (font.Face, error) {
+	f, err := monoFont()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: float64(emSize * scale), DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load font: %w", err)
+	}
+	return face, nil
+}
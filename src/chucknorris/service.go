@@ -1,10 +1,7 @@
 package chucknorris
 
 import (
-	"encoding/json"
-	"fmt"
-	"math/rand"
-	"time"
+	"github.com/apimgr/quotes/src/collection"
 )
 
 // Joke represents a Chuck Norris joke
@@ -14,55 +11,54 @@ type Joke struct {
 	Category string `json:"category"`
 }
 
-var (
-	jokes []Joke
-	rng   *rand.Rand
-)
-
-func init() {
-	rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+// GetID returns the joke ID
+func (j Joke) GetID() int {
+	return j.ID
 }
 
-// LoadJokes loads Chuck Norris jokes from embedded JSON data
-func LoadJokes(jsonData []byte) error {
-	err := json.Unmarshal(jsonData, &jokes)
-	if err != nil {
-		return fmt.Errorf("failed to parse chucknorris.json: %w", err)
+// Field returns the value of a named joke field
+func (j Joke) Field(name string) string {
+	switch name {
+	case "joke":
+		return j.Joke
+	case "category":
+		return j.Category
 	}
+	return ""
+}
 
-	if len(jokes) == 0 {
-		return fmt.Errorf("no Chuck Norris jokes found")
-	}
+// Collection is the Chuck Norris jokes collection
+var Collection = collection.New[Joke](collection.Info{
+	Name:      "chucknorris",
+	Title:     "Chuck Norris jokes",
+	ItemName:  "Chuck Norris joke",
+	TextField: "joke",
+	Fields: []collection.Field{
+		{Name: "category", Path: "category"},
+	},
+})
 
-	return nil
+// LoadJokes loads Chuck Norris jokes from embedded JSON data
+func LoadJokes(jsonData []byte) error {
+	return Collection.Load(jsonData)
 }
 
 // GetRandomJoke returns a random Chuck Norris joke
 func GetRandomJoke() (*Joke, error) {
-	if len(jokes) == 0 {
-		return nil, fmt.Errorf("no jokes available")
-	}
-
-	index := rng.Intn(len(jokes))
-	return &jokes[index], nil
+	return Collection.Random()
 }
 
 // GetAllJokes returns all Chuck Norris jokes
 func GetAllJokes() []Joke {
-	return jokes
+	return Collection.All()
 }
 
 // GetJokeByID returns a joke by its ID
 func GetJokeByID(id int) (*Joke, error) {
-	for _, joke := range jokes {
-		if joke.ID == id {
-			return &joke, nil
-		}
-	}
-	return nil, fmt.Errorf("joke with ID %d not found", id)
+	return Collection.ByID(id)
 }
 
 // GetTotalCount returns the total number of jokes
 func GetTotalCount() int {
-	return len(jokes)
+	return Collection.Count()
 }
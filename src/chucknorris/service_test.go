@@ -0,0 +1,106 @@
+package chucknorris
+
+import (
+	"os"
+	"testing"
+)
+
+// loadTestJokes loads the embedded Chuck Norris jokes dataset used by the server
+func loadTestJokes(tb testing.TB) {
+	tb.Helper()
+
+	data, err := os.ReadFile("../data/chucknorris.json")
+	if err != nil {
+		tb.Fatalf("failed to read chucknorris.json: %v", err)
+	}
+	if err := LoadJokes(data); err != nil {
+		tb.Fatalf("LoadJokes failed: %v", err)
+	}
+}
+
+func TestLoadJokes(t *testing.T) {
+	loadTestJokes(t)
+
+	if GetTotalCount() == 0 {
+		t.Fatal("expected jokes to be loaded")
+	}
+	if got := len(GetAllJokes()); got != GetTotalCount() {
+		t.Errorf("GetAllJokes returned %d jokes, want %d", got, GetTotalCount())
+	}
+	for _, joke := range GetAllJokes() {
+		if joke.ID == 0 || joke.Joke == "" || joke.Category == "" {
+			t.Fatalf("joke has missing fields: %+v", joke)
+		}
+	}
+}
+
+func TestLoadJokesInvalid(t *testing.T) {
+	if err := LoadJokes([]byte("not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+	if err := LoadJokes([]byte("[]")); err == nil {
+		t.Error("expected error for empty dataset")
+	}
+}
+
+func TestGetRandomJoke(t *testing.T) {
+	loadTestJokes(t)
+
+	joke, err := GetRandomJoke()
+	if err != nil {
+		t.Fatalf("GetRandomJoke failed: %v", err)
+	}
+	if joke.Joke == "" {
+		t.Error("expected a non-empty joke")
+	}
+}
+
+func TestGetJokeByID(t *testing.T) {
+	loadTestJokes(t)
+
+	joke, err := GetJokeByID(42)
+	if err != nil {
+		t.Fatalf("GetJokeByID failed: %v", err)
+	}
+	if joke.ID != 42 {
+		t.Errorf("got joke ID %d, want 42", joke.ID)
+	}
+
+	if _, err := GetJokeByID(-1); err == nil {
+		t.Error("expected error for unknown ID")
+	}
+}
+
+func TestJokeFields(t *testing.T) {
+	loadTestJokes(t)
+
+	joke, err := GetJokeByID(1)
+	if err != nil {
+		t.Fatalf("GetJokeByID failed: %v", err)
+	}
+	if joke.Field("joke") != joke.Joke || joke.Field("category") != joke.Category || joke.Field("author") != "" {
+		t.Errorf("unexpected fields of %+v", joke)
+	}
+
+	result := Collection.ByField("category", "power")
+	if len(result) == 0 {
+		t.Fatal("expected jokes in category power")
+	}
+	for _, joke := range result {
+		if joke.Category != "power" {
+			t.Errorf("joke %d has category %q, want power", joke.ID, joke.Category)
+		}
+	}
+}
+
+func BenchmarkGetJokeByID(b *testing.B) {
+	loadTestJokes(b)
+	total := GetTotalCount()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetJokeByID(i%total + 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package collection
+
+import (
+	"strings"
+	"time"
+
+	"github.com/apimgr/quotes/src/search"
+)
+
+// Item is implemented by every entry type stored in a collection
+type Item interface {
+	// GetID returns the unique ID of the item
+	GetID() int
+
+	// Field returns the value of a named string field, or "" if the item has no such field
+	Field(name string) string
+}
+
+// Times records when an item was added and last changed. Items of the
+// embedded datasets have no known times and leave both zero.
+type Times struct {
+	Created time.Time
+	Updated time.Time
+}
+
+// Facet is a distinct field value and the number of items that have it
+type Facet = search.Entry
+
+// Field describes a filterable string field of a collection
+type Field struct {
+	Name string `json:"name"` // JSON field name, e.g. "author"
+	Path string `json:"path"` // Route segment, e.g. "show" for the anime field
+}
+
+// Info describes a collection and how it is exposed by the API
+type Info struct {
+	Name      string  `json:"name"`       // Route and file name, e.g. "anime"
+	Title     string  `json:"title"`      // Plural display name, e.g. "anime quotes"
+	ItemName  string  `json:"item_name"`  // Singular display name, e.g. "anime quote"
+	TextField string  `json:"text_field"` // Field holding the main text, e.g. "quote"
+	Fields    []Field `json:"fields"`     // Filterable fields
+
+	// Attribution lists the fields naming the source of an item, e.g. the
+	// author of a quote, in the order they are cited
+	Attribution []string `json:"attribution,omitempty"`
+}
+
+// Collection is the type-independent view of a dataset used by the server
+type Collection interface {
+	// Info returns the description of the collection
+	Info() Info
+
+	// Load replaces the collection contents with the items in jsonData
+	Load(jsonData []byte) error
+
+	// Put adds or replaces one item given as JSON, returning the stored item
+	// and whether it was added
+	Put(jsonData []byte) (Item, bool, error)
+
+	// PutAt is Put at a given time, for restoring items saved earlier.
+	// created only applies when the item is added.
+	PutAt(jsonData []byte, created, updated time.Time) (Item, bool, error)
+
+	// Times returns when the item with the given ID was added and last changed
+	Times(id int) Times
+
+	// Count returns the number of loaded items
+	Count() int
+
+	// LoadedAt returns when the items were last loaded or changed
+	LoadedAt() time.Time
+
+	// RandomItem returns a random item
+	RandomItem() (Item, error)
+
+	// Items returns all loaded items
+	Items() []Item
+
+	// ItemByID returns the item with the given ID
+	ItemByID(id int) (Item, error)
+
+	// ItemsByField returns all items whose field matches value, ignoring case,
+	// diacritics and punctuation
+	ItemsByField(field, value string) []Item
+
+	// SimilarValues returns up to max field values that are close to value,
+	// for "did you mean" suggestions
+	SimilarValues(field, value string, max int) []string
+
+	// Facets returns every distinct value of a field with its item count
+	Facets(field string) []Facet
+
+	// Suggest returns up to max field values with a word starting with prefix
+	Suggest(field, prefix string, max int) []Facet
+
+	// Search returns items matching a full-text query ordered by relevance,
+	// and the index terms that matched
+	Search(query string) ([]SearchHit, []string)
+}
+
+// HasField reports whether the collection info declares the named field
+func (i Info) HasField(name string) bool {
+	for _, f := range i.Fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AttributionOf returns the attribution of an item, e.g. "Naruto Uzumaki, Naruto",
+// or "" if the collection has none or the item leaves it empty
+func (i Info) AttributionOf(item Item) string {
+	var parts []string
+	for _, name := range i.Attribution {
+		if value := item.Field(name); value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FieldByName returns the filterable field with the given name or route path
+func (i Info) FieldByName(name string) (Field, bool) {
+	for _, f := range i.Fields {
+		if f.Name == name || f.Path == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
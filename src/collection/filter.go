@@ -0,0 +1,101 @@
+package collection
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Filter restricts which items are eligible for selection
+type Filter struct {
+	Fields     map[string]string // Field name to required value, matched like ItemsByField
+	MinLength  int               // Minimum text length in characters, 0 for no minimum
+	MaxLength  int               // Maximum text length in characters, 0 for no maximum
+	ExcludeIDs map[int]bool      // IDs that must not be selected
+}
+
+// IsEmpty reports whether the filter accepts every item
+func (f Filter) IsEmpty() bool {
+	return len(f.Fields) == 0 && f.MinLength == 0 && f.MaxLength == 0 && len(f.ExcludeIDs) == 0
+}
+
+// Matches reports whether an item of the collection passes the filter
+func (f Filter) Matches(info Info, item Item) bool {
+	if f.ExcludeIDs[item.GetID()] {
+		return false
+	}
+
+	for name, value := range f.Fields {
+		if indexKey(item.Field(name)) != indexKey(value) {
+			return false
+		}
+	}
+
+	if f.MinLength > 0 || f.MaxLength > 0 {
+		length := utf8.RuneCountInString(item.Field(info.TextField))
+		if f.MinLength > 0 && length < f.MinLength {
+			return false
+		}
+		if f.MaxLength > 0 && length > f.MaxLength {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FilterItems returns the items of c that pass the filter. Field constraints
+// are answered from the collection's field indexes before the remaining
+// checks run, so the cost depends on the narrowest field rather than the corpus.
+func FilterItems(c Collection, f Filter) []Item {
+	candidates := c.Items()
+	for name, value := range f.Fields {
+		if matches := c.ItemsByField(name, value); len(matches) < len(candidates) {
+			candidates = matches
+		}
+	}
+
+	// The narrowest field index already answers a single-field filter
+	if len(f.Fields) <= 1 && f.MinLength == 0 && f.MaxLength == 0 && len(f.ExcludeIDs) == 0 {
+		return candidates
+	}
+
+	info := c.Info()
+	result := make([]Item, 0, len(candidates))
+	for _, item := range candidates {
+		if f.Matches(info, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Key returns a canonical string for the filter, equal for equal filters
+func (f Filter) Key() string {
+	var b strings.Builder
+
+	names := make([]string, 0, len(f.Fields))
+	for name := range f.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=%s;", name, indexKey(f.Fields[name]))
+	}
+
+	if f.MinLength > 0 || f.MaxLength > 0 {
+		fmt.Fprintf(&b, "length=%d-%d;", f.MinLength, f.MaxLength)
+	}
+
+	if len(f.ExcludeIDs) > 0 {
+		ids := make([]int, 0, len(f.ExcludeIDs))
+		for id := range f.ExcludeIDs {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		fmt.Fprintf(&b, "exclude=%v;", ids)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package collection
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// Period is a span of time during which a periodic item stays the same
+type Period string
+
+const (
+	Hourly Period = "hourly"
+	Daily  Period = "daily"
+	Weekly Period = "weekly"
+)
+
+// Periods lists the supported periods
+var Periods = []Period{Hourly, Daily, Weekly}
+
+// Bounds returns the start and end of the period containing t, in t's location.
+// Weeks start on Monday.
+func (p Period) Bounds(t time.Time) (time.Time, time.Time) {
+	switch p {
+	case Hourly:
+		start := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+		return start, start.Add(time.Hour)
+	case Weekly:
+		offset := (int(t.Weekday()) + 6) % 7
+		start := time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 0, 7)
+	default:
+		start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 0, 1)
+	}
+}
+
+// Key returns a stable name for the period containing t, in t's location,
+// e.g. "2025-10-14T09" (hourly), "2025-10-14" (daily) or "2025-W42" (weekly)
+func (p Period) Key(t time.Time) string {
+	switch p {
+	case Hourly:
+		return t.Format("2006-01-02T15")
+	case Weekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	default:
+		return t.Format("2006-01-02")
+	}
+}
+
+// periodSeed returns the hash of a period of a collection that item scores build on
+func periodSeed(name string, p Period, key string) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s:%s:%s", name, p, key)
+	return h.Sum64()
+}
+
+// periodScore returns the score of an item ID for a period: a splitmix64 mix
+// of the period seed and the ID
+func periodScore(seed uint64, id int) uint64 {
+	z := seed + uint64(id)*0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// periodPick returns the item chosen for the period of c starting at start
+// among candidates: the one with the lowest score for its ID. The choice
+// depends only on the collection name, period, key and item IDs, never on a
+// random generator or the order of the items, so every server instance and
+// every client agree on it. Items added after the period started take no
+// part, so adding items never changes the pick of a current or past period.
+func periodPick(c Collection, candidates []Item, p Period, key string, start time.Time) Item {
+	seed := periodSeed(c.Info().Name, p, key)
+	var best Item
+	var bestScore uint64
+	for _, item := range candidates {
+		if created := c.Times(item.GetID()).Created; created.After(start) {
+			continue
+		}
+		score := periodScore(seed, item.GetID())
+		if best == nil || score < bestScore || score == bestScore && item.GetID() < best.GetID() {
+			best, bestScore = item, score
+		}
+	}
+	return best
+}
+
+// PeriodicItem returns the item of c for the period containing t
+func PeriodicItem(c Collection, p Period, t time.Time) (Item, error) {
+	start, _ := p.Bounds(t)
+	item := periodPick(c, c.Items(), p, p.Key(t), start)
+	if item == nil {
+		return nil, fmt.Errorf("no %s available", c.Info().Title)
+	}
+	return item, nil
+}
+
+// PeriodicMatch returns the item for the period containing t among the items
+// of c that pass the filter. With an empty filter it is the same as PeriodicItem.
+func PeriodicMatch(c Collection, f Filter, p Period, t time.Time) (Item, error) {
+	start, _ := p.Bounds(t)
+	item := periodPick(c, FilterItems(c, f), p, p.Key(t), start)
+	if item == nil {
+		return nil, ErrNoMatch
+	}
+	return item, nil
+}
@@ -0,0 +1,107 @@
+package collection
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPeriodicItemStableAcrossAppend(t *testing.T) {
+	store := newTestStore(t, 200)
+	now := time.Now().UTC()
+	var times []time.Time
+	for day := -60; day <= 0; day++ {
+		times = append(times, now.AddDate(0, 0, day))
+	}
+
+	picks := make(map[time.Time]int)
+	for _, at := range times {
+		item, err := PeriodicItem(store, Daily, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		picks[at] = item.GetID()
+	}
+
+	// Items added now take no part in today's or earlier picks
+	for i := 0; i < 50; i++ {
+		if _, _, err := store.Put([]byte(fmt.Sprintf(`{"text": "appended %d"}`, i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, at := range times {
+		if item, _ := PeriodicItem(store, Daily, at); item.GetID() != picks[at] {
+			t.Errorf("%s: pick changed from %d to %d after items were added", Daily.Key(at), picks[at], item.GetID())
+		}
+	}
+
+	// Items added to the dataset itself have no creation time, so a day may
+	// move to one of them, but never to another old item
+	items := make([]testItem, 400)
+	for i := range items {
+		items[i] = testItem{ID: i + 1, Text: fmt.Sprintf("item %d", i+1)}
+	}
+	data, _ := json.Marshal(items)
+	if err := store.Load(data); err != nil {
+		t.Fatal(err)
+	}
+	moved := 0
+	for _, at := range times {
+		item, _ := PeriodicItem(store, Daily, at)
+		if item.GetID() != picks[at] {
+			moved++
+			if item.GetID() <= 200 {
+				t.Errorf("%s: pick moved from %d to old item %d", Daily.Key(at), picks[at], item.GetID())
+			}
+		}
+	}
+	// Half the candidates are new, so about half the days move
+	if moved == 0 || moved == len(times) {
+		t.Errorf("%d of %d days moved to new items", moved, len(times))
+	}
+}
+
+func TestPeriodicItemSpread(t *testing.T) {
+	store := newTestStore(t, 20)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	counts := make(map[int]int)
+	for hour := 0; hour < 2000; hour++ {
+		item, err := PeriodicItem(store, Hourly, start.Add(time.Duration(hour)*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[item.GetID()]++
+	}
+	for id := 1; id <= 20; id++ {
+		if counts[id] < 50 || counts[id] > 150 {
+			t.Errorf("item %d picked for %d of 2000 hours, want about 100", id, counts[id])
+		}
+	}
+
+	// Every instance agrees, whatever the order of the items
+	at := start.AddDate(0, 3, 0)
+	a, _ := PeriodicItem(store, Weekly, at)
+	items := store.Items()
+	reversed := make([]Item, len(items))
+	for i, item := range items {
+		reversed[len(items)-1-i] = item
+	}
+	if b := periodPick(store, reversed, Weekly, Weekly.Key(at), at); b.GetID() != a.GetID() {
+		t.Errorf("pick depends on item order: %d and %d", a.GetID(), b.GetID())
+	}
+}
+
+func TestPeriodicMatch(t *testing.T) {
+	store := newTestStore(t, 100)
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	f := Filter{Fields: map[string]string{"category": "category 3"}}
+	item, err := PeriodicMatch(store, f, Daily, at)
+	if err != nil || item.Field("category") != "Category 3" {
+		t.Fatalf("PeriodicMatch = %v, %v", item, err)
+	}
+
+	if _, err := PeriodicMatch(store, Filter{Fields: map[string]string{"category": "none"}}, Daily, at); err != ErrNoMatch {
+		t.Errorf("PeriodicMatch with no candidates = %v, want ErrNoMatch", err)
+	}
+}
@@ -0,0 +1,84 @@
+package collection
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldNames returns every field an item of the collection exposes,
+// starting with id and the text field
+func (i Info) FieldNames() []string {
+	names := []string{"id", i.TextField}
+	for _, f := range i.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// HasFieldName reports whether name is one of the collection's field names
+func (i Info) HasFieldName(name string) bool {
+	for _, n := range i.FieldNames() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SortKeys returns the keys items of the collection can be sorted by
+func (i Info) SortKeys() []string {
+	return append(i.FieldNames(), "length")
+}
+
+// SortItems returns a copy of items ordered by key. Valid keys are the
+// collection's field names and "length", the text length in characters.
+// Ties are broken by ID so the order is stable across requests.
+func SortItems(info Info, items []Item, key string, desc bool) ([]Item, error) {
+	var less func(a, b Item) int
+	switch {
+	case key == "id":
+		less = func(a, b Item) int { return a.GetID() - b.GetID() }
+	case key == "length":
+		less = func(a, b Item) int {
+			return utf8.RuneCountInString(a.Field(info.TextField)) - utf8.RuneCountInString(b.Field(info.TextField))
+		}
+	case info.HasFieldName(key):
+		less = func(a, b Item) int {
+			return strings.Compare(strings.ToLower(a.Field(key)), strings.ToLower(b.Field(key)))
+		}
+	default:
+		return nil, fmt.Errorf("cannot sort by %q, valid keys are: %s", key, strings.Join(info.SortKeys(), ", "))
+	}
+
+	sorted := make([]Item, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		c := less(sorted[i], sorted[j])
+		if c == 0 {
+			return sorted[i].GetID() < sorted[j].GetID()
+		}
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+	return sorted, nil
+}
+
+// SelectFields returns the requested fields of item as a map suitable for encoding
+func SelectFields(info Info, item Item, fields []string) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(fields))
+	for _, name := range fields {
+		switch {
+		case name == "id":
+			result["id"] = item.GetID()
+		case info.HasFieldName(name):
+			result[name] = item.Field(name)
+		default:
+			return nil, fmt.Errorf("unknown field %q, valid fields are: %s", name, strings.Join(info.FieldNames(), ", "))
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package collection
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+)
+
+// ErrNoMatch is returned when no item passes a filter
+var ErrNoMatch = errors.New("no items match the filters")
+
+// intN returns a random int in [0, n) from rng, or from the shared
+// concurrency-safe generator when rng is nil
+func intN(rng *rand.Rand, n int) int {
+	if rng == nil {
+		return rand.IntN(n)
+	}
+	return rng.IntN(n)
+}
+
+// NewSeededRand returns a generator whose output depends only on seed
+func NewSeededRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+}
+
+// NewSeed returns a random seed that round-trips through JSON numbers
+func NewSeed() uint64 {
+	return rand.Uint64() >> 11
+}
+
+// Sample returns up to count distinct random items from items. A nil rng
+// uses the shared generator; a seeded rng makes the selection reproducible.
+func Sample(items []Item, count int, rng *rand.Rand) []Item {
+	if count > len(items) {
+		count = len(items)
+	}
+
+	// Few picks from many items: draw indexes and skip repeats
+	if count*4 < len(items) {
+		seen := make(map[int]bool, count)
+		result := make([]Item, 0, count)
+		for len(result) < count {
+			i := intN(rng, len(items))
+			if seen[i] {
+				continue
+			}
+			seen[i] = true
+			result = append(result, items[i])
+		}
+		return result
+	}
+
+	// Otherwise run a partial Fisher-Yates shuffle over a copy
+	shuffled := make([]Item, len(items))
+	copy(shuffled, items)
+	for i := 0; i < count; i++ {
+		j := i + intN(rng, len(shuffled)-i)
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	}
+	return shuffled[:count]
+}
+
+// RandomItems returns up to count distinct random items of c that pass the filter.
+// The same seeded rng state and filter always select the same items.
+func RandomItems(c Collection, f Filter, count int, rng *rand.Rand) ([]Item, error) {
+	if c.Count() == 0 {
+		return nil, fmt.Errorf("no %s available", c.Info().Title)
+	}
+
+	candidates := FilterItems(c, f)
+	if len(candidates) == 0 {
+		return nil, ErrNoMatch
+	}
+
+	return Sample(candidates, count, rng), nil
+}
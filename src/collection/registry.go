@@ -0,0 +1,49 @@
+package collection
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	registry      []Collection
+	registryIndex = make(map[string]Collection)
+	registryMutex sync.RWMutex
+)
+
+// Register adds a collection to the registry so the server exposes it
+func Register(c Collection) error {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	name := c.Info().Name
+	if name == "" {
+		return fmt.Errorf("collection name is required")
+	}
+	if _, exists := registryIndex[name]; exists {
+		return fmt.Errorf("collection %s is already registered", name)
+	}
+
+	registry = append(registry, c)
+	registryIndex[name] = c
+	return nil
+}
+
+// Get returns a registered collection by name
+func Get(name string) (Collection, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	c, ok := registryIndex[name]
+	return c, ok
+}
+
+// All returns all registered collections in registration order
+func All() []Collection {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	result := make([]Collection, len(registry))
+	copy(result, registry)
+	return result
+}
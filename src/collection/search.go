@@ -0,0 +1,29 @@
+package collection
+
+import (
+	"github.com/apimgr/quotes/src/search"
+)
+
+// snippetLength is the maximum length of a search snippet in bytes
+const snippetLength = 200
+
+// SearchHit is a ranked full-text search result
+type SearchHit struct {
+	Item  Item
+	Score float64
+}
+
+// searchFields returns the fields of an item that are indexed for full-text search,
+// starting with the text field
+func searchFields(info Info, item Item) []string {
+	fields := []string{item.Field(info.TextField)}
+	for _, f := range info.Fields {
+		fields = append(fields, item.Field(f.Name))
+	}
+	return fields
+}
+
+// Snippet returns the highlighted excerpt of an item's text for the matched terms
+func Snippet(info Info, item Item, terms []string) string {
+	return search.Snippet(item.Field(info.TextField), terms, snippetLength)
+}
@@ -0,0 +1,389 @@
+package collection
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/apimgr/quotes/src/search"
+)
+
+// Store holds the items of a single collection and implements Collection.
+// Random selection uses the math/rand/v2 top-level functions, which are safe
+// for concurrent use and draw from per-thread generators without a shared lock.
+// The items and their indexes are rebuilt on every change and swapped in
+// atomically, so readers never lock and always see a consistent snapshot.
+type Store[T Item] struct {
+	info  Info
+	data  atomic.Pointer[storeData[T]]
+	write sync.Mutex // Serializes Load and Put
+}
+
+// storeData is an immutable snapshot of the items of a store and their indexes
+type storeData[T Item] struct {
+	items    []T
+	all      []Item
+	byID     map[int]int
+	indexes  map[string]*fieldIndex[T]
+	text     *search.Index
+	times    map[int]Times // Times of the items added or changed since Load
+	loadedAt time.Time
+}
+
+// fieldIndex maps normalized field values to the matching items
+type fieldIndex[T Item] struct {
+	items  map[string][]T
+	all    map[string][]Item
+	values map[string]string
+	facets []Facet
+	trie   *search.Trie
+}
+
+// buildFacets computes the value counts and prefix trie of a field index
+func (f *fieldIndex[T]) buildFacets() {
+	f.facets = make([]Facet, 0, len(f.values))
+	f.trie = search.NewTrie()
+	for key, value := range f.values {
+		count := len(f.items[key])
+		f.facets = append(f.facets, Facet{Value: value, Count: count})
+		f.trie.Insert(value, count)
+	}
+
+	sort.Slice(f.facets, func(i, j int) bool {
+		if f.facets[i].Count != f.facets[j].Count {
+			return f.facets[i].Count > f.facets[j].Count
+		}
+		return f.facets[i].Value < f.facets[j].Value
+	})
+}
+
+// indexKey returns the lookup key for a field value, ignoring case,
+// diacritics and punctuation
+func indexKey(value string) string {
+	return search.Normalize(value)
+}
+
+// New creates an empty store described by info
+func New[T Item](info Info) *Store[T] {
+	s := &Store[T]{
+		info: info,
+	}
+	s.data.Store(&storeData[T]{})
+	return s
+}
+
+// snapshot returns the current items and indexes
+func (s *Store[T]) snapshot() *storeData[T] {
+	return s.data.Load()
+}
+
+// Info returns the description of the collection
+func (s *Store[T]) Info() Info {
+	return s.info
+}
+
+// Load loads items from embedded JSON data
+func (s *Store[T]) Load(jsonData []byte) error {
+	var items []T
+	if err := json.Unmarshal(jsonData, &items); err != nil {
+		return fmt.Errorf("failed to parse %s.json: %w", s.info.Name, err)
+	}
+
+	if len(items) == 0 {
+		return fmt.Errorf("no %s found in %s.json", s.info.Title, s.info.Name)
+	}
+
+	s.write.Lock()
+	defer s.write.Unlock()
+	s.data.Store(s.build(items))
+	return nil
+}
+
+// Put adds an item, or replaces the item with the same ID, given as JSON.
+// An item without an ID gets the next free one. It returns the stored item
+// and whether it was added rather than replaced.
+func (s *Store[T]) Put(jsonData []byte) (Item, bool, error) {
+	now := time.Now()
+	return s.PutAt(jsonData, now, now)
+}
+
+// PutAt is Put at a given time. An added item was created at created; a
+// replaced one keeps its creation time.
+func (s *Store[T]) PutAt(jsonData []byte, created, updated time.Time) (Item, bool, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(jsonData, &fields); err != nil {
+		return nil, false, fmt.Errorf("invalid %s: %w", s.info.ItemName, err)
+	}
+
+	s.write.Lock()
+	defer s.write.Unlock()
+	d := s.snapshot()
+
+	// Assign the next ID through the JSON fields, since T has no ID setter
+	if id, _ := fields["id"].(float64); id == 0 {
+		next := 1
+		for _, item := range d.items {
+			next = max(next, item.GetID()+1)
+		}
+		fields["id"] = next
+		jsonData, _ = json.Marshal(fields)
+	}
+
+	var item T
+	if err := json.Unmarshal(jsonData, &item); err != nil {
+		return nil, false, fmt.Errorf("invalid %s: %w", s.info.ItemName, err)
+	}
+	if item.GetID() < 1 {
+		return nil, false, fmt.Errorf("%s ID must be a positive integer", s.info.ItemName)
+	}
+	if strings.TrimSpace(item.Field(s.info.TextField)) == "" {
+		return nil, false, fmt.Errorf("%s must not be empty", s.info.TextField)
+	}
+
+	items := make([]T, len(d.items), len(d.items)+1)
+	copy(items, d.items)
+	i, exists := d.byID[item.GetID()]
+	if exists {
+		items[i] = item
+	} else {
+		items = append(items, item)
+	}
+
+	times := make(map[int]Times, len(d.times)+1)
+	for id, t := range d.times {
+		times[id] = t
+	}
+	t := Times{Created: created, Updated: updated}
+	if exists {
+		t.Created = d.times[item.GetID()].Created
+	}
+	times[item.GetID()] = t
+
+	next := s.build(items)
+	next.times = times
+	s.data.Store(next)
+	return item, !exists, nil
+}
+
+// build indexes items into a new snapshot
+func (s *Store[T]) build(items []T) *storeData[T] {
+	all := make([]Item, len(items))
+	byID := make(map[int]int, len(items))
+	indexes := make(map[string]*fieldIndex[T], len(s.info.Fields))
+	for _, field := range s.info.Fields {
+		indexes[field.Name] = &fieldIndex[T]{
+			items:  make(map[string][]T),
+			all:    make(map[string][]Item),
+			values: make(map[string]string),
+		}
+	}
+
+	text := search.NewIndex()
+	for i, item := range items {
+		all[i] = item
+		text.Add(searchFields(s.info, item)...)
+		if _, exists := byID[item.GetID()]; !exists {
+			byID[item.GetID()] = i
+		}
+
+		for name, index := range indexes {
+			value := item.Field(name)
+			key := indexKey(value)
+			index.items[key] = append(index.items[key], item)
+			index.all[key] = append(index.all[key], item)
+			if _, exists := index.values[key]; !exists {
+				index.values[key] = value
+			}
+		}
+	}
+
+	for _, index := range indexes {
+		index.buildFacets()
+	}
+	text.Build()
+
+	return &storeData[T]{
+		items:    items,
+		all:      all,
+		byID:     byID,
+		indexes:  indexes,
+		text:     text,
+		loadedAt: time.Now(),
+	}
+}
+
+// Times returns when the item with the given ID was added and last changed,
+// zero for items as they were loaded
+func (s *Store[T]) Times(id int) Times {
+	return s.snapshot().times[id]
+}
+
+// LoadedAt returns when the items were last loaded or changed
+func (s *Store[T]) LoadedAt() time.Time {
+	return s.snapshot().loadedAt
+}
+
+// Random returns a random item from the loaded items
+func (s *Store[T]) Random() (*T, error) {
+	d := s.snapshot()
+	if len(d.items) == 0 {
+		return nil, fmt.Errorf("no %s available, please load %s first", s.info.Title, s.info.Name)
+	}
+
+	index := rand.IntN(len(d.items))
+	return &d.items[index], nil
+}
+
+// All returns all loaded items
+func (s *Store[T]) All() []T {
+	return s.snapshot().items
+}
+
+// ByID returns an item by its ID
+func (s *Store[T]) ByID(id int) (*T, error) {
+	d := s.snapshot()
+	if i, ok := d.byID[id]; ok {
+		return &d.items[i], nil
+	}
+	return nil, fmt.Errorf("%s with ID %d not found", s.info.ItemName, id)
+}
+
+// ByField returns all items whose field matches value, ignoring case,
+// diacritics and punctuation.
+// Indexed fields are answered from the index; the returned slice is shared
+// and must not be modified.
+func (s *Store[T]) ByField(field, value string) []T {
+	d := s.snapshot()
+	key := indexKey(value)
+	if index, ok := d.indexes[field]; ok {
+		return index.items[key]
+	}
+
+	var result []T
+	for _, item := range d.items {
+		if indexKey(item.Field(field)) == key {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Count returns the total number of loaded items
+func (s *Store[T]) Count() int {
+	return len(s.snapshot().items)
+}
+
+// RandomItem returns a random item as an Item
+func (s *Store[T]) RandomItem() (Item, error) {
+	item, err := s.Random()
+	if err != nil {
+		return nil, err
+	}
+	return *item, nil
+}
+
+// Items returns all loaded items as Items
+func (s *Store[T]) Items() []Item {
+	return s.snapshot().all
+}
+
+// ItemByID returns an item by its ID as an Item
+func (s *Store[T]) ItemByID(id int) (Item, error) {
+	item, err := s.ByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return *item, nil
+}
+
+// ItemsByField returns all items whose field matches value as Items
+func (s *Store[T]) ItemsByField(field, value string) []Item {
+	if index, ok := s.snapshot().indexes[field]; ok {
+		return index.all[indexKey(value)]
+	}
+
+	matches := s.ByField(field, value)
+	result := make([]Item, len(matches))
+	for i, item := range matches {
+		result[i] = item
+	}
+	return result
+}
+
+// Search returns items matching a full-text query ordered by relevance
+func (s *Store[T]) Search(query string) ([]SearchHit, []string) {
+	d := s.snapshot()
+	if d.text == nil {
+		return nil, nil
+	}
+
+	results, terms := d.text.Search(query)
+	hits := make([]SearchHit, len(results))
+	for i, result := range results {
+		hits[i] = SearchHit{Item: d.all[result.Doc], Score: result.Score}
+	}
+	return hits, terms
+}
+
+// SimilarValues returns up to max distinct values of an indexed field that are
+// close to value by edit distance or contain it, closest first
+func (s *Store[T]) SimilarValues(field, value string, max int) []string {
+	index, ok := s.snapshot().indexes[field]
+	if !ok {
+		return nil
+	}
+
+	key := indexKey(value)
+	threshold := len([]rune(key))/3 + 1
+
+	type candidate struct {
+		value    string
+		distance int
+	}
+	var candidates []candidate
+	for k, v := range index.values {
+		d := search.Distance(key, k)
+		if d <= threshold || (key != "" && strings.Contains(k, key)) {
+			candidates = append(candidates, candidate{value: v, distance: d})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].value < candidates[j].value
+	})
+
+	result := make([]string, 0, max)
+	for _, c := range candidates {
+		if len(result) == max {
+			break
+		}
+		result = append(result, c.value)
+	}
+	return result
+}
+
+// Facets returns every distinct value of an indexed field with its item count,
+// most common first
+func (s *Store[T]) Facets(field string) []Facet {
+	if index, ok := s.snapshot().indexes[field]; ok {
+		return index.facets
+	}
+	return nil
+}
+
+// Suggest returns up to max values of an indexed field with a word starting
+// with prefix, most common first
+func (s *Store[T]) Suggest(field, prefix string, max int) []Facet {
+	if index, ok := s.snapshot().indexes[field]; ok {
+		return index.trie.Prefix(prefix, max)
+	}
+	return nil
+}
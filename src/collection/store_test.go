@@ -0,0 +1,169 @@
+package collection
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// testItem is a minimal item used to build synthetic corpora
+type testItem struct {
+	ID       int    `json:"id"`
+	Text     string `json:"text"`
+	Category string `json:"category"`
+}
+
+func (t testItem) GetID() int {
+	return t.ID
+}
+
+func (t testItem) Field(name string) string {
+	switch name {
+	case "text":
+		return t.Text
+	case "category":
+		return t.Category
+	}
+	return ""
+}
+
+// newTestStore builds a loaded store with size items spread over ten categories
+func newTestStore(tb testing.TB, size int) *Store[testItem] {
+	tb.Helper()
+
+	items := make([]testItem, size)
+	for i := range items {
+		items[i] = testItem{
+			ID:       i + 1,
+			Text:     fmt.Sprintf("item %d", i+1),
+			Category: fmt.Sprintf("Category %d", i%10),
+		}
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		tb.Fatalf("failed to marshal items: %v", err)
+	}
+
+	store := New[testItem](Info{
+		Name:      "test",
+		Title:     "test items",
+		ItemName:  "test item",
+		TextField: "text",
+		Fields:    []Field{{Name: "category", Path: "category"}},
+	})
+	if err := store.Load(data); err != nil {
+		tb.Fatalf("Load failed: %v", err)
+	}
+	return store
+}
+
+func TestStoreIndexes(t *testing.T) {
+	store := newTestStore(t, 100)
+
+	item, err := store.ByID(42)
+	if err != nil || item.ID != 42 {
+		t.Fatalf("ByID(42) = %v, %v", item, err)
+	}
+
+	if _, err := store.ByID(1000); err == nil {
+		t.Error("expected error for unknown ID")
+	}
+
+	if got := len(store.ByField("category", "CATEGORY 3")); got != 10 {
+		t.Errorf("ByField is not case-insensitive: got %d items, want 10", got)
+	}
+
+	if got := len(store.ItemsByField("category", " category 3 ")); got != 10 {
+		t.Errorf("ItemsByField got %d items, want 10", got)
+	}
+
+	if got := len(store.ByField("text", "ITEM 7")); got != 1 {
+		t.Errorf("unindexed ByField got %d items, want 1", got)
+	}
+}
+
+func TestStorePut(t *testing.T) {
+	store := newTestStore(t, 20)
+	before := store.Items()
+
+	item, added, err := store.Put([]byte(`{"id": 5, "text": "edited", "category": "New"}`))
+	if err != nil || added || item.GetID() != 5 {
+		t.Fatalf("Put(edit) = %v, %v, %v; want item 5 replaced", item, added, err)
+	}
+	if got, _ := store.ItemByID(5); got.Field("text") != "edited" {
+		t.Errorf("ItemByID(5) text = %q, want %q", got.Field("text"), "edited")
+	}
+	if got := len(store.ItemsByField("category", "new")); got != 1 {
+		t.Errorf("category index has %d new items, want 1", got)
+	}
+	if before[4].Field("text") != "item 5" {
+		t.Errorf("Put modified a previously returned item slice")
+	}
+
+	item, added, err = store.Put([]byte(`{"text": "added", "category": "New"}`))
+	if err != nil || !added || item.GetID() != 21 {
+		t.Fatalf("Put(add) = %v, %v, %v; want item 21 added", item, added, err)
+	}
+	if store.Count() != 21 {
+		t.Errorf("Count() = %d, want 21", store.Count())
+	}
+	if hits, _ := store.Search("added"); len(hits) != 1 || hits[0].Item.GetID() != 21 {
+		t.Errorf("Search(added) = %v, want item 21", hits)
+	}
+
+	for _, invalid := range []string{`{"id": 3, "text": ""}`, `{"id": -1, "text": "x"}`, `[1, 2]`} {
+		if _, _, err := store.Put([]byte(invalid)); err == nil {
+			t.Errorf("Put(%s) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestStoreTimes(t *testing.T) {
+	store := newTestStore(t, 20)
+	if times := store.Times(5); !times.Created.IsZero() || !times.Updated.IsZero() {
+		t.Errorf("Times(5) = %+v for a loaded item, want zero", times)
+	}
+
+	before := time.Now()
+	store.Put([]byte(`{"id": 5, "text": "edited"}`))
+	if times := store.Times(5); !times.Created.IsZero() || times.Updated.Before(before) {
+		t.Errorf("Times(5) = %+v after an edit, want only an update time", times)
+	}
+
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+	item, added, err := store.PutAt([]byte(`{"text": "restored"}`), created, created)
+	if err != nil || !added {
+		t.Fatalf("PutAt(add) = %v, %v, %v", item, added, err)
+	}
+	store.PutAt([]byte(fmt.Sprintf(`{"id": %d, "text": "restored again"}`, item.GetID())), updated, updated)
+	if times := store.Times(item.GetID()); !times.Created.Equal(created) || !times.Updated.Equal(updated) {
+		t.Errorf("Times(%d) = %+v, want created %v and updated %v", item.GetID(), times, created, updated)
+	}
+}
+
+func BenchmarkStoreByID(b *testing.B) {
+	for _, size := range []int{1000, 10000, 100000} {
+		store := newTestStore(b, size)
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := store.ByID(size - i%size); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkStoreByField(b *testing.B) {
+	for _, size := range []int{1000, 10000, 100000} {
+		store := newTestStore(b, size)
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				store.ItemsByField("category", "category 7")
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package collection
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+)
+
+// float64N returns a random float in [0, 1) from rng, or from the shared
+// concurrency-safe generator when rng is nil
+func float64N(rng *rand.Rand) float64 {
+	if rng == nil {
+		return rand.Float64()
+	}
+	return rng.Float64()
+}
+
+// AliasTable samples indexes in proportion to fixed weights in constant
+// time, using Vose's alias method. Building the table takes linear time.
+type AliasTable struct {
+	prob  []float64
+	alias []int
+}
+
+// NewAliasTable builds a table for the given non-negative weights.
+// If every weight is zero, all indexes are equally likely.
+func NewAliasTable(weights []float64) *AliasTable {
+	n := len(weights)
+	t := &AliasTable{
+		prob:  make([]float64, n),
+		alias: make([]int, n),
+	}
+
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+
+	// Scale weights so the average is 1, then pair each small entry with a large one
+	scaled := make([]float64, n)
+	var small, large []int
+	for i, w := range weights {
+		if total > 0 {
+			scaled[i] = w * float64(n) / total
+		} else {
+			scaled[i] = 1
+		}
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+		large = large[:len(large)-1]
+
+		t.prob[s] = scaled[s]
+		t.alias[s] = l
+		scaled[l] += scaled[s] - 1
+		if scaled[l] < 1 {
+			small = append(small, l)
+		} else {
+			large = append(large, l)
+		}
+	}
+
+	// Leftovers are 1 up to rounding error
+	for _, i := range large {
+		t.prob[i] = 1
+	}
+	for _, i := range small {
+		t.prob[i] = 1
+	}
+	return t
+}
+
+// Len returns the number of indexes in the table
+func (t *AliasTable) Len() int {
+	return len(t.prob)
+}
+
+// Pick returns a random index. A nil rng uses the shared generator.
+func (t *AliasTable) Pick(rng *rand.Rand) int {
+	i := intN(rng, len(t.prob))
+	if float64N(rng) < t.prob[i] {
+		return i
+	}
+	return t.alias[i]
+}
+
+// WeightedSample returns up to count distinct items drawn with probability
+// proportional to their weights, using the Efraimidis-Spirakis method.
+// Items with zero weight are only returned once every weighted item is.
+func WeightedSample(items []Item, weights []float64, count int, rng *rand.Rand) []Item {
+	if count > len(items) {
+		count = len(items)
+	}
+
+	type keyed struct {
+		item Item
+		key  float64
+	}
+
+	// Each item gets the key log(u)/w; the largest keys win
+	keys := make([]keyed, len(items))
+	for i, item := range items {
+		u := 1 - float64N(rng) // in (0, 1], so log(u) is finite
+		key := math.Inf(-1)
+		if weights[i] > 0 {
+			key = math.Log(u) / weights[i]
+		}
+		keys[i] = keyed{item: item, key: key}
+	}
+	sort.SliceStable(keys, func(a, b int) bool {
+		return keys[a].key > keys[b].key
+	})
+
+	result := make([]Item, count)
+	for i := range result {
+		result[i] = keys[i].item
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+package collection
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAliasTableFollowsWeights(t *testing.T) {
+	weights := []float64{1, 2, 3, 0, 4}
+	table := NewAliasTable(weights)
+	rng := NewSeededRand(1)
+
+	const draws = 200000
+	counts := make([]int, len(weights))
+	for i := 0; i < draws; i++ {
+		counts[table.Pick(rng)]++
+	}
+
+	for i, w := range weights {
+		want := w / 10
+		got := float64(counts[i]) / draws
+		if math.Abs(got-want) > 0.01 {
+			t.Errorf("index %d drawn %.3f of the time, want %.3f", i, got, want)
+		}
+	}
+}
+
+func TestAliasTableAllZero(t *testing.T) {
+	table := NewAliasTable([]float64{0, 0, 0})
+	rng := NewSeededRand(1)
+
+	seen := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		seen[table.Pick(rng)] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("picked %d distinct indexes, want 3", len(seen))
+	}
+}
+
+func TestWeightedSampleDistinct(t *testing.T) {
+	store := newTestStore(t, 50)
+	items := store.Items()
+	weights := make([]float64, len(items))
+	for i := range weights {
+		weights[i] = float64(i + 1)
+	}
+
+	got := WeightedSample(items, weights, 20, NewSeededRand(7))
+	if len(got) != 20 {
+		t.Fatalf("got %d items, want 20", len(got))
+	}
+	seen := make(map[int]bool)
+	for _, item := range got {
+		if seen[item.GetID()] {
+			t.Fatalf("item %d returned twice", item.GetID())
+		}
+		seen[item.GetID()] = true
+	}
+
+	if all := WeightedSample(items, weights, 100, NewSeededRand(7)); len(all) != len(items) {
+		t.Errorf("got %d items, want all %d", len(all), len(items))
+	}
+}
+
+func TestWeightedSampleFavorsHeavyItems(t *testing.T) {
+	store := newTestStore(t, 10)
+	items := store.Items()
+	weights := make([]float64, len(items))
+	for i := range weights {
+		weights[i] = 1
+	}
+	weights[0] = 100
+
+	rng := NewSeededRand(3)
+	hits := 0
+	for i := 0; i < 1000; i++ {
+		if WeightedSample(items, weights, 1, rng)[0].GetID() == items[0].GetID() {
+			hits++
+		}
+	}
+	// Expected share is 100/109
+	if hits < 870 || hits > 960 {
+		t.Errorf("heavy item picked %d times out of 1000, want about 917", hits)
+	}
+}
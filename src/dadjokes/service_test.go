@@ -0,0 +1,43 @@
+package dadjokes
+
+import (
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestGetRandomJokeConcurrent(t *testing.T) {
+	data, err := os.ReadFile("../data/dadjokes.json")
+	if err != nil {
+		t.Fatalf("failed to read dadjokes.json: %v", err)
+	}
+	if err := LoadJokes(data); err != nil {
+		t.Fatalf("LoadJokes failed: %v", err)
+	}
+
+	seen := make([]map[int]bool, 64)
+	var wg sync.WaitGroup
+	for g := range seen {
+		seen[g] = make(map[int]bool)
+		wg.Add(1)
+		go func(ids map[int]bool) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				joke, err := GetRandomJoke()
+				if err != nil {
+					t.Errorf("GetRandomJoke failed: %v", err)
+					return
+				}
+				ids[joke.ID] = true
+			}
+		}(seen[g])
+	}
+	wg.Wait()
+
+	// Every goroutine should see a spread of jokes, not one stuck value
+	for g, ids := range seen {
+		if len(ids) < 100 {
+			t.Errorf("goroutine %d saw only %d distinct jokes", g, len(ids))
+		}
+	}
+}
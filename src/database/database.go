@@ -67,6 +67,44 @@ func createTables() error {
 		status_code INTEGER,
 		timestamp DATETIME DEFAULT CURRENT_TIMESTAMP
 	);
+
+	CREATE TABLE IF NOT EXISTS rotations (
+		key TEXT PRIMARY KEY,
+		seed INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		size INTEGER NOT NULL,
+		cursor INTEGER NOT NULL DEFAULT 0,
+		deferred TEXT NOT NULL DEFAULT '',
+		last TEXT NOT NULL DEFAULT '',
+		version INTEGER NOT NULL DEFAULT 0,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS item_weights (
+		collection TEXT NOT NULL,
+		item_id INTEGER NOT NULL,
+		weight REAL NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (collection, item_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS item_ratings (
+		collection TEXT NOT NULL,
+		item_id INTEGER NOT NULL,
+		rating_sum INTEGER NOT NULL,
+		rating_count INTEGER NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (collection, item_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS item_edits (
+		collection TEXT NOT NULL,
+		item_id INTEGER NOT NULL,
+		data TEXT NOT NULL,
+		created_at DATETIME,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (collection, item_id)
+	);
 	`
 
 	_, err := db.Exec(schema)
@@ -0,0 +1,23 @@
+package database
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "quotes-database-test")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := InitDB(filepath.Join(dir, "quotes.db")); err != nil {
+		log.Fatal(err)
+	}
+
+	code := m.Run()
+	Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ItemEdit is an item added or edited by an admin, kept as JSON
+type ItemEdit struct {
+	Collection string
+	ID         int
+	Data       []byte
+	CreatedAt  time.Time // When the item was added, zero for edited dataset items
+	UpdatedAt  time.Time
+}
+
+// GetItemEdits retrieves every admin-added or edited item in the order they were saved
+func GetItemEdits() ([]ItemEdit, error) {
+	query := `SELECT collection, item_id, data, created_at, updated_at FROM item_edits ORDER BY updated_at, rowid`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve item edits: %w", err)
+	}
+	defer rows.Close()
+
+	var edits []ItemEdit
+	for rows.Next() {
+		var edit ItemEdit
+		var data string
+		var created sql.NullTime
+		if err := rows.Scan(&edit.Collection, &edit.ID, &data, &created, &edit.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan item edit: %w", err)
+		}
+		edit.Data = []byte(data)
+		edit.CreatedAt = created.Time
+		edits = append(edits, edit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating item edits: %w", err)
+	}
+
+	return edits, nil
+}
+
+// SaveItemEdit saves an admin-added or edited item changed at updated.
+// created is when the item was added, or zero for an edited dataset item;
+// later saves keep the first one.
+func SaveItemEdit(name string, id int, data []byte, created, updated time.Time) error {
+	var createdAt sql.NullTime
+	if !created.IsZero() {
+		createdAt = sql.NullTime{Time: created.UTC(), Valid: true}
+	}
+	query := `INSERT INTO item_edits (collection, item_id, data, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+			  ON CONFLICT(collection, item_id) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`
+	_, err := db.Exec(query, name, id, string(data), createdAt, updated.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save item edit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestItemEdits(t *testing.T) {
+	added := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	edited := added.Add(time.Hour)
+
+	// An added item, then an edited dataset item, then a new edit of the added item
+	if err := SaveItemEdit("quotes", 9001, []byte(`{"id":9001,"quote":"first"}`), added, added); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveItemEdit("anime", 3, []byte(`{"id":3}`), time.Time{}, added.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveItemEdit("quotes", 9001, []byte(`{"id":9001,"quote":"second"}`), edited, edited); err != nil {
+		t.Fatal(err)
+	}
+
+	edits, err := GetItemEdits()
+	if err != nil {
+		t.Fatalf("GetItemEdits failed: %v", err)
+	}
+	if len(edits) != 2 {
+		t.Fatalf("got %d edits, want one per item: %+v", len(edits), edits)
+	}
+
+	// Edits are listed in the order they were last saved
+	dataset, item := edits[0], edits[1]
+	if dataset.Collection != "anime" || dataset.ID != 3 || !dataset.CreatedAt.IsZero() {
+		t.Errorf("dataset item edit = %+v, want no creation time", dataset)
+	}
+	if item.Collection != "quotes" || item.ID != 9001 || string(item.Data) != `{"id":9001,"quote":"second"}` {
+		t.Errorf("added item edit = %+v, want the latest data", item)
+	}
+	if !item.CreatedAt.Equal(added) || !item.UpdatedAt.Equal(edited) {
+		t.Errorf("added item created %v, updated %v; want the first creation and the latest update", item.CreatedAt, item.UpdatedAt)
+	}
+}
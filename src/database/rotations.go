@@ -0,0 +1,96 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rotation is the saved no-repeat rotation state of a client key
+type Rotation struct {
+	Seed     uint64
+	Position int
+	Size     int
+	Cursor   int
+	Deferred []int
+	Last     []int
+	Version  int64
+}
+
+// GetRotation retrieves the saved no-repeat rotation state for a client key
+func GetRotation(key string) (Rotation, bool, error) {
+	var r Rotation
+	var seed int64
+	var deferred, last string
+	query := `SELECT seed, position, size, cursor, deferred, last, version FROM rotations WHERE key = ?`
+	err := db.QueryRow(query, key).Scan(&seed, &r.Position, &r.Size, &r.Cursor, &deferred, &last, &r.Version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Rotation{}, false, nil
+		}
+		return Rotation{}, false, fmt.Errorf("database error: %w", err)
+	}
+	r.Seed = uint64(seed)
+	if r.Deferred, err = decodeInts(deferred); err != nil {
+		return Rotation{}, false, fmt.Errorf("invalid rotation %s: %w", key, err)
+	}
+	if r.Last, err = decodeInts(last); err != nil {
+		return Rotation{}, false, fmt.Errorf("invalid rotation %s: %w", key, err)
+	}
+	return r, true, nil
+}
+
+// SaveRotation saves the no-repeat rotation state for a client key, unless
+// a state with a higher version is already saved
+func SaveRotation(key string, r Rotation) error {
+	query := `INSERT INTO rotations (key, seed, position, size, cursor, deferred, last, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			  ON CONFLICT(key) DO UPDATE SET seed = excluded.seed, position = excluded.position, size = excluded.size,
+			  cursor = excluded.cursor, deferred = excluded.deferred, last = excluded.last, version = excluded.version,
+			  updated_at = CURRENT_TIMESTAMP
+			  WHERE excluded.version > rotations.version`
+	_, err := db.Exec(query, key, int64(r.Seed), r.Position, r.Size, r.Cursor, encodeInts(r.Deferred), encodeInts(r.Last), r.Version)
+	if err != nil {
+		return fmt.Errorf("failed to save rotation: %w", err)
+	}
+	return nil
+}
+
+// PruneRotations deletes the rotations last used before a time and all but
+// the keep most recently used ones, and returns how many were deleted
+func PruneRotations(before time.Time, keep int) (int64, error) {
+	query := `DELETE FROM rotations WHERE updated_at < ?
+			  OR key IN (SELECT key FROM rotations ORDER BY updated_at DESC, version DESC LIMIT -1 OFFSET ?)`
+	result, err := db.Exec(query, before.UTC().Format("2006-01-02 15:04:05"), keep)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune rotations: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// encodeInts joins integers with commas
+func encodeInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+// decodeInts parses integers joined with commas
+func decodeInts(s string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	values := make([]int, len(parts))
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
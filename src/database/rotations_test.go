@@ -0,0 +1,81 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRotationRoundTrip(t *testing.T) {
+	saved := Rotation{Seed: 1<<63 + 5, Position: 3, Size: 10, Cursor: 4, Deferred: []int{7, 0}, Last: []int{2}, Version: 100}
+	if err := SaveRotation("round|trip", saved); err != nil {
+		t.Fatal(err)
+	}
+	got, found, err := GetRotation("round|trip")
+	if err != nil || !found || !reflect.DeepEqual(got, saved) {
+		t.Fatalf("GetRotation = %+v, %v, %v; want %+v", got, found, err, saved)
+	}
+
+	// Saves of older versions are ignored
+	older := saved
+	older.Position, older.Version = 1, 99
+	if err := SaveRotation("round|trip", older); err != nil {
+		t.Fatal(err)
+	}
+	if got, _, _ := GetRotation("round|trip"); got.Position != 3 {
+		t.Errorf("older save overwrote the rotation: %+v", got)
+	}
+
+	newer := Rotation{Size: 10, Version: 101}
+	if err := SaveRotation("round|trip", newer); err != nil {
+		t.Fatal(err)
+	}
+	if got, _, _ := GetRotation("round|trip"); !reflect.DeepEqual(got, newer) {
+		t.Errorf("GetRotation after a newer save = %+v, want %+v", got, newer)
+	}
+
+	if _, found, err := GetRotation("round|missing"); found || err != nil {
+		t.Errorf("GetRotation(missing) = %v, %v", found, err)
+	}
+}
+
+func TestPruneRotationsKeepsRecent(t *testing.T) {
+	if _, err := db.Exec(`DELETE FROM rotations`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := SaveRotation(fmt.Sprintf("prune|%d", i), Rotation{Size: 5, Version: int64(i + 1)}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(`UPDATE rotations SET updated_at = datetime('now', ?) WHERE key = ?`, fmt.Sprintf("-%d minutes", 5-i), fmt.Sprintf("prune|%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Exec(`UPDATE rotations SET updated_at = datetime('now', '-31 days') WHERE key = 'prune|4'`); err != nil {
+		t.Fatal(err)
+	}
+
+	// prune|4 is unused for too long, prune|0 and prune|1 are beyond the two most recent
+	deleted, err := PruneRotations(time.Now().Add(-30*24*time.Hour), 2)
+	if err != nil || deleted != 3 {
+		t.Fatalf("PruneRotations = %d, %v; want 3 deleted", deleted, err)
+	}
+	for i, want := range []bool{false, false, true, true, false} {
+		if _, found, _ := GetRotation(fmt.Sprintf("prune|%d", i)); found != want {
+			t.Errorf("prune|%d kept = %v, want %v", i, found, want)
+		}
+	}
+}
+
+func TestDecodeInts(t *testing.T) {
+	for _, values := range [][]int{nil, {0}, {3, -1, 42}} {
+		got, err := decodeInts(encodeInts(values))
+		if err != nil || !reflect.DeepEqual(got, values) {
+			t.Errorf("decodeInts(encodeInts(%v)) = %v, %v", values, got, err)
+		}
+	}
+	if _, err := decodeInts("1,x"); err == nil {
+		t.Error("expected error for a non-integer")
+	}
+}
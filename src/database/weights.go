@@ -0,0 +1,103 @@
+package database
+
+import (
+	"fmt"
+)
+
+// ItemRating is the sum and number of user ratings of an item
+type ItemRating struct {
+	Sum   int
+	Count int
+}
+
+// GetItemWeights retrieves all admin-assigned item weights by collection and item ID
+func GetItemWeights() (map[string]map[int]float64, error) {
+	weights := make(map[string]map[int]float64)
+	query := `SELECT collection, item_id, weight FROM item_weights`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve item weights: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var id int
+		var weight float64
+		if err := rows.Scan(&name, &id, &weight); err != nil {
+			return nil, fmt.Errorf("failed to scan item weight: %w", err)
+		}
+		if weights[name] == nil {
+			weights[name] = make(map[int]float64)
+		}
+		weights[name][id] = weight
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating item weights: %w", err)
+	}
+
+	return weights, nil
+}
+
+// SetItemWeight sets or updates the weight of an item
+func SetItemWeight(name string, id int, weight float64) error {
+	query := `INSERT INTO item_weights (collection, item_id, weight) VALUES (?, ?, ?)
+			  ON CONFLICT(collection, item_id) DO UPDATE SET weight = ?, updated_at = CURRENT_TIMESTAMP`
+	_, err := db.Exec(query, name, id, weight, weight)
+	if err != nil {
+		return fmt.Errorf("failed to set item weight: %w", err)
+	}
+	return nil
+}
+
+// DeleteItemWeight removes the weight of an item
+func DeleteItemWeight(name string, id int) error {
+	query := `DELETE FROM item_weights WHERE collection = ? AND item_id = ?`
+	if _, err := db.Exec(query, name, id); err != nil {
+		return fmt.Errorf("failed to delete item weight: %w", err)
+	}
+	return nil
+}
+
+// GetItemRatings retrieves all item ratings by collection and item ID
+func GetItemRatings() (map[string]map[int]ItemRating, error) {
+	ratings := make(map[string]map[int]ItemRating)
+	query := `SELECT collection, item_id, rating_sum, rating_count FROM item_ratings`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve item ratings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var id int
+		var rating ItemRating
+		if err := rows.Scan(&name, &id, &rating.Sum, &rating.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan item rating: %w", err)
+		}
+		if ratings[name] == nil {
+			ratings[name] = make(map[int]ItemRating)
+		}
+		ratings[name][id] = rating
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating item ratings: %w", err)
+	}
+
+	return ratings, nil
+}
+
+// AddItemRating records one user rating of an item
+func AddItemRating(name string, id int, rating int) error {
+	query := `INSERT INTO item_ratings (collection, item_id, rating_sum, rating_count) VALUES (?, ?, ?, 1)
+			  ON CONFLICT(collection, item_id) DO UPDATE SET rating_sum = rating_sum + ?,
+			  rating_count = rating_count + 1, updated_at = CURRENT_TIMESTAMP`
+	_, err := db.Exec(query, name, id, rating, rating)
+	if err != nil {
+		return fmt.Errorf("failed to add item rating: %w", err)
+	}
+	return nil
+}
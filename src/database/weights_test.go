@@ -0,0 +1,58 @@
+package database
+
+import (
+	"testing"
+)
+
+func TestItemWeights(t *testing.T) {
+	if err := SetItemWeight("quotes", 1, 2.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetItemWeight("quotes", 1, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetItemWeight("anime", 1, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	weights, err := GetItemWeights()
+	if err != nil {
+		t.Fatalf("GetItemWeights failed: %v", err)
+	}
+	if weights["quotes"][1] != 0.5 || weights["anime"][1] != 3 {
+		t.Errorf("weights = %v, want the latest weight of each item", weights)
+	}
+
+	if err := DeleteItemWeight("quotes", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteItemWeight("quotes", 999); err != nil {
+		t.Errorf("deleting a missing weight failed: %v", err)
+	}
+	weights, _ = GetItemWeights()
+	if _, ok := weights["quotes"][1]; ok || weights["anime"][1] != 3 {
+		t.Errorf("weights after delete = %v", weights)
+	}
+}
+
+func TestItemRatings(t *testing.T) {
+	for _, rating := range []int{5, 2, 4} {
+		if err := AddItemRating("dadjokes", 7, rating); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := AddItemRating("dadjokes", 8, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	ratings, err := GetItemRatings()
+	if err != nil {
+		t.Fatalf("GetItemRatings failed: %v", err)
+	}
+	if got := ratings["dadjokes"][7]; got != (ItemRating{Sum: 11, Count: 3}) {
+		t.Errorf("rating of item 7 = %+v, want sum 11 of 3", got)
+	}
+	if got := ratings["dadjokes"][8]; got != (ItemRating{Sum: 1, Count: 1}) {
+		t.Errorf("rating of item 8 = %+v, want sum 1 of 1", got)
+	}
+}
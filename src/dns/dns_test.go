@@ -0,0 +1,332 @@
+package dns
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// testQuery builds a query for name, with an OPT record announcing
+// udpSize when it is not 0
+func testQuery(id uint16, name string, qtype Type, udpSize uint16) []byte {
+	b := &builder{}
+	var ar uint16
+	if udpSize != 0 {
+		ar = 1
+	}
+	b.header(id, flagRD, [4]uint16{1, 0, 0, ar})
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		b.str(label)
+	}
+	b.msg = append(b.msg, 0)
+	b.uint16(uint16(qtype))
+	b.uint16(classINET)
+	if udpSize != 0 {
+		b.msg = append(b.msg, 0)
+		b.endRecord(b.record(TypeOPT, udpSize, 0))
+	}
+	return b.msg
+}
+
+// testRecord is a record of a reply
+type testRecord struct {
+	rtype   Type
+	ttl     uint32
+	strings []string // Character-strings of a TXT record
+}
+
+// testReply is a parsed reply
+type testReply struct {
+	id       uint16
+	flags    uint16
+	rcode    RCode
+	question string
+	answers  []testRecord
+	ns       []testRecord
+	extra    []testRecord
+}
+
+func parseTestReply(t *testing.T, msg []byte) testReply {
+	t.Helper()
+	if len(msg) < headerLength {
+		t.Fatalf("reply of %d bytes", len(msg))
+	}
+	r := testReply{
+		id:    binary.BigEndian.Uint16(msg),
+		flags: binary.BigEndian.Uint16(msg[2:]),
+	}
+	r.rcode = RCode(r.flags & 0xF)
+	off := headerLength
+	if binary.BigEndian.Uint16(msg[4:]) == 1 {
+		var q query
+		var err error
+		if off, err = q.parseQuestion(msg, off); err != nil {
+			t.Fatalf("question: %v", err)
+		}
+		r.question = strings.Join(q.labels, ".")
+	}
+
+	sections := []*[]testRecord{&r.answers, &r.ns, &r.extra}
+	for i, section := range sections {
+		for range binary.BigEndian.Uint16(msg[6+2*i:]) {
+			rtype, _, ttl, next, err := readRecord(msg, off)
+			if err != nil {
+				t.Fatalf("record: %v", err)
+			}
+			rec := testRecord{rtype: rtype, ttl: ttl}
+			if rtype == TypeTXT {
+				nameEnd, _ := skipName(msg, off)
+				for p := nameEnd + 10; p < next; p += 1 + int(msg[p]) {
+					rec.strings = append(rec.strings, string(msg[p+1:p+1+int(msg[p])]))
+				}
+			}
+			if rtype == TypeOPT {
+				r.rcode |= RCode(ttl>>24) << 4
+			}
+			*section = append(*section, rec)
+			off = next
+		}
+	}
+	return r
+}
+
+// testServer serves a zone where random.quotes holds a short text, long.quotes
+// a text of 600 bytes and many.quotes 20 of them
+func testServer(t *testing.T) *Server {
+	t.Helper()
+	long := strings.Repeat("a", 600)
+	s, err := Listen("127.0.0.1:0", "Example.", func(labels []string) (Answer, bool) {
+		switch strings.Join(labels, ".") {
+		case "", "quotes":
+			return Answer{}, true
+		case "random.quotes":
+			return Answer{Texts: []string{"Be yourself -- Oscar Wilde"}, TTL: 1}, true
+		case "long.quotes":
+			return Answer{Texts: []string{long}, TTL: 300}, true
+		case "many.quotes":
+			texts := make([]string, 20)
+			for i := range texts {
+				texts[i] = long
+			}
+			return Answer{Texts: texts, TTL: 300}, true
+		}
+		return Answer{}, false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// exchangeUDP sends a query over UDP and returns the reply
+func exchangeUDP(t *testing.T, addr net.Addr, msg []byte) testReply {
+	t.Helper()
+	conn, err := net.Dial("udp", addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write(msg); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parseTestReply(t, buf[:n])
+}
+
+func TestSplitText(t *testing.T) {
+	if got := SplitText(""); len(got) != 1 || got[0] != "" {
+		t.Errorf("SplitText(\"\") = %q", got)
+	}
+	if got := SplitText("short"); len(got) != 1 || got[0] != "short" {
+		t.Errorf("SplitText(\"short\") = %q", got)
+	}
+
+	for _, text := range []string{strings.Repeat("a", 600), strings.Repeat("é", 300), strings.Repeat("日本", 100)} {
+		parts := SplitText(text)
+		if strings.Join(parts, "") != text {
+			t.Errorf("SplitText parts of %d bytes do not join back", len(text))
+		}
+		for _, p := range parts {
+			if len(p) > MaxStringLength || !utf8.ValidString(p) {
+				t.Errorf("SplitText part of %d bytes, valid UTF-8 %v", len(p), utf8.ValidString(p))
+			}
+		}
+	}
+	if got := SplitText(strings.Repeat("a", 600)); len(got) != 3 || len(got[0]) != 255 || len(got[2]) != 90 {
+		t.Errorf("SplitText of 600 bytes gives parts of %d, %d... bytes", len(got[0]), len(got[1]))
+	}
+}
+
+func TestTXT(t *testing.T) {
+	s := testServer(t)
+	r := exchangeUDP(t, s.UDPAddr(), testQuery(42, "Random.QUOTES.example.", TypeTXT, 0))
+	if r.id != 42 || r.flags&flagQR == 0 || r.flags&flagAA == 0 || r.flags&flagRD == 0 || r.rcode != RCodeSuccess {
+		t.Fatalf("reply id %d, flags %#x", r.id, r.flags)
+	}
+	if r.question != "Random.QUOTES.example" {
+		t.Errorf("question = %q, want the case of the query", r.question)
+	}
+	if len(r.answers) != 1 || r.answers[0].rtype != TypeTXT || r.answers[0].ttl != 1 {
+		t.Fatalf("answers = %+v", r.answers)
+	}
+	if got := r.answers[0].strings; len(got) != 1 || got[0] != "Be yourself -- Oscar Wilde" {
+		t.Errorf("TXT = %q", got)
+	}
+
+	r = exchangeUDP(t, s.UDPAddr(), testQuery(1, "long.quotes.example", TypeTXT, 1232))
+	if len(r.answers) != 1 || len(r.answers[0].strings) != 3 || r.answers[0].ttl != 300 {
+		t.Fatalf("long answers = %+v", r.answers)
+	}
+	if got := strings.Join(r.answers[0].strings, ""); got != strings.Repeat("a", 600) {
+		t.Errorf("long TXT of %d bytes", len(got))
+	}
+}
+
+func TestNegativeAnswers(t *testing.T) {
+	s := testServer(t)
+	tests := []struct {
+		name  string
+		qtype Type
+		rcode RCode
+		soa   bool // Whether the SOA is in the authority section
+	}{
+		{"nope.quotes.example", TypeTXT, RCodeNameError, true},
+		{"random.quotes.example", TypeA, RCodeSuccess, true},
+		{"quotes.example", TypeTXT, RCodeSuccess, true},
+		{"random.quotes.example.org", TypeTXT, RCodeRefused, false},
+		{"org", TypeTXT, RCodeRefused, false},
+	}
+	for _, tt := range tests {
+		r := exchangeUDP(t, s.UDPAddr(), testQuery(7, tt.name, tt.qtype, 0))
+		if r.rcode != tt.rcode || len(r.answers) != 0 {
+			t.Errorf("%s: rcode %d with %d answers, want %d and none", tt.name, r.rcode, len(r.answers), tt.rcode)
+		}
+		if soa := len(r.ns) == 1 && r.ns[0].rtype == TypeSOA; soa != tt.soa {
+			t.Errorf("%s: authority %+v, want SOA %v", tt.name, r.ns, tt.soa)
+		}
+	}
+
+	r := exchangeUDP(t, s.UDPAddr(), testQuery(7, "example", TypeSOA, 0))
+	if len(r.answers) != 1 || r.answers[0].rtype != TypeSOA || len(r.ns) != 0 {
+		t.Errorf("SOA answers %+v, authority %+v", r.answers, r.ns)
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	s := testServer(t)
+
+	msg := testQuery(9, "random.quotes.example", TypeTXT, 0)
+	binary.BigEndian.PutUint16(msg[4:], 0)
+	if r := exchangeUDP(t, s.UDPAddr(), msg); r.id != 9 || r.rcode != RCodeFormatError {
+		t.Errorf("no question: id %d, rcode %d", r.id, r.rcode)
+	}
+
+	msg = testQuery(9, "random.quotes.example", TypeTXT, 0)
+	msg[2] |= 2 << 3 // Opcode 2, STATUS
+	if r := exchangeUDP(t, s.UDPAddr(), msg); r.rcode != RCodeNotImplemented {
+		t.Errorf("STATUS query: rcode %d", r.rcode)
+	}
+
+	msg = testQuery(9, "random.quotes.example", TypeTXT, 0)
+	if r := exchangeUDP(t, s.UDPAddr(), msg[:len(msg)-3]); r.rcode != RCodeFormatError {
+		t.Errorf("cut question: rcode %d", r.rcode)
+	}
+
+	msg = testQuery(9, "random.quotes.example", TypeTXT, 1232)
+	msg[len(msg)-5] = 1 // EDNS version 1
+	r := exchangeUDP(t, s.UDPAddr(), msg)
+	if r.rcode != RCodeBadVersion || len(r.answers) != 0 || len(r.extra) != 1 {
+		t.Errorf("EDNS version 1: rcode %d, %d answers", r.rcode, len(r.answers))
+	}
+
+	for _, msg := range [][]byte{{1, 2, 3}, append([]byte{0, 9, 0x80, 0}, make([]byte, 8)...)} {
+		if q, _, ok := parseQuery(msg); ok {
+			t.Errorf("parseQuery(%v) = %+v, want no reply", msg, q)
+		}
+	}
+}
+
+func TestTruncation(t *testing.T) {
+	s := testServer(t)
+
+	// A text of 600 bytes only fits with EDNS, and 20 of them fit in
+	// neither 512 nor 1232 bytes
+	r := exchangeUDP(t, s.UDPAddr(), testQuery(3, "long.quotes.example", TypeTXT, 0))
+	if r.flags&flagTC == 0 || len(r.answers) != 0 || r.question != "long.quotes.example" {
+		t.Errorf("UDP reply flags %#x, %d answers, want truncated", r.flags, len(r.answers))
+	}
+	r = exchangeUDP(t, s.UDPAddr(), testQuery(3, "long.quotes.example", TypeTXT, 1232))
+	if r.flags&flagTC != 0 || len(r.answers) != 1 {
+		t.Errorf("EDNS reply of one long text flags %#x, %d answers", r.flags, len(r.answers))
+	}
+	r = exchangeUDP(t, s.UDPAddr(), testQuery(3, "many.quotes.example", TypeTXT, 4096))
+	if r.flags&flagTC == 0 || len(r.extra) != 1 || r.extra[0].rtype != TypeOPT {
+		t.Errorf("EDNS reply flags %#x, extra %+v, want truncated with OPT", r.flags, r.extra)
+	}
+
+	conn, err := net.Dial("tcp", s.TCPAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	for id := range uint16(2) {
+		msg := testQuery(id, "many.quotes.example", TypeTXT, 0)
+		conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(msg))), msg...))
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			t.Fatal(err)
+		}
+		reply := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			t.Fatal(err)
+		}
+		r := parseTestReply(t, reply)
+		if r.id != id || r.flags&flagTC != 0 || len(r.answers) != 20 {
+			t.Errorf("TCP reply %d: flags %#x, %d answers, want 20", r.id, r.flags, len(r.answers))
+		}
+	}
+}
+
+func TestAllow(t *testing.T) {
+	s := &Server{rates: make(map[string]rate)}
+	addr := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 5353}
+	other := &net.UDPAddr{IP: net.ParseIP("192.0.2.2"), Port: 5353}
+	now := time.Now()
+
+	for i := range rateLimit {
+		if !s.allow(addr, now) {
+			t.Fatalf("reply %d refused", i+1)
+		}
+	}
+	if s.allow(&net.UDPAddr{IP: addr.IP, Port: 53}, now) {
+		t.Error("reply past the limit allowed")
+	}
+	if !s.allow(other, now) {
+		t.Error("reply to another host refused")
+	}
+	if !s.allow(addr, now.Add(time.Second)) {
+		t.Error("reply a second later refused")
+	}
+}
+
+func TestParseZone(t *testing.T) {
+	if labels, err := parseZone("Quotes.Internal."); err != nil || strings.Join(labels, ".") != "quotes.internal" {
+		t.Errorf("parseZone = %q, %v", labels, err)
+	}
+	for _, zone := range []string{"", ".", "a..b", strings.Repeat("a", 64)} {
+		if _, err := parseZone(zone); err == nil {
+			t.Errorf("parseZone(%q) accepted", zone)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package dns
+
+import (
+	"encoding/binary"
+	"errors"
+	"unicode/utf8"
+)
+
+// Type is the type of a resource record, or of the records a question asks for
+type Type uint16
+
+const (
+	TypeA   Type = 1
+	TypeSOA Type = 6
+	TypeTXT Type = 16
+	TypeOPT Type = 41
+	TypeANY Type = 255
+)
+
+// RCode is the response code of a reply. Codes above 15 are extended
+// codes, sent partly in the OPT record.
+type RCode uint16
+
+const (
+	RCodeSuccess        RCode = 0
+	RCodeFormatError    RCode = 1
+	RCodeNameError      RCode = 3 // NXDOMAIN
+	RCodeNotImplemented RCode = 4
+	RCodeRefused        RCode = 5
+	RCodeBadVersion     RCode = 16
+)
+
+const (
+	// MaxStringLength is the longest character-string of a TXT record
+	MaxStringLength = 255
+
+	// headerLength is the size of a message header
+	headerLength = 12
+
+	// minUDPSize is the largest UDP message every client accepts
+	minUDPSize = 512
+
+	// maxUDPSize is the largest UDP message sent, however large the client
+	// accepts, so that replies are not fragmented on common links
+	maxUDPSize = 1232
+
+	// maxLabelLength and maxNameLength are the limits of RFC 1035
+	maxLabelLength = 63
+	maxNameLength  = 255
+
+	classINET = 1
+	classANY  = 255
+)
+
+// Header flags
+const (
+	flagQR = 1 << 15 // Response
+	flagAA = 1 << 10 // Authoritative answer
+	flagTC = 1 << 9  // Truncated
+	flagRD = 1 << 8  // Recursion desired, echoed
+)
+
+var errMalformed = errors.New("malformed message")
+
+// query is a parsed query: one question and, with EDNS, an OPT record
+type query struct {
+	id     uint16
+	flags  uint16
+	labels []string // Labels of the name, as sent
+	name   []byte   // Name in wire format, echoed in replies to keep its case
+	qtype  Type
+	class  uint16
+
+	edns        bool
+	ednsVersion uint8
+	udpSize     int // Largest UDP reply the client accepts
+}
+
+// opcode returns the kind of query, 0 for a standard query
+func (q *query) opcode() uint16 {
+	return q.flags >> 11 & 0xF
+}
+
+// parseQuery parses a query. It returns the header even when the rest is
+// malformed, so that the error can be answered, and ok false when the
+// message is not a query worth answering at all.
+func parseQuery(msg []byte) (q *query, rcode RCode, ok bool) {
+	if len(msg) < headerLength {
+		return nil, 0, false
+	}
+	q = &query{
+		id:      binary.BigEndian.Uint16(msg),
+		flags:   binary.BigEndian.Uint16(msg[2:]),
+		udpSize: minUDPSize,
+	}
+	if q.flags&flagQR != 0 {
+		// Never answer replies, which could start a loop
+		return nil, 0, false
+	}
+	if q.opcode() != 0 {
+		return q, RCodeNotImplemented, true
+	}
+	qdcount := binary.BigEndian.Uint16(msg[4:])
+	ancount := binary.BigEndian.Uint16(msg[6:])
+	nscount := binary.BigEndian.Uint16(msg[8:])
+	arcount := binary.BigEndian.Uint16(msg[10:])
+	if qdcount != 1 {
+		return q, RCodeFormatError, true
+	}
+
+	off, err := q.parseQuestion(msg, headerLength)
+	if err != nil {
+		return q, RCodeFormatError, true
+	}
+	for i := 0; i < int(ancount)+int(nscount); i++ {
+		if off, err = skipRecord(msg, off); err != nil {
+			return q, RCodeFormatError, true
+		}
+	}
+	for i := 0; i < int(arcount); i++ {
+		if off, err = q.parseAdditional(msg, off); err != nil {
+			return q, RCodeFormatError, true
+		}
+	}
+	return q, RCodeSuccess, true
+}
+
+// parseQuestion parses the question at off and returns the offset after it
+func (q *query) parseQuestion(msg []byte, off int) (int, error) {
+	start := off
+	for {
+		if off >= len(msg) {
+			return 0, errMalformed
+		}
+		n := int(msg[off])
+		if n == 0 {
+			off++
+			break
+		}
+		// The question is the first name of a message: it has nothing to
+		// point to, so compression is not accepted
+		if n > maxLabelLength || off+1+n > len(msg) {
+			return 0, errMalformed
+		}
+		q.labels = append(q.labels, string(msg[off+1:off+1+n]))
+		off += 1 + n
+	}
+	if off-start > maxNameLength || off+4 > len(msg) {
+		return 0, errMalformed
+	}
+	q.name = msg[start:off]
+	q.qtype = Type(binary.BigEndian.Uint16(msg[off:]))
+	q.class = binary.BigEndian.Uint16(msg[off+2:])
+	return off + 4, nil
+}
+
+// parseAdditional parses a record of the additional section, keeping the
+// EDNS parameters of an OPT record
+func (q *query) parseAdditional(msg []byte, off int) (int, error) {
+	rtype, class, ttl, next, err := readRecord(msg, off)
+	if err != nil || rtype != TypeOPT {
+		return next, err
+	}
+	if q.edns {
+		return 0, errMalformed
+	}
+	q.edns = true
+	q.ednsVersion = uint8(ttl >> 16)
+	q.udpSize = max(int(class), minUDPSize)
+	return next, nil
+}
+
+// skipRecord returns the offset after the record at off
+func skipRecord(msg []byte, off int) (int, error) {
+	_, _, _, next, err := readRecord(msg, off)
+	return next, err
+}
+
+// readRecord reads the type, class and TTL of the record at off and
+// returns the offset after it
+func readRecord(msg []byte, off int) (rtype Type, class uint16, ttl uint32, next int, err error) {
+	if off, err = skipName(msg, off); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	if off+10 > len(msg) {
+		return 0, 0, 0, 0, errMalformed
+	}
+	rtype = Type(binary.BigEndian.Uint16(msg[off:]))
+	class = binary.BigEndian.Uint16(msg[off+2:])
+	ttl = binary.BigEndian.Uint32(msg[off+4:])
+	next = off + 10 + int(binary.BigEndian.Uint16(msg[off+8:]))
+	if next > len(msg) {
+		return 0, 0, 0, 0, errMalformed
+	}
+	return rtype, class, ttl, next, nil
+}
+
+// skipName returns the offset after the name at off, which may end in a
+// compression pointer
+func skipName(msg []byte, off int) (int, error) {
+	for {
+		if off >= len(msg) {
+			return 0, errMalformed
+		}
+		n := int(msg[off])
+		switch {
+		case n == 0:
+			return off + 1, nil
+		case n&0xC0 == 0xC0:
+			if off+2 > len(msg) {
+				return 0, errMalformed
+			}
+			return off + 2, nil
+		case n > maxLabelLength:
+			return 0, errMalformed
+		}
+		off += 1 + n
+	}
+}
+
+// SplitText splits text into the character-strings of a TXT record, of
+// at most MaxStringLength bytes each. Strings end at character boundaries,
+// so each is valid UTF-8 when text is.
+func SplitText(text string) []string {
+	if text == "" {
+		return []string{""}
+	}
+	var parts []string
+	for len(text) > MaxStringLength {
+		cut := MaxStringLength
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		if cut == 0 {
+			cut = MaxStringLength
+		}
+		parts = append(parts, text[:cut])
+		text = text[cut:]
+	}
+	return append(parts, text)
+}
+
+// builder appends the sections of a reply to a message
+type builder struct {
+	msg []byte
+}
+
+func (b *builder) uint16(v uint16) {
+	b.msg = binary.BigEndian.AppendUint16(b.msg, v)
+}
+
+func (b *builder) uint32(v uint32) {
+	b.msg = binary.BigEndian.AppendUint32(b.msg, v)
+}
+
+// header appends a header with the given flags and section counts
+func (b *builder) header(id, flags uint16, counts [4]uint16) {
+	b.uint16(id)
+	b.uint16(flags)
+	for _, n := range counts {
+		b.uint16(n)
+	}
+}
+
+// pointer appends a compression pointer to the name at off
+func (b *builder) pointer(off int) {
+	b.uint16(0xC000 | uint16(off))
+}
+
+// str appends a string prefixed with its length: a label of a name, or
+// a character-string of a TXT record
+func (b *builder) str(s string) {
+	b.msg = append(b.msg, byte(len(s)))
+	b.msg = append(b.msg, s...)
+}
+
+// record appends the start of a record whose name was just appended, and
+// returns the offset of its RDATA length, filled in by endRecord
+func (b *builder) record(rtype Type, class uint16, ttl uint32) int {
+	b.uint16(uint16(rtype))
+	b.uint16(class)
+	b.uint32(ttl)
+	b.uint16(0)
+	return len(b.msg) - 2
+}
+
+// endRecord sets the RDATA length of the record started at lengthOff
+func (b *builder) endRecord(lengthOff int) {
+	binary.BigEndian.PutUint16(b.msg[lengthOff:], uint16(len(b.msg)-lengthOff-2))
+}
+
+// txt appends the RDATA of a TXT record holding text
+func (b *builder) txt(text string) {
+	for _, s := range SplitText(text) {
+		b.str(s)
+	}
+}
+
+// opt appends an OPT record announcing the UDP size this server accepts
+// and the upper bits of an extended response code
+func (b *builder) opt(rcode RCode) {
+	b.msg = append(b.msg, 0) // Root name
+	b.endRecord(b.record(TypeOPT, maxUDPSize, uint32(rcode>>4)<<24))
+}
@@ -0,0 +1,414 @@
+package dns
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// negativeTTL is how long resolvers may cache that a name, or a record
+	// of a name, does not exist
+	negativeTTL = 60
+
+	// maxTCPSize is the largest message sent over TCP
+	maxTCPSize = 65535
+
+	// tcpTimeout is how long a TCP connection may stay idle
+	tcpTimeout = 10 * time.Second
+
+	// maxConns is the number of TCP connections served at once; more are
+	// closed right away
+	maxConns = 256
+
+	// rateLimit is the number of UDP replies each host gets per second.
+	// Past it, replies are truncated so that clients retry over TCP, whose
+	// source cannot be spoofed; this keeps the server from amplifying
+	// floods.
+	rateLimit = 20
+
+	// maxTracked is the number of UDP client hosts remembered
+	maxTracked = 10000
+)
+
+// Answer holds the TXT records of a name
+type Answer struct {
+	Texts []string // One TXT record each, split into strings of up to 255 bytes
+	TTL   uint32   // Seconds resolvers may cache the records
+}
+
+// Handler returns the TXT records of a name of the zone, given as its
+// labels below the zone in lower case: ["random", "quotes"] for
+// random.quotes.example. in the zone example. ok is false when the name
+// does not exist; names with no records, such as those with only names
+// below them, return an empty Answer.
+type Handler func(labels []string) (answer Answer, ok bool)
+
+// Server answers the TXT queries of one zone, as its authoritative name
+// server, on a UDP and a TCP port
+type Server struct {
+	zone       []string // Labels of the zone, in lower case
+	zoneLength int      // Length of the zone name in wire format
+	serial     uint32
+	handler    Handler
+
+	udp   net.PacketConn
+	tcp   net.Listener
+	wg    sync.WaitGroup
+	rates map[string]rate // Replies to each UDP client host this second
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// rate counts the replies to a host since start
+type rate struct {
+	start time.Time
+	count int
+}
+
+// resolution is the outcome of a query, before it is written as a reply
+type resolution struct {
+	rcode     RCode
+	texts     []string
+	ttl       uint32
+	soa       bool // Whether the SOA record of the zone is an answer
+	authority bool // Whether the SOA record is in the authority section, for negative answers
+}
+
+// Listen listens on addr over UDP and TCP and answers the queries for
+// names of zone, such as "quotes.internal", with handler, until Close.
+// With port 0 both get the same free port.
+func Listen(addr, zone string, handler Handler) (*Server, error) {
+	labels, err := parseZone(zone)
+	if err != nil {
+		return nil, err
+	}
+
+	udp, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	host, _, _ := net.SplitHostPort(addr)
+	port := udp.LocalAddr().(*net.UDPAddr).Port
+	tcp, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		udp.Close()
+		return nil, err
+	}
+
+	s := &Server{
+		zone:       labels,
+		zoneLength: len(strings.Join(labels, ".")) + 2,
+		serial:     uint32(time.Now().Unix()),
+		handler:    handler,
+		udp:        udp,
+		tcp:        tcp,
+		rates:      make(map[string]rate),
+		conns:      make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(2)
+	go s.serveUDP()
+	go s.serveTCP()
+	return s, nil
+}
+
+// parseZone returns the labels of a zone name, in lower case
+func parseZone(zone string) ([]string, error) {
+	name := strings.ToLower(strings.TrimSuffix(zone, "."))
+	if name == "" || len(name) > maxNameLength-2 {
+		return nil, fmt.Errorf("invalid DNS zone %q", zone)
+	}
+	labels := strings.Split(name, ".")
+	for _, label := range labels {
+		if label == "" || len(label) > maxLabelLength {
+			return nil, fmt.Errorf("invalid DNS zone %q", zone)
+		}
+	}
+	return labels, nil
+}
+
+// UDPAddr returns the address of the UDP listener
+func (s *Server) UDPAddr() net.Addr {
+	return s.udp.LocalAddr()
+}
+
+// TCPAddr returns the address of the TCP listener
+func (s *Server) TCPAddr() net.Addr {
+	return s.tcp.Addr()
+}
+
+// Close stops both listeners, closes the TCP connections and waits for
+// them to finish
+func (s *Server) Close() error {
+	err := errors.Join(s.udp.Close(), s.tcp.Close())
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+// serveUDP answers every query datagram
+func (s *Server) serveUDP() {
+	defer s.wg.Done()
+	buf := make([]byte, 4096)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			continue
+		}
+
+		q, r, ok := s.answer(buf[:n])
+		if !ok {
+			continue
+		}
+		limit := min(q.udpSize, maxUDPSize)
+		if !s.allow(addr, time.Now()) {
+			limit = 0
+		}
+		s.udp.WriteTo(s.reply(q, r, limit), addr)
+	}
+}
+
+// allow reports whether a UDP client may get a full reply now, at most
+// rateLimit per second for each host
+func (s *Server) allow(addr net.Addr, now time.Time) bool {
+	host := addr.String()
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		host = udpAddr.IP.String()
+	}
+
+	r, ok := s.rates[host]
+	if !ok && len(s.rates) >= maxTracked {
+		for h, r := range s.rates {
+			if now.Sub(r.start) >= time.Second {
+				delete(s.rates, h)
+			}
+		}
+		if len(s.rates) >= maxTracked {
+			return false
+		}
+	}
+	if now.Sub(r.start) >= time.Second {
+		r = rate{start: now}
+	}
+	r.count++
+	s.rates[host] = r
+	return r.count <= rateLimit
+}
+
+// serveTCP accepts connections until the listener is closed
+func (s *Server) serveTCP() {
+	defer s.wg.Done()
+	var delay time.Duration
+	for {
+		conn, err := s.tcp.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			// Out of file descriptors and the like: back off as net/http does
+			delay = min(max(2*delay, 5*time.Millisecond), time.Second)
+			log.Printf("⚠️  Warning: DNS accept failed, retrying in %v: %v", delay, err)
+			time.Sleep(delay)
+			continue
+		}
+		delay = 0
+
+		s.mu.Lock()
+		if len(s.conns) >= maxConns {
+			s.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// serveConn answers the queries of a TCP connection, each prefixed with
+// its length, until the client closes it or stays idle
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	for {
+		conn.SetDeadline(time.Now().Add(tcpTimeout))
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return
+		}
+		msg := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, msg); err != nil {
+			return
+		}
+
+		q, r, ok := s.answer(msg)
+		if !ok {
+			return
+		}
+		reply := s.reply(q, r, maxTCPSize)
+		if _, err := conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(reply))), reply...)); err != nil {
+			return
+		}
+	}
+}
+
+// answer parses a query message and resolves it. ok is false when the
+// message gets no reply.
+func (s *Server) answer(msg []byte) (*query, resolution, bool) {
+	q, rcode, ok := parseQuery(msg)
+	if !ok {
+		return nil, resolution{}, false
+	}
+	if rcode != RCodeSuccess {
+		return q, resolution{rcode: rcode}, true
+	}
+	return q, s.resolve(q), true
+}
+
+// resolve answers a well-formed query
+func (s *Server) resolve(q *query) resolution {
+	if q.edns && q.ednsVersion > 0 {
+		return resolution{rcode: RCodeBadVersion}
+	}
+	if q.class != classINET && q.class != classANY {
+		return resolution{rcode: RCodeRefused}
+	}
+	labels, ok := s.relative(q.labels)
+	if !ok {
+		return resolution{rcode: RCodeRefused}
+	}
+
+	answer, ok := s.handler(labels)
+	if !ok {
+		return resolution{rcode: RCodeNameError, authority: true}
+	}
+	var r resolution
+	if len(labels) == 0 && (q.qtype == TypeSOA || q.qtype == TypeANY) {
+		r.soa = true
+	}
+	if q.qtype == TypeTXT || q.qtype == TypeANY {
+		r.texts, r.ttl = answer.Texts, answer.TTL
+	}
+	r.authority = !r.soa && len(r.texts) == 0
+	return r
+}
+
+// relative returns the labels of a name below the zone, in lower case, and
+// false when the name is not in the zone
+func (s *Server) relative(labels []string) ([]string, bool) {
+	n := len(labels) - len(s.zone)
+	if n < 0 {
+		return nil, false
+	}
+	for i, label := range s.zone {
+		if !strings.EqualFold(labels[n+i], label) {
+			return nil, false
+		}
+	}
+	below := make([]string, n)
+	for i := range below {
+		below[i] = strings.ToLower(labels[i])
+	}
+	return below, true
+}
+
+// reply writes the reply to a query, without its records when it does not
+// fit in limit bytes, so that the client retries over TCP
+func (s *Server) reply(q *query, r resolution, limit int) []byte {
+	msg := s.build(q, r, false)
+	if len(msg) > limit {
+		msg = s.build(q, r, true)
+	}
+	return msg
+}
+
+// build writes the reply to a query, with its records unless truncated
+func (s *Server) build(q *query, r resolution, truncated bool) []byte {
+	flags := flagQR | q.flags&(flagRD|0xF<<11) | uint16(r.rcode&0xF)
+	if r.rcode == RCodeSuccess || r.rcode == RCodeNameError {
+		flags |= flagAA
+	}
+	var counts [4]uint16
+	if q.name != nil {
+		counts[0] = 1
+	}
+	if truncated {
+		flags |= flagTC
+	} else {
+		counts[1] = uint16(len(r.texts))
+		if r.soa {
+			counts[1]++
+		}
+		if r.authority {
+			counts[2] = 1
+		}
+	}
+	if q.edns {
+		counts[3] = 1
+	}
+
+	b := &builder{msg: make([]byte, 0, 512)}
+	b.header(q.id, flags, counts)
+	if q.name != nil {
+		b.msg = append(b.msg, q.name...)
+		b.uint16(uint16(q.qtype))
+		b.uint16(q.class)
+	}
+	if !truncated {
+		// The question is at the end of the header, and the zone at the
+		// end of the question
+		zoneOff := headerLength + len(q.name) - s.zoneLength
+		for _, text := range r.texts {
+			b.pointer(headerLength)
+			start := b.record(TypeTXT, classINET, r.ttl)
+			b.txt(text)
+			b.endRecord(start)
+		}
+		if r.soa || r.authority {
+			s.soa(b, zoneOff)
+		}
+	}
+	if q.edns {
+		b.opt(r.rcode)
+	}
+	return b.msg
+}
+
+// soa appends the SOA record of the zone, whose name is at zoneOff. Its
+// minimum is the TTL of negative answers.
+func (s *Server) soa(b *builder, zoneOff int) {
+	b.pointer(zoneOff)
+	start := b.record(TypeSOA, classINET, negativeTTL)
+	b.str("ns")
+	b.pointer(zoneOff)
+	b.str("hostmaster")
+	b.pointer(zoneOff)
+	b.uint32(s.serial)
+	b.uint32(3600)  // Refresh
+	b.uint32(600)   // Retry
+	b.uint32(86400) // Expire
+	b.uint32(negativeTTL)
+	b.endRecord(start)
+}
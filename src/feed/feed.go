@@ -0,0 +1,270 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// guidPrefix makes entry IDs tag URIs (RFC 4151) that stay the same across
+// deployments, so readers recognise an item however the server is reached
+const guidPrefix = "tag:github.com,2025:apimgr/quotes/"
+
+// GUID returns the stable ID of an item of a collection
+func GUID(collection string, id int) string {
+	return fmt.Sprintf("%s%s/%d", guidPrefix, collection, id)
+}
+
+// Feed is the format-independent content of a feed
+type Feed struct {
+	Title       string
+	Description string
+	HomeURL     string
+	FeedURL     string
+	Updated     time.Time
+	Entries     []Entry
+}
+
+// Entry is one item of a feed
+type Entry struct {
+	ID         string
+	Title      string
+	URL        string
+	Content    string
+	Author     string
+	Categories []string
+	Published  time.Time   // When the entry was first published, zero if unknown
+	Updated    time.Time   // When the entry last changed, zero if never
+	Item       interface{} // Original item, embedded in JSON feeds
+}
+
+// formatTime formats t in UTC with layout, or returns "" for the zero time
+func formatTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(layout)
+}
+
+// updated returns when the entry last changed, falling back to when it was
+// published and then to fallback
+func (e Entry) updated(fallback time.Time) time.Time {
+	if !e.Updated.IsZero() {
+		return e.Updated
+	}
+	if !e.Published.IsZero() {
+		return e.Published
+	}
+	return fallback
+}
+
+// rss is the document element of an RSS 2.0 feed
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Self          atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	Description string   `xml:"description"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// WriteRSS writes the feed as RSS 2.0
+func (f *Feed) WriteRSS(w io.Writer) error {
+	doc := rss{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.HomeURL,
+			Description:   f.Description,
+			LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+			Self:          atomLink{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	for _, e := range f.Entries {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       e.Title,
+			Link:        e.URL,
+			GUID:        rssGUID{Value: e.ID},
+			Description: e.Content,
+			Creator:     e.Author,
+			Categories:  e.Categories,
+			PubDate:     formatTime(e.Published, time.RFC1123Z),
+		})
+	}
+	return writeXML(w, doc)
+}
+
+// atom is the document element of an Atom feed
+type atom struct {
+	XMLName xml.Name    `xml:"feed"`
+	NS      string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomPerson  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Link       atomLink       `xml:"link"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+// WriteAtom writes the feed as Atom (RFC 4287)
+func (f *Feed) WriteAtom(w io.Writer) error {
+	doc := atom{
+		NS:      "http://www.w3.org/2005/Atom",
+		ID:      f.FeedURL,
+		Title:   f.Title,
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.HomeURL, Rel: "alternate"},
+		},
+		Author: atomPerson{Name: f.Title},
+	}
+	for _, e := range f.Entries {
+		entry := atomEntry{
+			ID:        e.ID,
+			Title:     e.Title,
+			Updated:   e.updated(f.Updated).UTC().Format(time.RFC3339),
+			Published: formatTime(e.Published, time.RFC3339),
+			Link:      atomLink{Href: e.URL, Rel: "alternate"},
+			Content:   atomContent{Type: "text", Value: e.Content},
+		}
+		if e.Author != "" {
+			entry.Author = &atomPerson{Name: e.Author}
+		}
+		for _, c := range e.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: c})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return writeXML(w, doc)
+}
+
+// writeXML writes an indented XML document with its declaration
+func writeXML(w io.Writer, doc interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// jsonFeed is a JSON Feed 1.1 document
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentText   string           `json:"content_text"`
+	DatePublished string           `json:"date_published,omitempty"`
+	DateModified  string           `json:"date_modified,omitempty"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	Item          interface{}      `json:"_item,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+// WriteJSON writes the feed as JSON Feed 1.1, with each original item
+// under the _item extension
+func (f *Feed) WriteJSON(w io.Writer) error {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.HomeURL,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Items:       []jsonFeedItem{},
+	}
+	for _, e := range f.Entries {
+		item := jsonFeedItem{
+			ID:            e.ID,
+			URL:           e.URL,
+			Title:         e.Title,
+			ContentText:   e.Content,
+			DatePublished: formatTime(e.Published, time.RFC3339),
+			DateModified:  formatTime(e.Updated, time.RFC3339),
+			Tags:          e.Categories,
+			Item:          e.Item,
+		}
+		if e.Author != "" {
+			item.Authors = []jsonFeedAuthor{{Name: e.Author}}
+		}
+		doc.Items = append(doc.Items, item)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
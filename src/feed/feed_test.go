@@ -0,0 +1,215 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	feedUpdated = time.Date(2025, 10, 14, 0, 0, 0, 0, time.UTC)
+	published   = time.Date(2025, 10, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	edited      = time.Date(2025, 10, 10, 8, 30, 0, 0, time.UTC)
+)
+
+// testFeed returns a feed with a dated entry and an undated one
+func testFeed() *Feed {
+	return &Feed{
+		Title:       "Quotes",
+		Description: "Quote of the day",
+		HomeURL:     "https://quotes.example.com/",
+		FeedURL:     "https://quotes.example.com/feeds/quotes.rss",
+		Updated:     feedUpdated,
+		Entries: []Entry{
+			{
+				ID:         GUID("quotes", 42),
+				Title:      "Quote #42",
+				URL:        "https://quotes.example.com/api/v1/quotes/42",
+				Content:    "Less is <more> & then some",
+				Author:     "Author 42",
+				Categories: []string{"wisdom"},
+				Published:  published,
+				Updated:    edited,
+				Item:       map[string]interface{}{"id": 42},
+			},
+			{
+				ID:    GUID("quotes", 7),
+				Title: "Quote #7",
+				URL:   "https://quotes.example.com/api/v1/quotes/7",
+			},
+		},
+	}
+}
+
+func TestGUID(t *testing.T) {
+	if got, want := GUID("anime", 7), "tag:github.com,2025:apimgr/quotes/anime/7"; got != want {
+		t.Errorf("GUID = %q, want %q", got, want)
+	}
+}
+
+func TestWriteRSS(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testFeed().WriteRSS(&buf); err != nil {
+		t.Fatalf("WriteRSS failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Errorf("missing XML declaration: %.60q", buf.String())
+	}
+
+	var doc struct {
+		Version string `xml:"version,attr"`
+		Channel struct {
+			Title         string `xml:"title"`
+			LastBuildDate string `xml:"lastBuildDate"`
+			Self          struct {
+				Href string `xml:"href,attr"`
+			} `xml:"http://www.w3.org/2005/Atom link"`
+			Items []struct {
+				GUID struct {
+					IsPermaLink string `xml:"isPermaLink,attr"`
+					Value       string `xml:",chardata"`
+				} `xml:"guid"`
+				Description string   `xml:"description"`
+				Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+				Categories  []string `xml:"category"`
+				PubDate     *string  `xml:"pubDate"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid RSS: %v\n%s", err, buf.String())
+	}
+
+	ch := doc.Channel
+	if doc.Version != "2.0" || ch.Title != "Quotes" || ch.Self.Href != testFeed().FeedURL || len(ch.Items) != 2 {
+		t.Fatalf("unexpected channel: %+v", doc)
+	}
+	if ch.LastBuildDate != "Tue, 14 Oct 2025 00:00:00 +0000" {
+		t.Errorf("lastBuildDate = %q", ch.LastBuildDate)
+	}
+	first := ch.Items[0]
+	if first.GUID.Value != GUID("quotes", 42) || first.GUID.IsPermaLink != "false" {
+		t.Errorf("guid = %+v, want a tag URI that is not a permalink", first.GUID)
+	}
+	if first.Description != "Less is <more> & then some" || first.Creator != "Author 42" || len(first.Categories) != 1 {
+		t.Errorf("unexpected first item: %+v", first)
+	}
+	if first.PubDate == nil || *first.PubDate != "Wed, 01 Oct 2025 10:00:00 +0000" {
+		t.Errorf("pubDate = %v, want the publication time in UTC", first.PubDate)
+	}
+	if second := ch.Items[1]; second.PubDate != nil || second.Creator != "" {
+		t.Errorf("undated item has pubDate %v and creator %q", second.PubDate, second.Creator)
+	}
+}
+
+func TestWriteAtom(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testFeed().WriteAtom(&buf); err != nil {
+		t.Fatalf("WriteAtom failed: %v", err)
+	}
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Updated string   `xml:"updated"`
+		Links   []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Entries []struct {
+			ID        string `xml:"id"`
+			Updated   string `xml:"updated"`
+			Published string `xml:"published"`
+			Author    *struct {
+				Name string `xml:"name"`
+			} `xml:"author"`
+			Categories []struct {
+				Term string `xml:"term,attr"`
+			} `xml:"category"`
+			Content struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid Atom: %v\n%s", err, buf.String())
+	}
+
+	if doc.ID != testFeed().FeedURL || doc.Updated != "2025-10-14T00:00:00Z" || len(doc.Links) != 2 || len(doc.Entries) != 2 {
+		t.Fatalf("unexpected feed: %+v", doc)
+	}
+	if doc.Links[0].Rel != "self" || doc.Links[1].Rel != "alternate" {
+		t.Errorf("links = %+v, want self then alternate", doc.Links)
+	}
+
+	first := doc.Entries[0]
+	if first.Updated != "2025-10-10T08:30:00Z" || first.Published != "2025-10-01T10:00:00Z" {
+		t.Errorf("first entry updated %q, published %q", first.Updated, first.Published)
+	}
+	if first.Author == nil || first.Author.Name != "Author 42" || len(first.Categories) != 1 || first.Categories[0].Term != "wisdom" {
+		t.Errorf("unexpected first entry: %+v", first)
+	}
+	if first.Content.Type != "text" || first.Content.Value != "Less is <more> & then some" {
+		t.Errorf("content = %+v", first.Content)
+	}
+
+	// Entries without dates are as recent as the feed and have no author
+	second := doc.Entries[1]
+	if second.Updated != doc.Updated || second.Published != "" || second.Author != nil {
+		t.Errorf("unexpected undated entry: %+v", second)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testFeed().WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	var doc struct {
+		Version string                   `json:"version"`
+		FeedURL string                   `json:"feed_url"`
+		Items   []map[string]interface{} `json:"items"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON Feed: %v\n%s", err, buf.String())
+	}
+	if doc.Version != "https://jsonfeed.org/version/1.1" || doc.FeedURL != testFeed().FeedURL || len(doc.Items) != 2 {
+		t.Fatalf("unexpected feed: %+v", doc)
+	}
+
+	first := doc.Items[0]
+	if first["id"] != GUID("quotes", 42) || first["content_text"] != "Less is <more> & then some" {
+		t.Errorf("unexpected first item: %v", first)
+	}
+	if first["date_published"] != "2025-10-01T10:00:00Z" || first["date_modified"] != "2025-10-10T08:30:00Z" {
+		t.Errorf("first item dates: %v, %v", first["date_published"], first["date_modified"])
+	}
+	if item, ok := first["_item"].(map[string]interface{}); !ok || item["id"] != 42.0 {
+		t.Errorf("_item = %v, want the original item", first["_item"])
+	}
+
+	second := doc.Items[1]
+	for _, key := range []string{"date_published", "date_modified", "authors", "tags", "_item"} {
+		if _, ok := second[key]; ok {
+			t.Errorf("undated item has %s: %v", key, second[key])
+		}
+	}
+}
+
+func TestWriteJSONEmpty(t *testing.T) {
+	f := testFeed()
+	f.Entries = nil
+
+	var buf bytes.Buffer
+	if err := f.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"items": []`) {
+		t.Errorf("empty feed should list no items rather than null:\n%s", buf.String())
+	}
+}
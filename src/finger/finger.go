@@ -0,0 +1,37 @@
+package finger
+
+import (
+	"io"
+	"strings"
+)
+
+// Query is a Finger query, as described in RFC 1288
+type Query struct {
+	User    string // Empty when the client asks for the list of users
+	Verbose bool   // Set by the /W token, asking for more detail
+	Host    string // Host to forward the query to, if any
+}
+
+// ParseQuery parses a query line such as "/W quote" or "quote@host"
+func ParseQuery(line string) Query {
+	var q Query
+	line = strings.TrimSpace(line)
+	if token, rest, _ := strings.Cut(line, " "); strings.EqualFold(token, "/W") {
+		q.Verbose = true
+		line = strings.TrimSpace(rest)
+	}
+
+	// Only the first host is kept; forwarding is refused anyway
+	user, host, _ := strings.Cut(line, "@")
+	host, _, _ = strings.Cut(host, "@")
+	q.User = user
+	q.Host = host
+	return q
+}
+
+// WriteReply writes a reply with CRLF line endings, as the RFC requires
+func WriteReply(w io.Writer, text string) error {
+	text = strings.ReplaceAll(strings.TrimRight(text, "\r\n"), "\r\n", "\n")
+	_, err := io.WriteString(w, strings.ReplaceAll(text, "\n", "\r\n")+"\r\n")
+	return err
+}
@@ -0,0 +1,34 @@
+package finger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := map[string]Query{
+		"":                       {},
+		"/W":                     {Verbose: true},
+		"quote":                  {User: "quote"},
+		"/W dadjoke":             {User: "dadjoke", Verbose: true},
+		"/w  quote ":             {User: "quote", Verbose: true},
+		"quote@example.org":      {User: "quote", Host: "example.org"},
+		"quote@a.example@b.test": {User: "quote", Host: "a.example"},
+		"@example.org":           {Host: "example.org"},
+	}
+	for line, want := range tests {
+		if got := ParseQuery(line); got != want {
+			t.Errorf("ParseQuery(%q) = %+v, want %+v", line, got, want)
+		}
+	}
+}
+
+func TestWriteReply(t *testing.T) {
+	var b strings.Builder
+	if err := WriteReply(&b, "one\ntwo\r\nthree\n\n"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "one\r\ntwo\r\nthree\r\n"; b.String() != want {
+		t.Errorf("reply = %q, want %q", b.String(), want)
+	}
+}
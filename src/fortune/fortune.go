@@ -0,0 +1,95 @@
+package fortune
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+
+	"github.com/apimgr/quotes/src/collection"
+)
+
+const (
+	// Delimiter is the character on the line separating two cookies
+	Delimiter = '%'
+
+	// strfileVersion is the version of the index format written by strfile
+	strfileVersion = 2
+)
+
+// Entry returns the cookie of an item: its text, followed by its attribution
+// on an indented line in the usual fortune style
+func Entry(info collection.Info, item collection.Item) string {
+	text := strings.TrimRight(item.Field(info.TextField), "\n")
+	if attribution := info.AttributionOf(item); attribution != "" {
+		text += "\n\t\t-- " + attribution
+	}
+
+	// A line holding only the delimiter would split the cookie in two
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == string(Delimiter) {
+			lines[i] = " " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Cookies returns the cookie file of a collection: every item as an entry
+// followed by a line holding only the delimiter
+func Cookies(c collection.Collection) []byte {
+	info := c.Info()
+	var buf bytes.Buffer
+	for _, item := range c.Items() {
+		buf.WriteString(Entry(info, item))
+		buf.WriteString("\n")
+		buf.WriteByte(Delimiter)
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// Index returns the strfile(1) index of a cookie file, as read by fortune(6)
+// from the .dat file next to it. The header holds the version, number of
+// cookies, longest and shortest cookie lengths, flags and delimiter, followed
+// by the offset of every cookie and the offset of the end of the last one,
+// all as big-endian 32-bit integers.
+func Index(cookies []byte) []byte {
+	offsets := []uint32{0}
+	var longest, shortest uint32
+	start := 0
+	for pos := 0; pos < len(cookies); {
+		end := bytes.IndexByte(cookies[pos:], '\n')
+		if end < 0 {
+			end = len(cookies)
+		} else {
+			end += pos + 1
+		}
+
+		line := cookies[pos:end]
+		isDelimiter := len(line) == 2 && line[0] == Delimiter && line[1] == '\n'
+		if isDelimiter || end == len(cookies) {
+			length := pos - start
+			if !isDelimiter {
+				length = end - start
+			}
+			start = end
+			if length > 0 {
+				offsets = append(offsets, uint32(end))
+				if uint32(length) > longest {
+					longest = uint32(length)
+				}
+				if shortest == 0 || uint32(length) < shortest {
+					shortest = uint32(length)
+				}
+			}
+		}
+		pos = end
+	}
+
+	var buf bytes.Buffer
+	header := []uint32{strfileVersion, uint32(len(offsets) - 1), longest, shortest, 0}
+	binary.Write(&buf, binary.BigEndian, header)
+	buf.Write([]byte{Delimiter, 0, 0, 0})
+	binary.Write(&buf, binary.BigEndian, offsets)
+	return buf.Bytes()
+}
@@ -0,0 +1,110 @@
+package fortune
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	"github.com/apimgr/quotes/src/collection"
+)
+
+// testItem is a minimal item with a text and an author
+type testItem struct {
+	ID     int    `json:"id"`
+	Text   string `json:"text"`
+	Author string `json:"author"`
+}
+
+func (t testItem) GetID() int {
+	return t.ID
+}
+
+func (t testItem) Field(name string) string {
+	switch name {
+	case "text":
+		return t.Text
+	case "author":
+		return t.Author
+	}
+	return ""
+}
+
+var testInfo = collection.Info{
+	Name:        "test",
+	Title:       "test items",
+	ItemName:    "test item",
+	TextField:   "text",
+	Fields:      []collection.Field{{Name: "author", Path: "author"}},
+	Attribution: []string{"author"},
+}
+
+func TestEntry(t *testing.T) {
+	tests := []struct {
+		item testItem
+		want string
+	}{
+		{testItem{1, "Hello", "Ada"}, "Hello\n\t\t-- Ada"},
+		{testItem{2, "No author\n", ""}, "No author"},
+		{testItem{3, "Before\n%\nAfter", ""}, "Before\n %\nAfter"},
+	}
+
+	for _, tt := range tests {
+		if got := Entry(testInfo, tt.item); got != tt.want {
+			t.Errorf("Entry(%d) = %q, want %q", tt.item.ID, got, tt.want)
+		}
+	}
+}
+
+// readIndex decodes a strfile index into its header and offsets
+func readIndex(t *testing.T, dat []byte) ([5]uint32, byte, []uint32) {
+	t.Helper()
+
+	r := bytes.NewReader(dat)
+	var header [5]uint32
+	var stuff [4]byte
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		t.Fatalf("failed to read header: %v", err)
+	}
+	if err := binary.Read(r, binary.BigEndian, &stuff); err != nil {
+		t.Fatalf("failed to read delimiter: %v", err)
+	}
+	offsets := make([]uint32, r.Len()/4)
+	if err := binary.Read(r, binary.BigEndian, offsets); err != nil {
+		t.Fatalf("failed to read offsets: %v", err)
+	}
+	return header, stuff[0], offsets
+}
+
+func TestIndex(t *testing.T) {
+	// Cookies of 6, 12 and 4 bytes including their newlines
+	cookies := []byte("first\n%\nsecond\nline\n%\nend\n%\n")
+
+	header, delimiter, offsets := readIndex(t, Index(cookies))
+	if want := [5]uint32{2, 3, 12, 4, 0}; header != want {
+		t.Errorf("header = %v, want %v", header, want)
+	}
+	if delimiter != '%' {
+		t.Errorf("delimiter = %q, want '%%'", delimiter)
+	}
+	if want := []uint32{0, 8, 22, 28}; !slices.Equal(offsets, want) {
+		t.Errorf("offsets = %v, want %v", offsets, want)
+	}
+
+	// Every offset but the last starts a cookie
+	for _, off := range offsets[:len(offsets)-1] {
+		if cookies[off] == '%' {
+			t.Errorf("offset %d points at a delimiter", off)
+		}
+	}
+}
+
+func TestIndexWithoutTrailingDelimiter(t *testing.T) {
+	header, _, offsets := readIndex(t, Index([]byte("one\n%\ntwo\n")))
+	if header[1] != 2 {
+		t.Errorf("numstr = %d, want 2", header[1])
+	}
+	if want := []uint32{0, 6, 10}; !slices.Equal(offsets, want) {
+		t.Errorf("offsets = %v, want %v", offsets, want)
+	}
+}
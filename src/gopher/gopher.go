@@ -0,0 +1,114 @@
+package gopher
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Item types of menu entries, as listed in RFC 1436
+const (
+	TypeText   = '0'
+	TypeMenu   = '1'
+	TypeError  = '3'
+	TypeSearch = '7'
+	TypeInfo   = 'i' // Not in the RFC, but understood by every current client
+)
+
+// Request is a Gopher request: a selector, and the query of a search
+type Request struct {
+	Selector string
+	Query    string
+}
+
+// ParseRequest parses a request line. Search requests hold the query after
+// a tab; Gopher+ clients may add more fields, which are ignored.
+func ParseRequest(line string) Request {
+	selector, rest, _ := strings.Cut(line, "\t")
+	query, _, _ := strings.Cut(rest, "\t")
+	return Request{Selector: selector, Query: query}
+}
+
+// item is an entry of a menu
+type item struct {
+	kind     byte
+	display  string
+	selector string
+}
+
+// Menu is a directory listing. Its links point to the host and port it was
+// created with.
+type Menu struct {
+	host  string
+	port  int
+	items []item
+}
+
+// NewMenu returns an empty menu linking to host and port
+func NewMenu(host string, port int) *Menu {
+	return &Menu{host: host, port: port}
+}
+
+// clean makes text safe for a menu field, which must not hold tabs or line breaks
+func clean(text string) string {
+	return strings.NewReplacer("\t", "    ", "\r", "", "\n", " ").Replace(text)
+}
+
+// Link adds an entry of the given type leading to a selector on this server
+func (m *Menu) Link(kind byte, display, selector string) {
+	m.items = append(m.items, item{kind: kind, display: clean(display), selector: clean(selector)})
+}
+
+// Info adds lines of text that lead nowhere
+func (m *Menu) Info(text string) {
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		m.items = append(m.items, item{kind: TypeInfo, display: clean(line)})
+	}
+}
+
+// Error adds an error line
+func (m *Menu) Error(text string) {
+	m.items = append(m.items, item{kind: TypeError, display: clean(text)})
+}
+
+// WriteTo writes the menu, ended by a line holding a period
+func (m *Menu) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	for _, it := range m.items {
+		if it.kind == TypeInfo || it.kind == TypeError {
+			// Lines leading nowhere get the conventional dummy selector and host
+			fmt.Fprintf(&b, "%c%s\t\terror.host\t1\r\n", it.kind, it.display)
+			continue
+		}
+		fmt.Fprintf(&b, "%c%s\t%s\t%s\t%d\r\n", it.kind, it.display, it.selector, m.host, m.port)
+	}
+	b.WriteString(".\r\n")
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// WriteText writes a text document with CRLF line endings, doubling the
+// leading period of lines so none is taken for the closing one
+func WriteText(w io.Writer, text string) error {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, ".") {
+			b.WriteByte('.')
+		}
+		b.WriteString(line)
+		b.WriteString("\r\n")
+	}
+	b.WriteString(".\r\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteError writes a menu holding only an error line, the usual answer to
+// an unknown selector
+func WriteError(w io.Writer, text string) error {
+	m := &Menu{}
+	m.Error(text)
+	_, err := m.WriteTo(w)
+	return err
+}
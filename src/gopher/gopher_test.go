@@ -0,0 +1,57 @@
+package gopher
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRequest(t *testing.T) {
+	tests := map[string]Request{
+		"":                      {},
+		"/quotes":               {Selector: "/quotes"},
+		"/quotes/search\tlove":  {Selector: "/quotes/search", Query: "love"},
+		"/quotes/search\tx\t+":  {Selector: "/quotes/search", Query: "x"},
+		"/quotes/item/1\t\t$":   {Selector: "/quotes/item/1"},
+		"/a b/c\tquery with sp": {Selector: "/a b/c", Query: "query with sp"},
+	}
+	for line, want := range tests {
+		if got := ParseRequest(line); got != want {
+			t.Errorf("ParseRequest(%q) = %+v, want %+v", line, got, want)
+		}
+	}
+}
+
+func TestMenu(t *testing.T) {
+	m := NewMenu("example.org", 70)
+	m.Info("Welcome\nto the server")
+	m.Link(TypeMenu, "Quotes", "/quotes")
+	m.Link(TypeText, "A\ttabbed\nquote", "/quotes/item/1")
+	m.Link(TypeSearch, "Search", "/quotes/search")
+	m.Error("Oops")
+
+	var b strings.Builder
+	if _, err := m.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := "iWelcome\t\terror.host\t1\r\n" +
+		"ito the server\t\terror.host\t1\r\n" +
+		"1Quotes\t/quotes\texample.org\t70\r\n" +
+		"0A    tabbed quote\t/quotes/item/1\texample.org\t70\r\n" +
+		"7Search\t/quotes/search\texample.org\t70\r\n" +
+		"3Oops\t\terror.host\t1\r\n" +
+		".\r\n"
+	if b.String() != want {
+		t.Errorf("menu = %q, want %q", b.String(), want)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var b strings.Builder
+	if err := WriteText(&b, "First line\n.\n..dots\r\nlast\n"); err != nil {
+		t.Fatal(err)
+	}
+	want := "First line\r\n..\r\n...dots\r\nlast\r\n.\r\n"
+	if b.String() != want {
+		t.Errorf("text = %q, want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,125 @@
+package lineserver
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxRequestLength is the longest request line accepted, in bytes
+	MaxRequestLength = 1024
+
+	// maxConns is the number of connections served at once; more are
+	// closed right away
+	maxConns = 256
+
+	// timeout is how long a client has to send its request, and then to
+	// take the response
+	timeout = 30 * time.Second
+)
+
+// Handler writes the response to a request line, given without its line ending
+type Handler func(w io.Writer, request string)
+
+// Server answers protocols such as Gopher and Finger, where a client
+// connects, sends one line and reads until the server closes the connection
+type Server struct {
+	ln      net.Listener
+	handler Handler
+	conns   chan struct{} // One token per connection being served
+	wg      sync.WaitGroup
+}
+
+// Listen listens on addr over TCP and answers each request with handler, until Close
+func Listen(addr string, handler Handler) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{ln: ln, handler: handler, conns: make(chan struct{}, maxConns)}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address of the listener
+func (s *Server) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
+// Close stops the listener and waits for the connections being served
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	s.wg.Wait()
+	return err
+}
+
+// serve accepts connections until the listener is closed
+func (s *Server) serve() {
+	defer s.wg.Done()
+	var delay time.Duration
+	for {
+		conn, err := s.ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			// Out of file descriptors and the like: back off as net/http does
+			delay = min(max(2*delay, 5*time.Millisecond), time.Second)
+			log.Printf("⚠️  Warning: accept on %s failed, retrying in %v: %v", s.ln.Addr(), delay, err)
+			time.Sleep(delay)
+			continue
+		}
+		delay = 0
+
+		select {
+		case s.conns <- struct{}{}:
+		default:
+			conn.Close()
+			continue
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() { <-s.conns }()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// serveConn reads the request line of a connection and writes the response
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	request, err := readLine(bufio.NewReaderSize(conn, MaxRequestLength))
+	if err != nil {
+		return
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(timeout))
+	w := bufio.NewWriter(conn)
+	s.handler(w, request)
+	w.Flush()
+}
+
+// readLine reads a line ending in LF or CRLF of at most MaxRequestLength
+// bytes. A client closing its side after the line without an ending is
+// also accepted, as some do.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		return "", errors.New("request line too long")
+	}
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
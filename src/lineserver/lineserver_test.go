@@ -0,0 +1,88 @@
+package lineserver
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// request sends a request to the server at addr and returns the response
+func request(t *testing.T, addr, req string) string {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.WriteString(conn, req); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(resp)
+}
+
+func TestServer(t *testing.T) {
+	s, err := Listen("127.0.0.1:0", func(w io.Writer, req string) {
+		fmt.Fprintf(w, "got %q\r\n", req)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	addr := s.Addr().String()
+
+	tests := map[string]string{
+		"hello\r\n":       "got \"hello\"\r\n",
+		"a\tb\n":          "got \"a\\tb\"\r\n",
+		"\r\n":            "got \"\"\r\n",
+		"first\r\nsecond": "got \"first\"\r\n",
+	}
+	for req, want := range tests {
+		if got := request(t, addr, req); got != want {
+			t.Errorf("response to %q = %q, want %q", req, got, want)
+		}
+	}
+
+	// Too long a request gets no response; the connection may be reset
+	// since the server leaves the rest of it unread
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	io.WriteString(conn, strings.Repeat("x", MaxRequestLength+10)+"\r\n")
+	if resp, _ := io.ReadAll(conn); len(resp) != 0 {
+		t.Errorf("response to a long request = %q, want none", resp)
+	}
+}
+
+func TestCloseWithoutNewline(t *testing.T) {
+	s, err := Listen("127.0.0.1:0", func(w io.Writer, req string) {
+		io.WriteString(w, req)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	io.WriteString(conn, "partial")
+	conn.(*net.TCPConn).CloseWrite()
+	resp, _ := io.ReadAll(conn)
+	if string(resp) != "partial" {
+		t.Errorf("response = %q, want %q", resp, "partial")
+	}
+}
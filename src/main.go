@@ -8,11 +8,15 @@
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 
 	"github.com/apimgr/quotes/src/anime"
 	"github.com/apimgr/quotes/src/chucknorris"
+	"github.com/apimgr/quotes/src/collection"
 	"github.com/apimgr/quotes/src/dadjokes"
 	"github.com/apimgr/quotes/src/database"
+	"github.com/apimgr/quotes/src/fortune"
 	"github.com/apimgr/quotes/src/paths"
 	"github.com/apimgr/quotes/src/programming"
 	"github.com/apimgr/quotes/src/quotes"
@@ -45,8 +49,15 @@ func main() {
 	// Command-line flags
 	port := flag.String("port", getEnv("PORT", "8080"), "Server port")
 	address := flag.String("address", getEnv("ADDRESS", "0.0.0.0"), "Server address")
+	grpcPort := flag.String("grpc-port", getEnv("GRPC_PORT", ""), "gRPC API port, e.g. 9090 (empty to disable)")
+	qotd := flag.Bool("qotd", getEnvBool("QOTD_ENABLED", false), "Serve Quote of the Day (RFC 865) over TCP and UDP on the QOTD port")
+	qotdPort := flag.String("qotd-port", getEnv("QOTD_PORT", "1717"), "Quote of the Day (RFC 865) TCP and UDP port, 17 being the standard one")
+	gopherPort := flag.String("gopher-port", getEnv("GOPHER_PORT", ""), "Gopher (RFC 1436) port, e.g. 70 or 7070 (empty to disable)")
+	fingerPort := flag.String("finger-port", getEnv("FINGER_PORT", ""), "Finger (RFC 1288) port, e.g. 79 or 7979 (empty to disable)")
+	dnsPort := flag.String("dns-port", getEnv("DNS_PORT", ""), "DNS TXT record UDP and TCP port, e.g. 53 or 5353 (empty to disable)")
 	showVersion := flag.Bool("version", false, "Show version information")
 	showStatus := flag.Bool("status", false, "Show status (for health checks)")
+	exportFortune := flag.String("export-fortune", "", "Write every collection as fortune cookie and .dat files to this directory and exit")
 	flag.Parse()
 
 	// Show version
@@ -63,6 +74,15 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Export fortune files
+	if *exportFortune != "" {
+		loadCollections()
+		if err := exportFortunes(*exportFortune); err != nil {
+			log.Fatalf("Failed to export fortune files: %v", err)
+		}
+		os.Exit(0)
+	}
+
 	log.Printf("Starting Quotes API v%s", Version)
 
 	// Get directories
@@ -120,51 +140,75 @@ func main() {
 		}
 	}
 
-	// Load quotes from embedded data
-	log.Println("Loading quotes...")
-	if err := quotes.LoadQuotes(quotesData); err != nil {
-		log.Fatalf("Failed to load quotes: %v", err)
-	}
-	log.Printf("✅ Loaded %d quotes", quotes.GetTotalCount())
+	// Load and register collections from embedded data
+	loadCollections()
 
-	// Load anime quotes from embedded data
-	log.Println("Loading anime quotes...")
-	if err := anime.LoadQuotes(animeData); err != nil {
-		log.Fatalf("Failed to load anime quotes: %v", err)
-	}
-	log.Printf("✅ Loaded %d anime quotes", anime.GetTotalCount())
+	// Set version information in server
+	server.Version = Version
+	server.Commit = Commit
+	server.BuildDate = BuildDate
 
-	// Load Chuck Norris jokes from embedded data
-	log.Println("Loading Chuck Norris jokes...")
-	if err := chucknorris.LoadJokes(chuckNorrisData); err != nil {
-		log.Fatalf("Failed to load Chuck Norris jokes: %v", err)
+	// Start server; Quote of the Day only listens when enabled
+	if !*qotd {
+		*qotdPort = ""
 	}
-	log.Printf("✅ Loaded %d Chuck Norris jokes", chucknorris.GetTotalCount())
+	srv := server.NewServer(*port, *address, server.ProtocolPorts{
+		GRPC:   *grpcPort,
+		QOTD:   *qotdPort,
+		Gopher: *gopherPort,
+		Finger: *fingerPort,
+		DNS:    *dnsPort,
+	})
+	if err := srv.Start(); err != nil {
+		log.Fatalf("Server failed to start: %v", err)
+	}
+}
 
-	// Load dad jokes from embedded data
-	log.Println("Loading dad jokes...")
-	if err := dadjokes.LoadJokes(dadJokesData); err != nil {
-		log.Fatalf("Failed to load dad jokes: %v", err)
+// loadCollections loads and registers every collection from embedded data
+func loadCollections() {
+	datasets := []struct {
+		collection collection.Collection
+		data       []byte
+	}{
+		{quotes.Collection, quotesData},
+		{anime.Collection, animeData},
+		{chucknorris.Collection, chuckNorrisData},
+		{dadjokes.Collection, dadJokesData},
+		{programming.Collection, programmingData},
 	}
-	log.Printf("✅ Loaded %d dad jokes", dadjokes.GetTotalCount())
 
-	// Load programming jokes from embedded data
-	log.Println("Loading programming jokes...")
-	if err := programming.LoadJokes(programmingData); err != nil {
-		log.Fatalf("Failed to load programming jokes: %v", err)
+	for _, ds := range datasets {
+		info := ds.collection.Info()
+		log.Printf("Loading %s...", info.Title)
+		if err := ds.collection.Load(ds.data); err != nil {
+			log.Fatalf("Failed to load %s: %v", info.Title, err)
+		}
+		if err := collection.Register(ds.collection); err != nil {
+			log.Fatalf("Failed to register %s: %v", info.Title, err)
+		}
+		log.Printf("✅ Loaded %d %s", ds.collection.Count(), info.Title)
 	}
-	log.Printf("✅ Loaded %d programming jokes", programming.GetTotalCount())
+}
 
-	// Set version information in server
-	server.Version = Version
-	server.Commit = Commit
-	server.BuildDate = BuildDate
+// exportFortunes writes each registered collection as a fortune cookie file
+// and its strfile index to dir
+func exportFortunes(dir string) error {
+	if err := paths.EnsureDir(dir); err != nil {
+		return err
+	}
 
-	// Start server
-	srv := server.NewServer(*port, *address)
-	if err := srv.Start(); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	for _, c := range collection.All() {
+		name := c.Info().Name
+		cookies := fortune.Cookies(c)
+		if err := os.WriteFile(filepath.Join(dir, name), cookies, 0644); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".dat"), fortune.Index(cookies), 0644); err != nil {
+			return err
+		}
+		log.Printf("✅ Exported %d %s to %s", c.Count(), c.Info().Title, filepath.Join(dir, name))
 	}
+	return nil
 }
 
 // getEnv gets an environment variable or returns a default value
@@ -175,6 +219,16 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvBool returns a boolean environment variable, or defaultValue when
+// it is unset or invalid
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 // generateRandomPassword generates a random password
 func generateRandomPassword() string {
 	bytes := make([]byte, 16)
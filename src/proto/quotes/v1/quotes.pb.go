@@ -0,0 +1,1028 @@
+// Messages and service of the gRPC API. Regenerate the Go code with
+// `make proto` after changing this file.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: quotes/v1/quotes.proto
+
+package quotesv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Period int32
+
+const (
+	Period_PERIOD_UNSPECIFIED Period = 0 // Daily
+	Period_PERIOD_HOURLY      Period = 1
+	Period_PERIOD_DAILY       Period = 2
+	Period_PERIOD_WEEKLY      Period = 3
+)
+
+// Enum value maps for Period.
+var (
+	Period_name = map[int32]string{
+		0: "PERIOD_UNSPECIFIED",
+		1: "PERIOD_HOURLY",
+		2: "PERIOD_DAILY",
+		3: "PERIOD_WEEKLY",
+	}
+	Period_value = map[string]int32{
+		"PERIOD_UNSPECIFIED": 0,
+		"PERIOD_HOURLY":      1,
+		"PERIOD_DAILY":       2,
+		"PERIOD_WEEKLY":      3,
+	}
+)
+
+func (x Period) Enum() *Period {
+	p := new(Period)
+	*p = x
+	return p
+}
+
+func (x Period) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Period) Descriptor() protoreflect.EnumDescriptor {
+	return file_quotes_v1_quotes_proto_enumTypes[0].Descriptor()
+}
+
+func (Period) Type() protoreflect.EnumType {
+	return &file_quotes_v1_quotes_proto_enumTypes[0]
+}
+
+func (x Period) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Period.Descriptor instead.
+func (Period) EnumDescriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{0}
+}
+
+type Item struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Collection    string                 `protobuf:"bytes,2,opt,name=collection,proto3" json:"collection,omitempty"`
+	Text          string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`                                                                               // The quote or joke
+	Fields        map[string]string      `protobuf:"bytes,4,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // e.g. author, category, anime, character
+	Attribution   string                 `protobuf:"bytes,5,opt,name=attribution,proto3" json:"attribution,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Item) Reset() {
+	*x = Item{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Item) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Item) ProtoMessage() {}
+
+func (x *Item) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Item.ProtoReflect.Descriptor instead.
+func (*Item) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Item) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Item) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *Item) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Item) GetFields() map[string]string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *Item) GetAttribution() string {
+	if x != nil {
+		return x.Attribution
+	}
+	return ""
+}
+
+type Filter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fields        map[string]string      `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Field filters, matched like the random filters
+	MinLength     int32                  `protobuf:"varint,2,opt,name=min_length,json=minLength,proto3" json:"min_length,omitempty"`
+	MaxLength     int32                  `protobuf:"varint,3,opt,name=max_length,json=maxLength,proto3" json:"max_length,omitempty"`
+	ExcludeIds    []int32                `protobuf:"varint,4,rep,packed,name=exclude_ids,json=excludeIds,proto3" json:"exclude_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Filter) Reset() {
+	*x = Filter{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Filter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Filter) ProtoMessage() {}
+
+func (x *Filter) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Filter.ProtoReflect.Descriptor instead.
+func (*Filter) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Filter) GetFields() map[string]string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *Filter) GetMinLength() int32 {
+	if x != nil {
+		return x.MinLength
+	}
+	return 0
+}
+
+func (x *Filter) GetMaxLength() int32 {
+	if x != nil {
+		return x.MaxLength
+	}
+	return 0
+}
+
+func (x *Filter) GetExcludeIds() []int32 {
+	if x != nil {
+		return x.ExcludeIds
+	}
+	return nil
+}
+
+type RandomRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"` // Default: quotes
+	Filter        *Filter                `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+	Count         int32                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`             // Default: 1, max: 100
+	Seed          *uint64                `protobuf:"varint,4,opt,name=seed,proto3,oneof" json:"seed,omitempty"`         // Makes the pick repeatable
+	Weighted      *bool                  `protobuf:"varint,5,opt,name=weighted,proto3,oneof" json:"weighted,omitempty"` // Default: the random.weighted setting
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RandomRequest) Reset() {
+	*x = RandomRequest{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RandomRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RandomRequest) ProtoMessage() {}
+
+func (x *RandomRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RandomRequest.ProtoReflect.Descriptor instead.
+func (*RandomRequest) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RandomRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *RandomRequest) GetFilter() *Filter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *RandomRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *RandomRequest) GetSeed() uint64 {
+	if x != nil && x.Seed != nil {
+		return *x.Seed
+	}
+	return 0
+}
+
+func (x *RandomRequest) GetWeighted() bool {
+	if x != nil && x.Weighted != nil {
+		return *x.Weighted
+	}
+	return false
+}
+
+type RandomResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Item                `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Seed          uint64                 `protobuf:"varint,2,opt,name=seed,proto3" json:"seed,omitempty"` // Seed used, to replay the pick
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RandomResponse) Reset() {
+	*x = RandomResponse{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RandomResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RandomResponse) ProtoMessage() {}
+
+func (x *RandomResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RandomResponse.ProtoReflect.Descriptor instead.
+func (*RandomResponse) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RandomResponse) GetItems() []*Item {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *RandomResponse) GetSeed() uint64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	Id            int32                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *GetRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	Filter        *Filter                `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+	Sort          string                 `protobuf:"bytes,3,opt,name=sort,proto3" json:"sort,omitempty"` // id (default), the text field, a field or length
+	Descending    bool                   `protobuf:"varint,4,opt,name=descending,proto3" json:"descending,omitempty"`
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"` // 0 streams every item
+	Offset        int32                  `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *ListRequest) GetFilter() *Filter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ListRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *ListRequest) GetDescending() bool {
+	if x != nil {
+		return x.Descending
+	}
+	return false
+}
+
+func (x *ListRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Collections   []string               `protobuf:"bytes,2,rep,name=collections,proto3" json:"collections,omitempty"` // Default: every collection
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`            // Default: 50, max: 500
+	Offset        int32                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetCollections() []string {
+	if x != nil {
+		return x.Collections
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         int32                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Results       []*SearchResult        `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SearchResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *SearchResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type SearchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	Snippet       string                 `protobuf:"bytes,3,opt,name=snippet,proto3" json:"snippet,omitempty"`
+	Item          *Item                  `protobuf:"bytes,4,opt,name=item,proto3" json:"item,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SearchResult) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *SearchResult) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SearchResult) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+func (x *SearchResult) GetItem() *Item {
+	if x != nil {
+		return x.Item
+	}
+	return nil
+}
+
+type DailyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	Period        Period                 `protobuf:"varint,2,opt,name=period,proto3,enum=quotes.v1.Period" json:"period,omitempty"`
+	Timezone      string                 `protobuf:"bytes,3,opt,name=timezone,proto3" json:"timezone,omitempty"` // IANA name, default: UTC
+	Date          string                 `protobuf:"bytes,4,opt,name=date,proto3" json:"date,omitempty"`         // YYYY-MM-DD, YYYY-MM-DDTHH or RFC 3339; default: now
+	Filter        *Filter                `protobuf:"bytes,5,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DailyRequest) Reset() {
+	*x = DailyRequest{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DailyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DailyRequest) ProtoMessage() {}
+
+func (x *DailyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DailyRequest.ProtoReflect.Descriptor instead.
+func (*DailyRequest) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DailyRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *DailyRequest) GetPeriod() Period {
+	if x != nil {
+		return x.Period
+	}
+	return Period_PERIOD_UNSPECIFIED
+}
+
+func (x *DailyRequest) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *DailyRequest) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *DailyRequest) GetFilter() *Filter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type DailyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	Period        Period                 `protobuf:"varint,2,opt,name=period,proto3,enum=quotes.v1.Period" json:"period,omitempty"`
+	Key           string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Timezone      string                 `protobuf:"bytes,4,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	StartsAt      string                 `protobuf:"bytes,5,opt,name=starts_at,json=startsAt,proto3" json:"starts_at,omitempty"` // RFC 3339
+	EndsAt        string                 `protobuf:"bytes,6,opt,name=ends_at,json=endsAt,proto3" json:"ends_at,omitempty"`
+	Item          *Item                  `protobuf:"bytes,7,opt,name=item,proto3" json:"item,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DailyResponse) Reset() {
+	*x = DailyResponse{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DailyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DailyResponse) ProtoMessage() {}
+
+func (x *DailyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DailyResponse.ProtoReflect.Descriptor instead.
+func (*DailyResponse) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DailyResponse) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *DailyResponse) GetPeriod() Period {
+	if x != nil {
+		return x.Period
+	}
+	return Period_PERIOD_UNSPECIFIED
+}
+
+func (x *DailyResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *DailyResponse) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *DailyResponse) GetStartsAt() string {
+	if x != nil {
+		return x.StartsAt
+	}
+	return ""
+}
+
+func (x *DailyResponse) GetEndsAt() string {
+	if x != nil {
+		return x.EndsAt
+	}
+	return ""
+}
+
+func (x *DailyResponse) GetItem() *Item {
+	if x != nil {
+		return x.Item
+	}
+	return nil
+}
+
+var File_quotes_v1_quotes_proto protoreflect.FileDescriptor
+
+const file_quotes_v1_quotes_proto_rawDesc = "" +
+	"\n" +
+	"\x16quotes/v1/quotes.proto\x12\tquotes.v1\"\xdc\x01\n" +
+	"\x04Item\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x02 \x01(\tR\n" +
+	"collection\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\x123\n" +
+	"\x06fields\x18\x04 \x03(\v2\x1b.quotes.v1.Item.FieldsEntryR\x06fields\x12 \n" +
+	"\vattribution\x18\x05 \x01(\tR\vattribution\x1a9\n" +
+	"\vFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd9\x01\n" +
+	"\x06Filter\x125\n" +
+	"\x06fields\x18\x01 \x03(\v2\x1d.quotes.v1.Filter.FieldsEntryR\x06fields\x12\x1d\n" +
+	"\n" +
+	"min_length\x18\x02 \x01(\x05R\tminLength\x12\x1d\n" +
+	"\n" +
+	"max_length\x18\x03 \x01(\x05R\tmaxLength\x12\x1f\n" +
+	"\vexclude_ids\x18\x04 \x03(\x05R\n" +
+	"excludeIds\x1a9\n" +
+	"\vFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc0\x01\n" +
+	"\rRandomRequest\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\x12)\n" +
+	"\x06filter\x18\x02 \x01(\v2\x11.quotes.v1.FilterR\x06filter\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x05R\x05count\x12\x17\n" +
+	"\x04seed\x18\x04 \x01(\x04H\x00R\x04seed\x88\x01\x01\x12\x1f\n" +
+	"\bweighted\x18\x05 \x01(\bH\x01R\bweighted\x88\x01\x01B\a\n" +
+	"\x05_seedB\v\n" +
+	"\t_weighted\"K\n" +
+	"\x0eRandomResponse\x12%\n" +
+	"\x05items\x18\x01 \x03(\v2\x0f.quotes.v1.ItemR\x05items\x12\x12\n" +
+	"\x04seed\x18\x02 \x01(\x04R\x04seed\"<\n" +
+	"\n" +
+	"GetRequest\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x05R\x02id\"\xba\x01\n" +
+	"\vListRequest\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\x12)\n" +
+	"\x06filter\x18\x02 \x01(\v2\x11.quotes.v1.FilterR\x06filter\x12\x12\n" +
+	"\x04sort\x18\x03 \x01(\tR\x04sort\x12\x1e\n" +
+	"\n" +
+	"descending\x18\x04 \x01(\bR\n" +
+	"descending\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x06 \x01(\x05R\x06offset\"u\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12 \n" +
+	"\vcollections\x18\x02 \x03(\tR\vcollections\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\"Y\n" +
+	"\x0eSearchResponse\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x05R\x05total\x121\n" +
+	"\aresults\x18\x02 \x03(\v2\x17.quotes.v1.SearchResultR\aresults\"\x83\x01\n" +
+	"\fSearchResult\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\x12\x18\n" +
+	"\asnippet\x18\x03 \x01(\tR\asnippet\x12#\n" +
+	"\x04item\x18\x04 \x01(\v2\x0f.quotes.v1.ItemR\x04item\"\xb4\x01\n" +
+	"\fDailyRequest\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\x12)\n" +
+	"\x06period\x18\x02 \x01(\x0e2\x11.quotes.v1.PeriodR\x06period\x12\x1a\n" +
+	"\btimezone\x18\x03 \x01(\tR\btimezone\x12\x12\n" +
+	"\x04date\x18\x04 \x01(\tR\x04date\x12)\n" +
+	"\x06filter\x18\x05 \x01(\v2\x11.quotes.v1.FilterR\x06filter\"\xe3\x01\n" +
+	"\rDailyResponse\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\x12)\n" +
+	"\x06period\x18\x02 \x01(\x0e2\x11.quotes.v1.PeriodR\x06period\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x12\x1a\n" +
+	"\btimezone\x18\x04 \x01(\tR\btimezone\x12\x1b\n" +
+	"\tstarts_at\x18\x05 \x01(\tR\bstartsAt\x12\x17\n" +
+	"\aends_at\x18\x06 \x01(\tR\x06endsAt\x12#\n" +
+	"\x04item\x18\a \x01(\v2\x0f.quotes.v1.ItemR\x04item*X\n" +
+	"\x06Period\x12\x16\n" +
+	"\x12PERIOD_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rPERIOD_HOURLY\x10\x01\x12\x10\n" +
+	"\fPERIOD_DAILY\x10\x02\x12\x11\n" +
+	"\rPERIOD_WEEKLY\x10\x032\xab\x02\n" +
+	"\rQuotesService\x12=\n" +
+	"\x06Random\x12\x18.quotes.v1.RandomRequest\x1a\x19.quotes.v1.RandomResponse\x12-\n" +
+	"\x03Get\x12\x15.quotes.v1.GetRequest\x1a\x0f.quotes.v1.Item\x121\n" +
+	"\x04List\x12\x16.quotes.v1.ListRequest\x1a\x0f.quotes.v1.Item0\x01\x12=\n" +
+	"\x06Search\x12\x18.quotes.v1.SearchRequest\x1a\x19.quotes.v1.SearchResponse\x12:\n" +
+	"\x05Daily\x12\x17.quotes.v1.DailyRequest\x1a\x18.quotes.v1.DailyResponseB7Z5github.com/apimgr/quotes/src/proto/quotes/v1;quotesv1b\x06proto3"
+
+var (
+	file_quotes_v1_quotes_proto_rawDescOnce sync.Once
+	file_quotes_v1_quotes_proto_rawDescData []byte
+)
+
+func file_quotes_v1_quotes_proto_rawDescGZIP() []byte {
+	file_quotes_v1_quotes_proto_rawDescOnce.Do(func() {
+		file_quotes_v1_quotes_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_quotes_v1_quotes_proto_rawDesc), len(file_quotes_v1_quotes_proto_rawDesc)))
+	})
+	return file_quotes_v1_quotes_proto_rawDescData
+}
+
+var file_quotes_v1_quotes_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_quotes_v1_quotes_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_quotes_v1_quotes_proto_goTypes = []any{
+	(Period)(0),            // 0: quotes.v1.Period
+	(*Item)(nil),           // 1: quotes.v1.Item
+	(*Filter)(nil),         // 2: quotes.v1.Filter
+	(*RandomRequest)(nil),  // 3: quotes.v1.RandomRequest
+	(*RandomResponse)(nil), // 4: quotes.v1.RandomResponse
+	(*GetRequest)(nil),     // 5: quotes.v1.GetRequest
+	(*ListRequest)(nil),    // 6: quotes.v1.ListRequest
+	(*SearchRequest)(nil),  // 7: quotes.v1.SearchRequest
+	(*SearchResponse)(nil), // 8: quotes.v1.SearchResponse
+	(*SearchResult)(nil),   // 9: quotes.v1.SearchResult
+	(*DailyRequest)(nil),   // 10: quotes.v1.DailyRequest
+	(*DailyResponse)(nil),  // 11: quotes.v1.DailyResponse
+	nil,                    // 12: quotes.v1.Item.FieldsEntry
+	nil,                    // 13: quotes.v1.Filter.FieldsEntry
+}
+var file_quotes_v1_quotes_proto_depIdxs = []int32{
+	12, // 0: quotes.v1.Item.fields:type_name -> quotes.v1.Item.FieldsEntry
+	13, // 1: quotes.v1.Filter.fields:type_name -> quotes.v1.Filter.FieldsEntry
+	2,  // 2: quotes.v1.RandomRequest.filter:type_name -> quotes.v1.Filter
+	1,  // 3: quotes.v1.RandomResponse.items:type_name -> quotes.v1.Item
+	2,  // 4: quotes.v1.ListRequest.filter:type_name -> quotes.v1.Filter
+	9,  // 5: quotes.v1.SearchResponse.results:type_name -> quotes.v1.SearchResult
+	1,  // 6: quotes.v1.SearchResult.item:type_name -> quotes.v1.Item
+	0,  // 7: quotes.v1.DailyRequest.period:type_name -> quotes.v1.Period
+	2,  // 8: quotes.v1.DailyRequest.filter:type_name -> quotes.v1.Filter
+	0,  // 9: quotes.v1.DailyResponse.period:type_name -> quotes.v1.Period
+	1,  // 10: quotes.v1.DailyResponse.item:type_name -> quotes.v1.Item
+	3,  // 11: quotes.v1.QuotesService.Random:input_type -> quotes.v1.RandomRequest
+	5,  // 12: quotes.v1.QuotesService.Get:input_type -> quotes.v1.GetRequest
+	6,  // 13: quotes.v1.QuotesService.List:input_type -> quotes.v1.ListRequest
+	7,  // 14: quotes.v1.QuotesService.Search:input_type -> quotes.v1.SearchRequest
+	10, // 15: quotes.v1.QuotesService.Daily:input_type -> quotes.v1.DailyRequest
+	4,  // 16: quotes.v1.QuotesService.Random:output_type -> quotes.v1.RandomResponse
+	1,  // 17: quotes.v1.QuotesService.Get:output_type -> quotes.v1.Item
+	1,  // 18: quotes.v1.QuotesService.List:output_type -> quotes.v1.Item
+	8,  // 19: quotes.v1.QuotesService.Search:output_type -> quotes.v1.SearchResponse
+	11, // 20: quotes.v1.QuotesService.Daily:output_type -> quotes.v1.DailyResponse
+	16, // [16:21] is the sub-list for method output_type
+	11, // [11:16] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_quotes_v1_quotes_proto_init() }
+func file_quotes_v1_quotes_proto_init() {
+	if File_quotes_v1_quotes_proto != nil {
+		return
+	}
+	file_quotes_v1_quotes_proto_msgTypes[2].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_quotes_v1_quotes_proto_rawDesc), len(file_quotes_v1_quotes_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_quotes_v1_quotes_proto_goTypes,
+		DependencyIndexes: file_quotes_v1_quotes_proto_depIdxs,
+		EnumInfos:         file_quotes_v1_quotes_proto_enumTypes,
+		MessageInfos:      file_quotes_v1_quotes_proto_msgTypes,
+	}.Build()
+	File_quotes_v1_quotes_proto = out.File
+	file_quotes_v1_quotes_proto_goTypes = nil
+	file_quotes_v1_quotes_proto_depIdxs = nil
+}
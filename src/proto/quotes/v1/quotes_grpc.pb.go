@@ -0,0 +1,280 @@
+// Messages and service of the gRPC API. Regenerate the Go code with
+// `make proto` after changing this file.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: quotes/v1/quotes.proto
+
+package quotesv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	QuotesService_Random_FullMethodName = "/quotes.v1.QuotesService/Random"
+	QuotesService_Get_FullMethodName    = "/quotes.v1.QuotesService/Get"
+	QuotesService_List_FullMethodName   = "/quotes.v1.QuotesService/List"
+	QuotesService_Search_FullMethodName = "/quotes.v1.QuotesService/Search"
+	QuotesService_Daily_FullMethodName  = "/quotes.v1.QuotesService/Daily"
+)
+
+// QuotesServiceClient is the client API for QuotesService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type QuotesServiceClient interface {
+	Random(ctx context.Context, in *RandomRequest, opts ...grpc.CallOption) (*RandomResponse, error)
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Item, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Item], error)
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	Daily(ctx context.Context, in *DailyRequest, opts ...grpc.CallOption) (*DailyResponse, error)
+}
+
+type quotesServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewQuotesServiceClient(cc grpc.ClientConnInterface) QuotesServiceClient {
+	return &quotesServiceClient{cc}
+}
+
+func (c *quotesServiceClient) Random(ctx context.Context, in *RandomRequest, opts ...grpc.CallOption) (*RandomResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RandomResponse)
+	err := c.cc.Invoke(ctx, QuotesService_Random_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quotesServiceClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Item, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Item)
+	err := c.cc.Invoke(ctx, QuotesService_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quotesServiceClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Item], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &QuotesService_ServiceDesc.Streams[0], QuotesService_List_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListRequest, Item]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type QuotesService_ListClient = grpc.ServerStreamingClient[Item]
+
+func (c *quotesServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, QuotesService_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quotesServiceClient) Daily(ctx context.Context, in *DailyRequest, opts ...grpc.CallOption) (*DailyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DailyResponse)
+	err := c.cc.Invoke(ctx, QuotesService_Daily_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// QuotesServiceServer is the server API for QuotesService service.
+// All implementations must embed UnimplementedQuotesServiceServer
+// for forward compatibility.
+type QuotesServiceServer interface {
+	Random(context.Context, *RandomRequest) (*RandomResponse, error)
+	Get(context.Context, *GetRequest) (*Item, error)
+	List(*ListRequest, grpc.ServerStreamingServer[Item]) error
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	Daily(context.Context, *DailyRequest) (*DailyResponse, error)
+	mustEmbedUnimplementedQuotesServiceServer()
+}
+
+// UnimplementedQuotesServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedQuotesServiceServer struct{}
+
+func (UnimplementedQuotesServiceServer) Random(context.Context, *RandomRequest) (*RandomResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Random not implemented")
+}
+func (UnimplementedQuotesServiceServer) Get(context.Context, *GetRequest) (*Item, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedQuotesServiceServer) List(*ListRequest, grpc.ServerStreamingServer[Item]) error {
+	return status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedQuotesServiceServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedQuotesServiceServer) Daily(context.Context, *DailyRequest) (*DailyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Daily not implemented")
+}
+func (UnimplementedQuotesServiceServer) mustEmbedUnimplementedQuotesServiceServer() {}
+func (UnimplementedQuotesServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeQuotesServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to QuotesServiceServer will
+// result in compilation errors.
+type UnsafeQuotesServiceServer interface {
+	mustEmbedUnimplementedQuotesServiceServer()
+}
+
+func RegisterQuotesServiceServer(s grpc.ServiceRegistrar, srv QuotesServiceServer) {
+	// If the following call pancis, it indicates UnimplementedQuotesServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&QuotesService_ServiceDesc, srv)
+}
+
+func _QuotesService_Random_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RandomRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuotesServiceServer).Random(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuotesService_Random_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuotesServiceServer).Random(ctx, req.(*RandomRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuotesService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuotesServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuotesService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuotesServiceServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuotesService_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QuotesServiceServer).List(m, &grpc.GenericServerStream[ListRequest, Item]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type QuotesService_ListServer = grpc.ServerStreamingServer[Item]
+
+func _QuotesService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuotesServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuotesService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuotesServiceServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuotesService_Daily_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DailyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuotesServiceServer).Daily(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuotesService_Daily_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuotesServiceServer).Daily(ctx, req.(*DailyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// QuotesService_ServiceDesc is the grpc.ServiceDesc for QuotesService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var QuotesService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "quotes.v1.QuotesService",
+	HandlerType: (*QuotesServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Random",
+			Handler:    _QuotesService_Random_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _QuotesService_Get_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _QuotesService_Search_Handler,
+		},
+		{
+			MethodName: "Daily",
+			Handler:    _QuotesService_Daily_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _QuotesService_List_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "quotes/v1/quotes.proto",
+}
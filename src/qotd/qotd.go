@@ -0,0 +1,167 @@
+package qotd
+
+import (
+	"errors"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxLength is the longest message sent, in bytes. RFC 865 asks for
+	// quotes of less than 512 characters.
+	MaxLength = 511
+
+	// writeTimeout is how long a TCP client has to take its quote
+	writeTimeout = 10 * time.Second
+
+	// udpInterval is the shortest time between two UDP replies to one
+	// address. Replies are larger than requests and UDP sources can be
+	// spoofed, so this keeps the server from amplifying floods.
+	udpInterval = time.Second
+
+	// maxTracked is the number of UDP client addresses remembered
+	maxTracked = 10000
+)
+
+// Format fits a quote into a QOTD message: lines end with CRLF and text
+// past MaxLength is cut, at a character boundary, with "..."
+func Format(text string) string {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	msg := strings.ReplaceAll(text, "\n", "\r\n") + "\r\n"
+	if len(msg) <= MaxLength {
+		return msg
+	}
+
+	const ellipsis = "...\r\n"
+	cut := MaxLength - len(ellipsis)
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+	return strings.TrimRight(msg[:cut], " \t\r\n") + ellipsis
+}
+
+// Server answers Quote of the Day requests on a TCP and a UDP port
+type Server struct {
+	quote func() string
+	tcp   net.Listener
+	udp   net.PacketConn
+	wg    sync.WaitGroup
+	last  map[string]time.Time // Time of the last UDP reply to each address
+}
+
+// Listen listens on addr over TCP and UDP and sends each client the quote
+// returned by quote, until Close. With port 0 both get the same free port.
+func Listen(addr string, quote func() string) (*Server, error) {
+	tcp, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	host, _, _ := net.SplitHostPort(addr)
+	port := tcp.Addr().(*net.TCPAddr).Port
+	udp, err := net.ListenPacket("udp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		tcp.Close()
+		return nil, err
+	}
+
+	s := &Server{quote: quote, tcp: tcp, udp: udp, last: make(map[string]time.Time)}
+	s.wg.Add(2)
+	go s.serveTCP()
+	go s.serveUDP()
+	return s, nil
+}
+
+// TCPAddr returns the address of the TCP listener
+func (s *Server) TCPAddr() net.Addr {
+	return s.tcp.Addr()
+}
+
+// UDPAddr returns the address of the UDP listener
+func (s *Server) UDPAddr() net.Addr {
+	return s.udp.LocalAddr()
+}
+
+// Close stops both listeners and waits for them to finish
+func (s *Server) Close() error {
+	err := errors.Join(s.tcp.Close(), s.udp.Close())
+	s.wg.Wait()
+	return err
+}
+
+// serveTCP sends a quote to every connection, then closes it
+func (s *Server) serveTCP() {
+	defer s.wg.Done()
+	var delay time.Duration
+	for {
+		conn, err := s.tcp.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			// Out of file descriptors and the like: back off as net/http does
+			delay = min(max(2*delay, 5*time.Millisecond), time.Second)
+			log.Printf("⚠️  Warning: QOTD accept failed, retrying in %v: %v", delay, err)
+			time.Sleep(delay)
+			continue
+		}
+		delay = 0
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			conn.Write([]byte(Format(s.quote())))
+		}()
+	}
+}
+
+// serveUDP answers every datagram with a quote, ignoring its contents
+func (s *Server) serveUDP() {
+	defer s.wg.Done()
+	buf := make([]byte, 512)
+	for {
+		_, addr, err := s.udp.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			continue
+		}
+		if !s.allow(addr, time.Now()) {
+			continue
+		}
+		s.udp.WriteTo([]byte(Format(s.quote())), addr)
+	}
+}
+
+// allow reports whether a UDP client may get a reply now, at most one per
+// udpInterval for each host
+func (s *Server) allow(addr net.Addr, now time.Time) bool {
+	host := addr.String()
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		host = udpAddr.IP.String()
+	}
+	if last, ok := s.last[host]; ok && now.Sub(last) < udpInterval {
+		return false
+	}
+
+	if len(s.last) >= maxTracked {
+		for h, last := range s.last {
+			if now.Sub(last) >= udpInterval {
+				delete(s.last, h)
+			}
+		}
+		if len(s.last) >= maxTracked {
+			return false
+		}
+	}
+	s.last[host] = now
+	return true
+}
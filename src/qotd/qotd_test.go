@@ -0,0 +1,106 @@
+package qotd
+
+import (
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Hello", "Hello\r\n"},
+		{"One\nTwo\n", "One\r\nTwo\r\n"},
+		{"One\r\nTwo", "One\r\nTwo\r\n"},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.text); got != tt.want {
+			t.Errorf("Format(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFormatLimit(t *testing.T) {
+	for _, text := range []string{strings.Repeat("a", 600), strings.Repeat("é", 300), strings.Repeat("line\n", 200)} {
+		got := Format(text)
+		if len(got) > MaxLength {
+			t.Errorf("Format of %d bytes is %d bytes long", len(text), len(got))
+		}
+		if !strings.HasSuffix(got, "...\r\n") {
+			t.Errorf("Format of a long quote = %q, want an ellipsis", got[len(got)-10:])
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("Format cut a character in two")
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	s, err := Listen("127.0.0.1:0", func() string { return "Be yourself\n\t\t-- Oscar Wilde" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	want := "Be yourself\r\n\t\t-- Oscar Wilde\r\n"
+
+	conn, err := net.Dial("tcp", s.TCPAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	got, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("TCP quote = %q, want %q", got, want)
+	}
+
+	udp, err := net.Dial("udp", s.UDPAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+	udp.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := udp.Write([]byte("\n")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1024)
+	n, err := udp.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:n]) != want {
+		t.Errorf("UDP quote = %q, want %q", buf[:n], want)
+	}
+
+	// A second datagram within the interval gets no reply
+	udp.Write([]byte("\n"))
+	udp.SetDeadline(time.Now().Add(200 * time.Millisecond))
+	if n, err := udp.Read(buf); err == nil {
+		t.Errorf("unexpected second UDP reply %q", buf[:n])
+	}
+}
+
+func TestAllow(t *testing.T) {
+	s := &Server{last: make(map[string]time.Time)}
+	a := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1000}
+	b := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 2000}
+	now := time.Now()
+
+	if !s.allow(a, now) {
+		t.Error("first request refused")
+	}
+	if s.allow(b, now.Add(udpInterval/2)) {
+		t.Error("request from the same host within the interval allowed")
+	}
+	if !s.allow(b, now.Add(udpInterval)) {
+		t.Error("request after the interval refused")
+	}
+}
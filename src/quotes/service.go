@@ -0,0 +1,79 @@
+package quotes
+
+import (
+	"github.com/apimgr/quotes/src/collection"
+)
+
+// Quote represents a single general quote
+type Quote struct {
+	ID       int    `json:"id"`
+	Quote    string `json:"quote"`
+	Author   string `json:"author"`
+	Category string `json:"category"`
+}
+
+// GetID returns the quote ID
+func (q Quote) GetID() int {
+	return q.ID
+}
+
+// Field returns the value of a named quote field
+func (q Quote) Field(name string) string {
+	switch name {
+	case "quote":
+		return q.Quote
+	case "author":
+		return q.Author
+	case "category":
+		return q.Category
+	}
+	return ""
+}
+
+// Collection is the general quotes collection
+var Collection = collection.New[Quote](collection.Info{
+	Name:      "quotes",
+	Title:     "quotes",
+	ItemName:  "quote",
+	TextField: "quote",
+	Fields: []collection.Field{
+		{Name: "category", Path: "category"},
+		{Name: "author", Path: "author"},
+	},
+	Attribution: []string{"author"},
+})
+
+// LoadQuotes loads quotes from embedded JSON data
+func LoadQuotes(jsonData []byte) error {
+	return Collection.Load(jsonData)
+}
+
+// GetRandomQuote returns a random quote from the loaded quotes
+func GetRandomQuote() (*Quote, error) {
+	return Collection.Random()
+}
+
+// GetAllQuotes returns all loaded quotes
+func GetAllQuotes() []Quote {
+	return Collection.All()
+}
+
+// GetQuoteByID returns a quote by its ID
+func GetQuoteByID(id int) (*Quote, error) {
+	return Collection.ByID(id)
+}
+
+// GetQuotesByCategory returns all quotes in a specific category
+func GetQuotesByCategory(category string) []Quote {
+	return Collection.ByField("category", category)
+}
+
+// GetQuotesByAuthor returns all quotes by a specific author
+func GetQuotesByAuthor(author string) []Quote {
+	return Collection.ByField("author", author)
+}
+
+// GetTotalCount returns the total number of loaded quotes
+func GetTotalCount() int {
+	return Collection.Count()
+}
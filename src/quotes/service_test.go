@@ -0,0 +1,164 @@
+package quotes
+
+import (
+	"os"
+	"sync"
+	"testing"
+)
+
+// loadTestQuotes loads the embedded quotes dataset used by the server
+func loadTestQuotes(t testing.TB) {
+	t.Helper()
+
+	data, err := os.ReadFile("../data/quotes.json")
+	if err != nil {
+		t.Fatalf("failed to read quotes.json: %v", err)
+	}
+
+	if err := LoadQuotes(data); err != nil {
+		t.Fatalf("LoadQuotes failed: %v", err)
+	}
+}
+
+func TestLoadQuotes(t *testing.T) {
+	loadTestQuotes(t)
+
+	if GetTotalCount() == 0 {
+		t.Fatal("expected quotes to be loaded")
+	}
+
+	if got := len(GetAllQuotes()); got != GetTotalCount() {
+		t.Errorf("GetAllQuotes returned %d quotes, want %d", got, GetTotalCount())
+	}
+
+	for _, quote := range GetAllQuotes() {
+		if quote.ID == 0 || quote.Quote == "" || quote.Author == "" || quote.Category == "" {
+			t.Fatalf("quote has missing fields: %+v", quote)
+		}
+	}
+}
+
+func TestLoadQuotesInvalid(t *testing.T) {
+	if err := LoadQuotes([]byte("not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+
+	if err := LoadQuotes([]byte("[]")); err == nil {
+		t.Error("expected error for empty dataset")
+	}
+}
+
+func TestGetRandomQuote(t *testing.T) {
+	loadTestQuotes(t)
+
+	quote, err := GetRandomQuote()
+	if err != nil {
+		t.Fatalf("GetRandomQuote failed: %v", err)
+	}
+	if quote.Quote == "" {
+		t.Error("expected a non-empty quote")
+	}
+}
+
+func TestGetQuoteByID(t *testing.T) {
+	loadTestQuotes(t)
+
+	quote, err := GetQuoteByID(42)
+	if err != nil {
+		t.Fatalf("GetQuoteByID failed: %v", err)
+	}
+	if quote.ID != 42 {
+		t.Errorf("got quote ID %d, want 42", quote.ID)
+	}
+
+	if _, err := GetQuoteByID(-1); err == nil {
+		t.Error("expected error for unknown ID")
+	}
+}
+
+func TestGetQuotesByCategory(t *testing.T) {
+	loadTestQuotes(t)
+
+	result := GetQuotesByCategory("wisdom")
+	if len(result) == 0 {
+		t.Fatal("expected quotes in category wisdom")
+	}
+	for _, quote := range result {
+		if quote.Category != "wisdom" {
+			t.Errorf("quote %d has category %q, want wisdom", quote.ID, quote.Category)
+		}
+	}
+
+	if got := GetQuotesByCategory("no-such-category"); len(got) != 0 {
+		t.Errorf("expected no quotes, got %d", len(got))
+	}
+}
+
+func TestGetQuotesByAuthor(t *testing.T) {
+	loadTestQuotes(t)
+
+	author := GetAllQuotes()[0].Author
+	result := GetQuotesByAuthor(author)
+	if len(result) == 0 {
+		t.Fatalf("expected quotes by %s", author)
+	}
+	for _, quote := range result {
+		if quote.Author != author {
+			t.Errorf("quote %d has author %q, want %q", quote.ID, quote.Author, author)
+		}
+	}
+}
+
+func TestGetRandomQuoteConcurrent(t *testing.T) {
+	loadTestQuotes(t)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 64; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				quote, err := GetRandomQuote()
+				if err != nil {
+					t.Errorf("GetRandomQuote failed: %v", err)
+					return
+				}
+				if quote.ID == 0 {
+					t.Errorf("got invalid quote: %+v", quote)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkGetQuoteByID(b *testing.B) {
+	loadTestQuotes(b)
+	total := GetTotalCount()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetQuoteByID(i%total + 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetQuotesByCategory(b *testing.B) {
+	loadTestQuotes(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GetQuotesByCategory("wisdom")
+	}
+}
+
+func BenchmarkGetQuotesByAuthor(b *testing.B) {
+	loadTestQuotes(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GetQuotesByAuthor("author 42")
+	}
+}
@@ -0,0 +1,188 @@
+package rotation
+
+import (
+	"container/list"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// State is the position of one client in its rotation
+type State struct {
+	Seed     uint64 // Selects the permutation of the current cycle
+	Position int    // Number of items already served in the current cycle
+	Size     int    // Number of items the permutation covers
+	Cursor   int    // Index in the permutation of the next item that is not deferred
+	Deferred []int  // Items held back to the end of the cycle, in order
+	Last     []int  // Items of the cycle served by the latest request
+	Version  int64  // Orders saves of the rotation; later states have higher versions
+}
+
+// newCycle starts a shuffled cycle over n items. The items last served
+// from the previous cycle are deferred to its end, so a new cycle neither
+// repeats them within the request that crosses into it nor right after the
+// request that finished the previous one. When the latest request served
+// a whole cycle only its last item is deferred.
+func newCycle(n int, last []int) State {
+	deferred := last
+	if len(deferred) >= n {
+		deferred = deferred[len(deferred)-1:]
+	}
+	return State{
+		Seed:     rand.Uint64() >> 11,
+		Size:     n,
+		Deferred: append([]int(nil), deferred...),
+	}
+}
+
+// next serves the next item of the cycle: the permutation without the
+// deferred items, then the deferred items
+func (s *State) next(deferred map[int]bool) int {
+	var item int
+	if s.Position < s.Size-len(s.Deferred) {
+		for {
+			item = permute(s.Cursor, s.Size, s.Seed)
+			s.Cursor++
+			if !deferred[item] {
+				break
+			}
+		}
+	} else {
+		item = s.Deferred[s.Position-(s.Size-len(s.Deferred))]
+	}
+	s.Position++
+	s.Last = append(s.Last, item)
+	return item
+}
+
+// Store persists rotation state outside of memory
+type Store interface {
+	// LoadRotation returns the saved state for key, and false if there is none
+	LoadRotation(key string) (State, bool, error)
+
+	// SaveRotation saves the state for key unless a state with a higher
+	// version is already saved
+	SaveRotation(key string, state State) error
+}
+
+// entry is a cached rotation state
+type entry struct {
+	key   string
+	state State
+}
+
+// Manager tracks per-client rotations so each client walks a shuffled
+// permutation of a collection and sees every item before any repeats.
+// At most capacity rotations are kept in memory; the least recently used
+// ones are evicted and, when a Store is configured, reloaded from it.
+type Manager struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	lru      *list.List
+	store    Store
+	version  int64 // Version of the latest state
+}
+
+// NewManager creates a manager keeping up to capacity rotations in memory.
+// store may be nil to keep rotations in memory only.
+func NewManager(capacity int, store Store) *Manager {
+	return &Manager{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		store:    store,
+	}
+}
+
+// Next returns the positions of the next count items, out of n candidates,
+// in the rotation identified by key, and the state after serving them.
+// A new shuffled cycle starts whenever the previous one is exhausted or the
+// number of candidates changes. The positions returned are distinct, even
+// when they span two cycles.
+func (m *Manager) Next(key string, n, count int) ([]int, State) {
+	m.mutex.Lock()
+	state := m.load(key)
+	if state.Size != n {
+		state = newCycle(n, nil)
+	} else if state.Position < state.Size {
+		state.Last = nil
+	}
+
+	count = min(count, n)
+	result := make([]int, 0, count)
+	deferred := deferredSet(state.Deferred)
+	for len(result) < count {
+		if state.Position >= state.Size {
+			state = newCycle(n, state.Last)
+			deferred = deferredSet(state.Deferred)
+		}
+		result = append(result, state.next(deferred))
+	}
+
+	// Versions follow the clock so that they keep increasing across restarts
+	m.version = max(m.version+1, time.Now().UnixNano())
+	state.Version = m.version
+	m.cache(key, state)
+	m.mutex.Unlock()
+
+	// Persist outside the lock so slow writes do not block other clients;
+	// the version keeps a slow older write from overwriting a newer one
+	if m.store != nil {
+		if err := m.store.SaveRotation(key, state); err != nil {
+			log.Printf("Failed to save rotation %s: %v", key, err)
+		}
+	}
+	return result, state
+}
+
+// deferredSet returns the deferred items of a cycle as a set
+func deferredSet(items []int) map[int]bool {
+	set := make(map[int]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+// load returns the cached or stored state for key
+func (m *Manager) load(key string) State {
+	if elem, ok := m.entries[key]; ok {
+		m.lru.MoveToFront(elem)
+		return elem.Value.(*entry).state
+	}
+
+	if m.store != nil {
+		state, ok, err := m.store.LoadRotation(key)
+		if err != nil {
+			log.Printf("Failed to load rotation %s: %v", key, err)
+		} else if ok {
+			return state
+		}
+	}
+	return State{}
+}
+
+// cache stores the state for key in memory, evicting the least recently
+// used rotation when the cache is full
+func (m *Manager) cache(key string, state State) {
+	if elem, ok := m.entries[key]; ok {
+		elem.Value.(*entry).state = state
+		m.lru.MoveToFront(elem)
+	} else {
+		m.entries[key] = m.lru.PushFront(&entry{key: key, state: state})
+		for m.lru.Len() > m.capacity {
+			oldest := m.lru.Back()
+			m.lru.Remove(oldest)
+			delete(m.entries, oldest.Value.(*entry).key)
+		}
+	}
+}
+
+// Len returns the number of rotations held in memory
+func (m *Manager) Len() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.lru.Len()
+}
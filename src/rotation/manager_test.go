@@ -0,0 +1,207 @@
+package rotation
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// memoryStore is a Store kept in a map, which like the database keeps the
+// state with the highest version
+type memoryStore struct {
+	mu     sync.Mutex
+	states map[string]State
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{states: make(map[string]State)}
+}
+
+func (s *memoryStore) LoadRotation(key string) (State, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[key]
+	return state, ok, nil
+}
+
+func (s *memoryStore) SaveRotation(key string, state State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state.Version > s.states[key].Version {
+		s.states[key] = state
+	}
+	return nil
+}
+
+func TestPermute(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 7, 16, 17, 1000} {
+		seen := make(map[int]bool, n)
+		for i := 0; i < n; i++ {
+			p := permute(i, n, 12345)
+			if p < 0 || p >= n || seen[p] {
+				t.Fatalf("permute(%d, %d) = %d, repeated or out of range", i, n, p)
+			}
+			seen[p] = true
+		}
+	}
+}
+
+// walk serves count items at a time from a rotation over n items and checks
+// every cycle serves each item exactly once, with no item twice in a request
+// or twice in a row
+func walk(t *testing.T, m *Manager, key string, n, count, requests int) {
+	t.Helper()
+	cycle := make(map[int]bool)
+	last := -1
+	for r := 0; r < requests; r++ {
+		positions, state := m.Next(key, n, count)
+		if len(positions) != min(count, n) {
+			t.Fatalf("request %d: %d positions, want %d", r, len(positions), min(count, n))
+		}
+		request := make(map[int]bool)
+		for _, p := range positions {
+			if request[p] {
+				t.Fatalf("n=%d count=%d request %d: %v repeats %d", n, count, r, positions, p)
+			}
+			request[p] = true
+			if n > 1 && p == last {
+				t.Fatalf("n=%d count=%d request %d: %d served twice in a row", n, count, r, p)
+			}
+			last = p
+
+			if cycle[p] {
+				t.Fatalf("n=%d count=%d request %d: %d repeated before the cycle ended", n, count, r, p)
+			}
+			cycle[p] = true
+			if len(cycle) == n {
+				cycle = make(map[int]bool)
+			}
+		}
+		if state.Size != n || state.Position != len(cycle) && !(len(cycle) == 0 && state.Position == n) {
+			t.Fatalf("request %d: state %+v with %d items of the cycle served", r, state, len(cycle))
+		}
+	}
+}
+
+func TestNextCycles(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 10, 101} {
+		for _, count := range []int{1, 2, 3, 7, 100} {
+			m := NewManager(10, nil)
+			walk(t, m, fmt.Sprintf("%d/%d", n, count), n, count, 200)
+		}
+	}
+}
+
+func TestNextCycleBoundary(t *testing.T) {
+	// A new cycle never starts with the item that ended the previous one
+	for trial := 0; trial < 200; trial++ {
+		m := NewManager(10, nil)
+		first, _ := m.Next("k", 3, 3)
+		second, _ := m.Next("k", 3, 1)
+		if second[0] == first[2] {
+			t.Fatalf("trial %d: cycle %v followed by %d", trial, first, second[0])
+		}
+	}
+
+	// A request crossing into a new cycle gets distinct items, and the new
+	// cycle still serves every item once
+	for trial := 0; trial < 200; trial++ {
+		m := NewManager(10, nil)
+		m.Next("k", 5, 3)
+		crossing, state := m.Next("k", 5, 4)
+		seen := make(map[int]bool)
+		for _, p := range crossing {
+			if seen[p] {
+				t.Fatalf("trial %d: crossing request %v repeats %d", trial, crossing, p)
+			}
+			seen[p] = true
+		}
+		if state.Position != 2 {
+			t.Fatalf("trial %d: %+v after two items of the new cycle", trial, state)
+		}
+		rest, _ := m.Next("k", 5, 3)
+		cycle := map[int]bool{crossing[2]: true, crossing[3]: true}
+		for _, p := range rest {
+			if cycle[p] {
+				t.Fatalf("trial %d: new cycle %v then %v repeats %d", trial, crossing[2:], rest, p)
+			}
+			cycle[p] = true
+		}
+	}
+}
+
+func TestNextSizeChange(t *testing.T) {
+	m := NewManager(10, nil)
+	m.Next("k", 10, 4)
+	positions, state := m.Next("k", 6, 1)
+	if state.Size != 6 || state.Position != 1 || positions[0] >= 6 {
+		t.Errorf("after the candidates changed: %v, %+v", positions, state)
+	}
+}
+
+func TestNextRestore(t *testing.T) {
+	store := newMemoryStore()
+	before := NewManager(10, store)
+	var served []int
+	for i := 0; i < 4; i++ {
+		positions, _ := before.Next("client", 10, 1)
+		served = append(served, positions...)
+	}
+
+	// A restarted server continues the same cycle
+	after := NewManager(10, store)
+	positions, state := after.Next("client", 10, 6)
+	if state.Position != 10 {
+		t.Fatalf("restored rotation at %+v, want the cycle completed", state)
+	}
+	seen := make(map[int]bool)
+	for _, p := range append(served, positions...) {
+		if seen[p] {
+			t.Fatalf("restored rotation repeated %d: %v then %v", p, served, positions)
+		}
+		seen[p] = true
+	}
+
+	// and the next cycle does not start with the last item of this one
+	next, _ := NewManager(10, store).Next("client", 10, 1)
+	if next[0] == positions[len(positions)-1] {
+		t.Errorf("new cycle after restore starts with %d again", next[0])
+	}
+}
+
+func TestNextEviction(t *testing.T) {
+	store := newMemoryStore()
+	m := NewManager(2, store)
+	first, _ := m.Next("a", 100, 1)
+	m.Next("b", 100, 1)
+	m.Next("c", 100, 1)
+	if m.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", m.Len())
+	}
+
+	// The evicted rotation is reloaded from the store
+	_, state := m.Next("a", 100, 1)
+	if state.Position != 2 {
+		t.Errorf("evicted rotation resumed at %+v after serving %v", state, first)
+	}
+}
+
+func TestNextVersions(t *testing.T) {
+	store := newMemoryStore()
+	m := NewManager(10, store)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Next("k", 1000, 1)
+		}()
+	}
+	wg.Wait()
+
+	// However the saves interleave, the store ends with the latest state
+	saved, _, _ := store.LoadRotation("k")
+	if saved.Position != 50 {
+		t.Errorf("saved rotation at position %d after 50 requests", saved.Position)
+	}
+}
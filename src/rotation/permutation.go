@@ -0,0 +1,52 @@
+package rotation
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+)
+
+// feistelRounds is the number of rounds of the permutation network
+const feistelRounds = 4
+
+// permute maps i in [0, n) to its position in a pseudo-random permutation of
+// [0, n) selected by seed. A balanced Feistel network permutes the smallest
+// power-of-four domain covering n, and values outside [0, n) are cycled
+// through the network again until they land inside it. The permutation
+// needs no memory, so the state of a rotation over any number of items is
+// a few integers and the deferred and last served items, whose number is
+// bounded by the items served per request.
+func permute(i, n int, seed uint64) int {
+	if n <= 1 {
+		return 0
+	}
+
+	halfBits := 1
+	for 1<<(2*halfBits) < n {
+		halfBits++
+	}
+	mask := uint64(1)<<halfBits - 1
+
+	x := uint64(i)
+	for {
+		left, right := x>>halfBits, x&mask
+		for round := 0; round < feistelRounds; round++ {
+			left, right = right, left^(roundFunc(seed, round, right)&mask)
+		}
+		x = left<<halfBits | right
+		if x < uint64(n) {
+			return int(x)
+		}
+	}
+}
+
+// roundFunc is the keyed round function of the Feistel network
+func roundFunc(seed uint64, round int, value uint64) uint64 {
+	var buf [17]byte
+	binary.LittleEndian.PutUint64(buf[0:8], seed)
+	buf[8] = byte(round)
+	binary.LittleEndian.PutUint64(buf[9:17], value)
+
+	h := fnv.New64a()
+	h.Write(buf[:])
+	return h.Sum64()
+}
@@ -0,0 +1,288 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// bm25K1 controls term frequency saturation
+	bm25K1 = 1.2
+
+	// bm25B controls document length normalization
+	bm25B = 0.75
+
+	// fieldGap separates the positions of consecutive fields so phrases never span fields
+	fieldGap = 100
+
+	// maxPrefixExpansions limits how many index terms a prefix query expands to
+	maxPrefixExpansions = 64
+)
+
+// posting lists the positions of a term within one document
+type posting struct {
+	doc       int
+	positions []int
+}
+
+// Index is an in-memory inverted index with BM25 ranking
+type Index struct {
+	postings map[string][]posting
+	terms    []string
+	lengths  []int
+	avgLen   float64
+}
+
+// Result is a ranked search hit
+type Result struct {
+	Doc   int
+	Score float64
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{postings: make(map[string][]posting)}
+}
+
+// Add indexes a document made of one or more fields and returns its document number.
+// Documents are numbered in the order they are added, starting at zero.
+func (idx *Index) Add(fields ...string) int {
+	doc := len(idx.lengths)
+	positions := make(map[string][]int)
+	pos := 0
+	for _, field := range fields {
+		for _, tok := range Tokenize(field) {
+			positions[tok.Term] = append(positions[tok.Term], pos)
+			pos++
+		}
+		pos += fieldGap
+	}
+
+	length := 0
+	for term, p := range positions {
+		idx.postings[term] = append(idx.postings[term], posting{doc: doc, positions: p})
+		length += len(p)
+	}
+	idx.lengths = append(idx.lengths, length)
+	return doc
+}
+
+// Build finalizes the index after all documents have been added
+func (idx *Index) Build() {
+	idx.terms = make([]string, 0, len(idx.postings))
+	for term := range idx.postings {
+		idx.terms = append(idx.terms, term)
+	}
+	sort.Strings(idx.terms)
+
+	total := 0
+	for _, l := range idx.lengths {
+		total += l
+	}
+	if len(idx.lengths) > 0 {
+		idx.avgLen = float64(total) / float64(len(idx.lengths))
+	}
+}
+
+// clause is one part of a parsed query
+type clause struct {
+	terms  []string
+	prefix bool
+}
+
+// parseQuery splits a query into phrase clauses ("...") and term clauses.
+// A term ending in * matches every indexed term with that prefix.
+func parseQuery(query string) []clause {
+	var clauses []clause
+	parts := strings.Split(query, "\"")
+	for i, part := range parts {
+		if i%2 == 1 {
+			var terms []string
+			for _, tok := range Tokenize(part) {
+				terms = append(terms, tok.Term)
+			}
+			if len(terms) > 0 {
+				clauses = append(clauses, clause{terms: terms})
+			}
+			continue
+		}
+
+		for _, word := range strings.Fields(part) {
+			tokens := Tokenize(word)
+			for j, tok := range tokens {
+				prefix := j == len(tokens)-1 && strings.HasSuffix(word, "*")
+				clauses = append(clauses, clause{terms: []string{tok.Term}, prefix: prefix})
+			}
+		}
+	}
+	return clauses
+}
+
+// expand returns the index terms matched by a prefix
+func (idx *Index) expand(prefix string) []string {
+	var result []string
+	i := sort.SearchStrings(idx.terms, prefix)
+	for ; i < len(idx.terms) && strings.HasPrefix(idx.terms[i], prefix); i++ {
+		result = append(result, idx.terms[i])
+		if len(result) == maxPrefixExpansions {
+			break
+		}
+	}
+	return result
+}
+
+// idf returns the BM25 inverse document frequency of a term
+func (idx *Index) idf(df int) float64 {
+	n := float64(len(idx.lengths))
+	return math.Log(1 + (n-float64(df)+0.5)/(float64(df)+0.5))
+}
+
+// bm25 returns the BM25 score contribution of a term occurring tf times in doc
+func (idx *Index) bm25(idf float64, tf int, doc int) float64 {
+	norm := 1 - bm25B + bm25B*float64(idx.lengths[doc])/idx.avgLen
+	return idf * (float64(tf) * (bm25K1 + 1)) / (float64(tf) + bm25K1*norm)
+}
+
+// findPosting returns the posting for doc, or nil if the term does not occur in it
+func findPosting(postings []posting, doc int) *posting {
+	i := sort.Search(len(postings), func(i int) bool { return postings[i].doc >= doc })
+	if i < len(postings) && postings[i].doc == doc {
+		return &postings[i]
+	}
+	return nil
+}
+
+// phraseMatches returns how often each document contains the phrase terms consecutively
+func (idx *Index) phraseMatches(terms []string) map[int]int {
+	first := idx.postings[terms[0]]
+	matches := make(map[int]int)
+	for _, p := range first {
+		positions := make(map[int]bool, len(p.positions))
+		for _, pos := range p.positions {
+			positions[pos] = true
+		}
+
+		for offset, term := range terms[1:] {
+			next := make(map[int]bool)
+			if q := findPosting(idx.postings[term], p.doc); q != nil {
+				for _, pos := range q.positions {
+					if positions[pos-offset-1] {
+						next[pos-offset-1] = true
+					}
+				}
+			}
+			positions = next
+			if len(positions) == 0 {
+				break
+			}
+		}
+
+		if len(positions) > 0 {
+			matches[p.doc] = len(positions)
+		}
+	}
+	return matches
+}
+
+// Search returns documents matching query ordered by descending BM25 score,
+// and the index terms that matched so callers can highlight them.
+// Phrase clauses must all match; other terms only contribute to the score.
+// Scores are divided by the highest score the query can reach in this index,
+// so they fall between 0 and 1 and compare across indexes.
+func (idx *Index) Search(query string) ([]Result, []string) {
+	clauses := parseQuery(query)
+	if len(clauses) == 0 || len(idx.lengths) == 0 {
+		return nil, nil
+	}
+
+	// Drop stopword terms unless the query consists of nothing else
+	meaningful := clauses[:0:0]
+	for _, c := range clauses {
+		if len(c.terms) > 1 || c.prefix || !IsStopword(c.terms[0]) {
+			meaningful = append(meaningful, c)
+		}
+	}
+	if len(meaningful) > 0 {
+		clauses = meaningful
+	}
+
+	scores := make(map[int]float64)
+	var required map[int]bool
+	var matched []string
+	var best float64
+
+	for _, c := range clauses {
+		if len(c.terms) > 1 {
+			docs := idx.phraseMatches(c.terms)
+			phraseDocs := make(map[int]bool, len(docs))
+			for doc, tf := range docs {
+				if required == nil || required[doc] {
+					phraseDocs[doc] = true
+				}
+				for _, term := range c.terms {
+					scores[doc] += idx.bm25(idx.idf(len(idx.postings[term])), tf, doc)
+				}
+			}
+			for _, term := range c.terms {
+				best += idx.idf(len(idx.postings[term])) * (bm25K1 + 1)
+			}
+			required = phraseDocs
+			if len(docs) > 0 {
+				matched = append(matched, c.terms...)
+			}
+			continue
+		}
+
+		if !c.prefix {
+			postings := idx.postings[c.terms[0]]
+			idf := idx.idf(len(postings))
+			best += idf * (bm25K1 + 1)
+			if len(postings) > 0 {
+				matched = append(matched, c.terms[0])
+			}
+			for _, p := range postings {
+				scores[p.doc] += idx.bm25(idf, len(p.positions), p.doc)
+			}
+			continue
+		}
+
+		// A prefix scores each document by its best expansion, so matching
+		// several forms of a word counts once
+		terms := idx.expand(c.terms[0])
+		if len(terms) == 0 {
+			best += idx.idf(0) * (bm25K1 + 1)
+			continue
+		}
+		prefixScores := make(map[int]float64)
+		var bestIDF float64
+		for _, term := range terms {
+			postings := idx.postings[term]
+			matched = append(matched, term)
+			idf := idx.idf(len(postings))
+			bestIDF = math.Max(bestIDF, idf)
+			for _, p := range postings {
+				prefixScores[p.doc] = math.Max(prefixScores[p.doc], idx.bm25(idf, len(p.positions), p.doc))
+			}
+		}
+		best += bestIDF * (bm25K1 + 1)
+		for doc, score := range prefixScores {
+			scores[doc] += score
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for doc, score := range scores {
+		if required != nil && !required[doc] {
+			continue
+		}
+		results = append(results, Result{Doc: doc, Score: score / best})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Doc < results[j].Doc
+	})
+	return results, matched
+}
@@ -0,0 +1,155 @@
+package search
+
+import (
+	"math"
+	"testing"
+)
+
+// testIndex returns an index over docs, one field each
+func testIndex(docs ...string) *Index {
+	idx := NewIndex()
+	for _, doc := range docs {
+		idx.Add(doc)
+	}
+	idx.Build()
+	return idx
+}
+
+// docs returns the document numbers of results in order
+func docs(results []Result) []int {
+	var result []int
+	for _, r := range results {
+		result = append(result, r.Doc)
+	}
+	return result
+}
+
+func equalDocs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSearchBM25Ordering(t *testing.T) {
+	idx := testIndex(
+		"the cat sat on the mat while the dog slept by the door all day long",
+		"cat",
+		"cat cat cat",
+		"a dog",
+		"dog and cat",
+	)
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		// Repeated terms score higher, and short documents beat long ones
+		{"cat", []int{2, 1, 4, 0}},
+		// Documents with both terms beat those with one, and rarer terms weigh more
+		{"cat dog", []int{4, 3, 2, 0, 1}},
+		// Stopwords are dropped when the query has other terms
+		{"the cat", []int{2, 1, 4, 0}},
+		// but match when the query is nothing else
+		{"the", []int{0}},
+		{"bird", nil},
+	}
+	for _, tt := range tests {
+		results, _ := idx.Search(tt.query)
+		if got := docs(results); !equalDocs(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+		for _, r := range results {
+			if r.Score <= 0 || r.Score > 1 {
+				t.Errorf("Search(%q): doc %d scores %v, want a score in (0, 1]", tt.query, r.Doc, r.Score)
+			}
+		}
+	}
+}
+
+func TestSearchScoresCompareAcrossIndexes(t *testing.T) {
+	// The same document must score the same relative to the query whether
+	// the term is rare or common in the rest of its index
+	small := testIndex("perseverance pays", "other words")
+	large := testIndex("perseverance pays", "other words", "more words", "even more words", "words words")
+	a, _ := small.Search("perseverance")
+	b, _ := large.Search("perseverance")
+	if len(a) != 1 || len(b) != 1 {
+		t.Fatalf("got %v and %v, want one hit each", a, b)
+	}
+	if diff := math.Abs(a[0].Score - b[0].Score); diff > 0.05 {
+		t.Errorf("the same match scores %v and %v", a[0].Score, b[0].Score)
+	}
+
+	// A document matching half the query scores below one matching all of it
+	idx := testIndex("never give up", "never surrender")
+	results, _ := idx.Search("never give")
+	if len(results) != 2 || results[0].Doc != 0 || results[1].Score >= results[0].Score {
+		t.Errorf("Search(never give) = %v", results)
+	}
+}
+
+func TestSearchPhrase(t *testing.T) {
+	idx := NewIndex()
+	idx.Add("to be or not to be", "Shakespeare")
+	idx.Add("not to be confused with anything", "Anonymous")
+	idx.Add("or not", "be to")
+	idx.Add("be happy or not", "Anonymous")
+	idx.Build()
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{`"to be or not to be"`, []int{0}},
+		{`"not to be"`, []int{0, 1}},
+		// Phrases never span fields
+		{`"or not be to"`, nil},
+		{`"be or not"`, []int{0}},
+		// Every phrase must match, other terms only rank
+		{`"or not" happy`, []int{3, 2, 0}},
+		{`"or not" "not to"`, []int{0}},
+	}
+	for _, tt := range tests {
+		results, _ := idx.Search(tt.query)
+		if got := docs(results); !equalDocs(got, tt.want) {
+			t.Errorf("Search(%s) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchPrefix(t *testing.T) {
+	idx := testIndex("persevere", "perseverance wins", "persistence", "perseverance and persevering persevere")
+
+	results, terms := idx.Search("persever*")
+	if got := docs(results); len(got) != 3 || got[len(got)-1] == 2 {
+		t.Errorf("Search(persever*) = %v, want documents 0, 1 and 3", got)
+	}
+	want := map[string]bool{"persevere": true, "perseverance": true, "persevering": true}
+	if len(terms) != len(want) {
+		t.Errorf("Search(persever*) matched %v, want %v", terms, want)
+	}
+	for _, term := range terms {
+		if !want[term] {
+			t.Errorf("Search(persever*) matched %q", term)
+		}
+	}
+	for _, r := range results {
+		if r.Score > 1 {
+			t.Errorf("Search(persever*): doc %d scores %v above 1", r.Doc, r.Score)
+		}
+	}
+
+	// Without the star only the exact term matches
+	if results, _ := idx.Search("persever"); len(results) != 0 {
+		t.Errorf("Search(persever) = %v, want no hits", results)
+	}
+	if results, _ := idx.Search("zz*"); len(results) != 0 {
+		t.Errorf("Search(zz*) = %v, want no hits", results)
+	}
+}
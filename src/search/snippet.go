@@ -0,0 +1,95 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// highlightStart and highlightEnd wrap matched terms in snippets
+	highlightStart = "<mark>"
+	highlightEnd   = "</mark>"
+)
+
+// Snippet returns an HTML-escaped excerpt of text of at most maxLen bytes
+// around the first matched term, with every matched term wrapped in <mark> tags
+func Snippet(text string, terms []string, maxLen int) string {
+	matched := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		matched[t] = true
+	}
+
+	var hits []Token
+	for _, tok := range Tokenize(text) {
+		if matched[tok.Term] {
+			hits = append(hits, tok)
+		}
+	}
+
+	// Choose a window that starts shortly before the first hit
+	start, end := 0, len(text)
+	if maxLen > 0 && len(text) > maxLen {
+		if len(hits) > 0 {
+			start = hits[0].Start - maxLen/4
+			if start < 0 {
+				start = 0
+			}
+		}
+		end = start + maxLen
+		if end > len(text) {
+			end = len(text)
+			start = end - maxLen
+		}
+		start, end = runeBoundary(text, start), runeBoundary(text, end)
+		if s, e := wordBoundary(text, start, false), wordBoundary(text, end, true); s < e {
+			start, end = s, e
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, hit := range hits {
+		if hit.Start < start || hit.End > end {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:hit.Start]))
+		b.WriteString(highlightStart)
+		b.WriteString(html.EscapeString(text[hit.Start:hit.End]))
+		b.WriteString(highlightEnd)
+		pos = hit.End
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// runeBoundary moves i back to the start of the UTF-8 sequence it points into
+func runeBoundary(text string, i int) int {
+	for i > 0 && i < len(text) && !utf8.RuneStart(text[i]) {
+		i--
+	}
+	return i
+}
+
+// wordBoundary moves i to the nearest space so snippets do not cut words in half
+func wordBoundary(text string, i int, forward bool) int {
+	if i <= 0 || i >= len(text) {
+		return i
+	}
+	if forward {
+		if j := strings.LastIndexByte(text[:i], ' '); j > 0 {
+			return j
+		}
+		return i
+	}
+	if j := strings.IndexByte(text[i:], ' '); j >= 0 {
+		return i + j + 1
+	}
+	return i
+}
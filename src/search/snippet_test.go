@@ -0,0 +1,52 @@
+package search
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		text   string
+		terms  []string
+		maxLen int
+		want   string
+	}{
+		{"Stay hungry, stay foolish.", []string{"stay"}, 100, "<mark>Stay</mark> hungry, <mark>stay</mark> foolish."},
+		{"Fish & <chips>", []string{"chips"}, 0, "Fish &amp; &lt;<mark>chips</mark>&gt;"},
+		{"Crème brûlée", []string{"creme"}, 100, "<mark>Crème</mark> brûlée"},
+		{"one two three four five six seven eight nine ten", []string{"nine"}, 20, "…eight <mark>nine</mark> ten"},
+		{"no match here at all in this text", []string{"zebra"}, 12, "no match…"},
+	}
+	for _, tt := range tests {
+		got := Snippet(tt.text, tt.terms, tt.maxLen)
+		if got != tt.want {
+			t.Errorf("Snippet(%q, %v, %d) = %q, want %q", tt.text, tt.terms, tt.maxLen, got, tt.want)
+		}
+	}
+}
+
+func TestSnippetBounds(t *testing.T) {
+	texts := []string{
+		strings.Repeat("word ", 100) + "needle " + strings.Repeat("word ", 100),
+		strings.Repeat("ünïcödé ", 60) + "needle",
+		"needle " + strings.Repeat("x", 500),
+		strings.Repeat("日本語", 100) + " needle",
+	}
+	for _, text := range texts {
+		for _, maxLen := range []int{1, 10, 50, 200} {
+			got := Snippet(text, []string{"needle"}, maxLen)
+			if !utf8.ValidString(got) {
+				t.Errorf("Snippet(%.20q…, %d) = %q is not valid UTF-8", text, maxLen, got)
+			}
+			plain := strings.NewReplacer(highlightStart, "", highlightEnd, "", "…", "").Replace(got)
+			if len(plain) > maxLen {
+				t.Errorf("Snippet(%.20q…, %d) has %d bytes of text", text, maxLen, len(plain))
+			}
+			if maxLen >= 50 && !strings.Contains(got, highlightStart+"needle"+highlightEnd) {
+				t.Errorf("Snippet(%.20q…, %d) = %q lost the match", text, maxLen, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package search
+
+import (
+	"unicode"
+)
+
+// Token is a normalized term and its byte span in the original text
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// stopwords are common English words dropped from queries that have other
+// terms. They are still indexed so that phrases such as "to be or not to be"
+// and queries of nothing but stopwords match.
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "of": true, "on": true, "or": true,
+	"so": true, "that": true, "the": true, "their": true, "then": true,
+	"there": true, "these": true, "they": true, "this": true, "to": true,
+	"was": true, "will": true, "with": true, "about": true,
+}
+
+// Tokenize splits text into terms of letters and digits, folded with Fold
+func Tokenize(text string) []Token {
+	var tokens []Token
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, Token{Term: Fold(text[start:i]), Start: start, End: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, Token{Term: Fold(text[start:]), Start: start, End: len(text)})
+	}
+	return tokens
+}
+
+// IsStopword reports whether term is dropped from queries that have other terms
+func IsStopword(term string) bool {
+	return stopwords[term]
+}
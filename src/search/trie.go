@@ -0,0 +1,99 @@
+package search
+
+import (
+	"sort"
+	"strings"
+)
+
+// Entry is a value stored in a Trie with its weight
+type Entry struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// trieNode is a node of the prefix trie
+type trieNode struct {
+	children map[rune]*trieNode
+	entries  []int
+}
+
+// Trie answers prefix queries over a set of weighted values. Every word of a
+// value is indexed, so "ein" finds "Albert Einstein".
+type Trie struct {
+	root    *trieNode
+	entries []Entry
+}
+
+// NewTrie creates an empty trie
+func NewTrie() *Trie {
+	return &Trie{root: &trieNode{}}
+}
+
+// Insert adds a value with its weight to the trie
+func (t *Trie) Insert(value string, count int) {
+	id := len(t.entries)
+	t.entries = append(t.entries, Entry{Value: value, Count: count})
+
+	key := Normalize(value)
+	words := strings.Split(key, " ")
+	for i := range words {
+		t.insertKey(strings.Join(words[i:], " "), id)
+	}
+}
+
+// insertKey stores an entry ID under a normalized key
+func (t *Trie) insertKey(key string, id int) {
+	node := t.root
+	for _, r := range key {
+		if node.children == nil {
+			node.children = make(map[rune]*trieNode)
+		}
+		child, ok := node.children[r]
+		if !ok {
+			child = &trieNode{}
+			node.children[r] = child
+		}
+		node = child
+	}
+	node.entries = append(node.entries, id)
+}
+
+// Prefix returns up to max entries with a word starting with prefix,
+// ordered by descending count and then by value
+func (t *Trie) Prefix(prefix string, max int) []Entry {
+	node := t.root
+	for _, r := range Normalize(prefix) {
+		node = node.children[r]
+		if node == nil {
+			return []Entry{}
+		}
+	}
+
+	seen := make(map[int]bool)
+	var collect func(n *trieNode)
+	collect = func(n *trieNode) {
+		for _, id := range n.entries {
+			seen[id] = true
+		}
+		for _, child := range n.children {
+			collect(child)
+		}
+	}
+	collect(node)
+
+	result := make([]Entry, 0, len(seen))
+	for id := range seen {
+		result = append(result, t.entries[id])
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Value < result[j].Value
+	})
+
+	if max > 0 && len(result) > max {
+		result = result[:max]
+	}
+	return result
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apimgr/quotes/src/card"
+	"github.com/apimgr/quotes/src/collection"
+)
+
+// cardFormats maps card file extensions to their content types
+var cardFormats = map[string]string{
+	"svg": "image/svg+xml",
+	"png": "image/png",
+}
+
+// periodNouns names the span of each period in card footers
+var periodNouns = map[collection.Period]string{
+	collection.Hourly: "hour",
+	collection.Daily:  "day",
+	collection.Weekly: "week",
+}
+
+// cardSizeNames returns the names of every size preset
+func cardSizeNames() string {
+	names := make([]string, len(card.Sizes))
+	for i, s := range card.Sizes {
+		names[i] = s.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// cardThemeNames returns the names of every theme
+func cardThemeNames() string {
+	names := make([]string, len(card.Themes))
+	for i, t := range card.Themes {
+		names[i] = t.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// parseCardOptions reads the size and theme parameters
+func parseCardOptions(r *http.Request) (card.Size, card.Theme, error) {
+	q := r.URL.Query()
+
+	size := card.Sizes[0]
+	if v := q.Get("size"); v != "" {
+		var ok bool
+		if size, ok = card.SizeByName(strings.ToLower(v)); !ok {
+			return size, card.Theme{}, fmt.Errorf("unknown size %q, supported sizes are: %s", v, cardSizeNames())
+		}
+	}
+
+	theme := card.Themes[0]
+	if v := q.Get("theme"); v != "" {
+		var ok bool
+		if theme, ok = card.ThemeByName(strings.ToLower(v)); !ok {
+			return size, theme, fmt.Errorf("unknown theme %q, supported themes are: %s", v, cardThemeNames())
+		}
+	}
+
+	return size, theme, nil
+}
+
+// respondWithCard renders a card of an item as an SVG or PNG image
+func respondWithCard(w http.ResponseWriter, r *http.Request, info collection.Info, item collection.Item, footer, kind string) {
+	size, theme, err := parseCardOptions(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c := card.Card{
+		Text:        item.Field(info.TextField),
+		Attribution: info.AttributionOf(item),
+		Footer:      footer,
+		Size:        size,
+		Theme:       theme,
+	}
+
+	var data []byte
+	if kind == "png" {
+		if data, err = c.PNG(); err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	} else {
+		data = c.SVG()
+	}
+
+	w.Header().Set("Content-Type", cardFormats[kind])
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// handleCollectionCard returns the card of an item by ID
+func handleCollectionCard(c collection.Collection, kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		item, ok := itemFromPath(w, r, c)
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		respondWithCard(w, r, c.Info(), item, capitalize(c.Info().Title), kind)
+	}
+}
+
+// handleCollectionRandomCard returns the card of a random item, honouring
+// the filter, seed and weighted parameters of the random endpoint
+func (s *Server) handleCollectionRandomCard(c collection.Collection, kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		info := c.Info()
+		filter, err := parseFilter(r, info)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		seed, err := parseSeed(r)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var items []collection.Item
+		rng := collection.NewSeededRand(seed)
+		if s.isWeighted(r) {
+			items, err = s.weights.RandomItems(c, filter, 1, rng, !r.URL.Query().Has("seed"))
+		} else {
+			items, err = collection.RandomItems(c, filter, 1, rng)
+		}
+		if errors.Is(err, collection.ErrNoMatch) {
+			respondWithError(w, http.StatusNotFound, fmt.Sprintf("No %s match the filters", info.Title))
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Random-Seed", strconv.FormatUint(seed, 10))
+		respondWithCard(w, r, info, items[0], capitalize(info.Title), kind)
+	}
+}
+
+// handleCollectionPeriodicCard returns the card of the item of the current
+// (or requested) hour, day or week, cached until the period ends. This is
+// what README badges embed.
+func handleCollectionPeriodicCard(c collection.Collection, period collection.Period, kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t, err := parsePeriodTime(r)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		item, err := collection.PeriodicItem(c, period, t)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		start, end := period.Bounds(t)
+		setPeriodCacheHeaders(w, start, end)
+
+		info := c.Info()
+		footer := fmt.Sprintf("%s of the %s · %s", capitalize(info.ItemName), periodNouns[period], period.Key(t))
+		respondWithCard(w, r, info, item, footer, kind)
+	}
+}
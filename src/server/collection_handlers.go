@@ -0,0 +1,179 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/go-chi/chi/v5"
+)
+
+// maxSuggestions is the number of "did you mean" suggestions returned with a 404
+const maxSuggestions = 5
+
+// CollectionMetadata describes a registered collection
+type CollectionMetadata struct {
+	collection.Info
+	Count int `json:"count"`
+}
+
+// mountCollection registers the full route set for a collection
+func (s *Server) mountCollection(r chi.Router, c collection.Collection) {
+	name := c.Info().Name
+
+	r.Get("/"+name+"/{id:[0-9]+}/rating", s.handleCollectionRating(c))
+	r.With(s.rateLimitMiddleware("rating")).Post("/"+name+"/{id:[0-9]+}/rating", s.handleCollectionRate(c))
+	r.Get("/"+name+"/count", handleCollectionCount(c))
+	r.Get("/"+name+"/metadata", handleCollectionMetadata(c))
+	r.Get("/"+name+"/facets", handleCollectionFacets(c))
+	r.Get("/"+name+".fortune", handleCollectionFortune(c))
+	r.Get("/"+name+".fortune.dat", handleCollectionFortuneIndex(c))
+
+	for kind := range cardFormats {
+		r.Get("/"+name+"/{id:[0-9]+}/card."+kind, handleCollectionCard(c, kind))
+		r.Get("/"+name+"/random/card."+kind, s.handleCollectionRandomCard(c, kind))
+		for _, period := range collection.Periods {
+			r.Get("/"+name+"/"+string(period)+"/card."+kind, handleCollectionPeriodicCard(c, period, kind))
+		}
+	}
+
+	// Content endpoints negotiate their output format
+	negotiated := r.With(formatMiddleware)
+	negotiated.Get("/"+name, handleCollectionAll(c))
+	negotiated.Get("/"+name+"/random", s.handleCollectionRandom(c))
+	negotiated.Get("/"+name+"/{id:[0-9]+}", handleCollectionByID(c))
+
+	for _, period := range collection.Periods {
+		negotiated.Get("/"+name+"/"+string(period), handleCollectionPeriodic(c, period))
+	}
+	negotiated.Get("/"+name+"/search", handleCollectionSearch(c))
+	negotiated.Get("/"+name+"/suggest", handleCollectionSuggest(c))
+	negotiated.Get("/"+name+"/facets/{field}", handleCollectionFieldFacets(c))
+
+	for _, field := range c.Info().Fields {
+		negotiated.Get("/"+name+"/"+field.Path+"/{value}", handleCollectionByField(c, field))
+	}
+}
+
+// collectionMetadata builds the metadata for a collection
+func collectionMetadata(c collection.Collection) CollectionMetadata {
+	return CollectionMetadata{
+		Info:  c.Info(),
+		Count: c.Count(),
+	}
+}
+
+// pathParam returns a decoded URL parameter. chi matches against the raw path
+// when the request path has a non-canonical encoding, so values may still be escaped.
+func pathParam(r *http.Request, name string) string {
+	value := chi.URLParam(r, name)
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		return unescaped
+	}
+	return value
+}
+
+// itemFromPath returns the item named by the id URL parameter, responding
+// with an error and false when there is none
+func itemFromPath(w http.ResponseWriter, r *http.Request, c collection.Collection) (collection.Item, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s ID", c.Info().ItemName))
+		return nil, false
+	}
+
+	item, err := c.ItemByID(id)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return nil, false
+	}
+	return item, true
+}
+
+// handleCollections returns metadata for all registered collections
+func handleCollections(w http.ResponseWriter, r *http.Request) {
+	all := collection.All()
+	result := make([]CollectionMetadata, 0, len(all))
+	for _, c := range all {
+		result = append(result, collectionMetadata(c))
+	}
+
+	respondWithJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+// handleCollectionAll returns a page of all items of a collection
+func handleCollectionAll(c collection.Collection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondWithList(w, r, c, c.Items())
+	}
+}
+
+// handleCollectionByID returns a collection item by ID
+func handleCollectionByID(c collection.Collection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		item, ok := itemFromPath(w, r, c)
+		if !ok {
+			return
+		}
+
+		respondWithContent(w, r, APIResponse{
+			Success: true,
+			Data:    item,
+		}, func() contentBody {
+			return singleItemContent(c.Info(), item)
+		})
+	}
+}
+
+// handleCollectionByField returns a page of collection items matching a field value
+func handleCollectionByField(c collection.Collection, field collection.Field) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		value := pathParam(r, "value")
+
+		items := c.ItemsByField(field.Name, value)
+		if len(items) == 0 {
+			message := fmt.Sprintf("No %s found for this %s", c.Info().Title, field.Name)
+			suggestions := c.SimilarValues(field.Name, value, maxSuggestions)
+			if len(suggestions) > 0 {
+				message += fmt.Sprintf(", did you mean %q?", suggestions[0])
+			}
+
+			respondWithJSON(w, http.StatusNotFound, APIResponse{
+				Success:     false,
+				Error:       message,
+				Suggestions: suggestions,
+			})
+			return
+		}
+
+		respondWithList(w, r, c, items)
+	}
+}
+
+// handleCollectionCount returns the number of items in a collection
+func handleCollectionCount(c collection.Collection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondWithJSON(w, http.StatusOK, APIResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"collection": c.Info().Name,
+				"count":      c.Count(),
+			},
+		})
+	}
+}
+
+// handleCollectionMetadata returns the metadata of a collection
+func handleCollectionMetadata(c collection.Collection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondWithJSON(w, http.StatusOK, APIResponse{
+			Success: true,
+			Data:    collectionMetadata(c),
+		})
+	}
+}
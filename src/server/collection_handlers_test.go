@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFieldFilterMatching(t *testing.T) {
+	for _, path := range []string{
+		"/api/v1/anime/show/Dragon%20Ball%20Z",
+		"/api/v1/anime/show/dragon-ball-z",
+		"/api/v1/anime/show/DRAGON_BALL_Z",
+		"/api/v1/quotes/category/Courage",
+	} {
+		var items []map[string]interface{}
+		resp := decodeResponse(t, get(t, path+"?limit=5"), &items)
+		if !resp.Success || len(items) == 0 {
+			t.Errorf("GET %s: %+v", path, resp)
+		}
+	}
+}
+
+func TestFieldFilterDidYouMean(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v1/anime/show/naruot", "Naruto"},
+		{"/api/v1/anime/show/one-pice", "One Piece"},
+		{"/api/v1/quotes/category/wisdm", "wisdom"},
+		{"/api/v1/quotes/category/lead", "leadership"},
+	}
+	for _, tt := range tests {
+		w := get(t, tt.path)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", tt.path, w.Code)
+			continue
+		}
+		resp := decodeResponse(t, w, nil)
+		if resp.Success || len(resp.Suggestions) == 0 || resp.Suggestions[0] != tt.want {
+			t.Errorf("GET %s: suggestions %v, want %q first", tt.path, resp.Suggestions, tt.want)
+		}
+		if len(resp.Suggestions) > maxSuggestions {
+			t.Errorf("GET %s: %d suggestions, want at most %d", tt.path, len(resp.Suggestions), maxSuggestions)
+		}
+		if !strings.Contains(resp.Error, `did you mean "`+tt.want+`"?`) {
+			t.Errorf("GET %s: error %q", tt.path, resp.Error)
+		}
+	}
+
+	// Nothing close gets a plain 404
+	w := get(t, "/api/v1/quotes/category/xyzzyplughfoo")
+	resp := decodeResponse(t, w, nil)
+	if w.Code != http.StatusNotFound || len(resp.Suggestions) != 0 || strings.Contains(resp.Error, "did you mean") {
+		t.Errorf("unrelated value: status %d, %+v", w.Code, resp)
+	}
+}
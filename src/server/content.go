@@ -0,0 +1,297 @@
+package server
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apimgr/quotes/src/collection"
+)
+
+// ndjsonFlushEvery is the number of NDJSON lines written between flushes
+const ndjsonFlushEvery = 100
+
+// recordField is a named value of a record: a string, int or float64
+type recordField struct {
+	name  string
+	value interface{}
+}
+
+// record is one item of a non-JSON response: a CSV row, an XML element,
+// a YAML mapping or an NDJSON line, with its own plain text rendering
+type record struct {
+	fields []recordField
+	text   string
+}
+
+// contentBody is the format-independent body of a content response
+type contentBody struct {
+	records []record
+	single  bool // Whether the body is one record rather than a list
+}
+
+// itemText returns the plain text of an item followed by its attribution
+func itemText(info collection.Info, item collection.Item) string {
+	text := item.Field(info.TextField)
+	if attribution := info.AttributionOf(item); attribution != "" {
+		text += "\n  — " + attribution
+	}
+	return text
+}
+
+// itemRecord returns the record of an item, restricted to the selected
+// fields when any are given
+func itemRecord(info collection.Info, item collection.Item, fields []string) record {
+	if len(fields) == 0 {
+		fields = info.FieldNames()
+	}
+
+	rec := record{text: itemText(info, item)}
+	for _, name := range fields {
+		if name == "id" {
+			rec.fields = append(rec.fields, recordField{"id", item.GetID()})
+		} else {
+			rec.fields = append(rec.fields, recordField{name, item.Field(name)})
+		}
+	}
+	return rec
+}
+
+// itemContent returns the content of a list of items
+func itemContent(info collection.Info, items []collection.Item, fields []string) contentBody {
+	records := make([]record, len(items))
+	for i, item := range items {
+		records[i] = itemRecord(info, item, fields)
+	}
+	return contentBody{records: records}
+}
+
+// singleItemContent returns the content of a single item
+func singleItemContent(info collection.Info, item collection.Item) contentBody {
+	return contentBody{records: []record{itemRecord(info, item, nil)}, single: true}
+}
+
+// facetContent returns the content of a list of facets
+func facetContent(facets []collection.Facet) contentBody {
+	records := make([]record, len(facets))
+	for i, facet := range facets {
+		records[i] = record{
+			fields: []recordField{{"value", facet.Value}, {"count", facet.Count}},
+			text:   facet.Value + "\t" + strconv.Itoa(facet.Count),
+		}
+	}
+	return contentBody{records: records}
+}
+
+// respondWithContent sends a successful content response in the negotiated
+// format. JSON responses carry the full envelope; other formats render the
+// records built by body, with pagination moved to headers.
+func respondWithContent(w http.ResponseWriter, r *http.Request, resp APIResponse, body func() contentBody) {
+	f := requestFormat(r)
+	if f == formatJSON {
+		respondWithJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	setPaginationHeaders(w, resp.Pagination)
+	c := body()
+
+	w.Header().Set("Content-Type", f.contentType)
+	w.WriteHeader(http.StatusOK)
+
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	switch f {
+	case formatText:
+		writeText(bw, c)
+	case formatCSV:
+		writeCSV(bw, c)
+	case formatXML:
+		writeXML(bw, c)
+	case formatYAML:
+		writeYAML(bw, c)
+	case formatNDJSON:
+		writeNDJSON(bw, w, c)
+	}
+}
+
+// setPaginationHeaders reports list pagination through X-Total-Count and
+// Link headers for formats without an envelope
+func setPaginationHeaders(w http.ResponseWriter, p *Pagination) {
+	if p == nil {
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(p.Total))
+	var links []string
+	if p.Next != "" {
+		links = append(links, fmt.Sprintf("<%s>; rel=\"next\"", p.Next))
+	}
+	if p.Prev != "" {
+		links = append(links, fmt.Sprintf("<%s>; rel=\"prev\"", p.Prev))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// writeText writes the plain text of each record on its own line, with
+// blank lines between records when any of them spans several lines
+func writeText(w *bufio.Writer, c contentBody) {
+	multiline := false
+	for _, rec := range c.records {
+		multiline = multiline || strings.Contains(rec.text, "\n")
+	}
+
+	for i, rec := range c.records {
+		if i > 0 && multiline {
+			w.WriteString("\n")
+		}
+		w.WriteString(rec.text)
+		w.WriteString("\n")
+	}
+}
+
+// columns returns the field names of all records in order of first appearance
+func (c contentBody) columns() []string {
+	var columns []string
+	seen := make(map[string]bool)
+	for _, rec := range c.records {
+		for _, field := range rec.fields {
+			if !seen[field.name] {
+				seen[field.name] = true
+				columns = append(columns, field.name)
+			}
+		}
+	}
+	return columns
+}
+
+// formatValue returns the text of a record value
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// writeCSV writes a header row followed by one row per record
+func writeCSV(w *bufio.Writer, c contentBody) {
+	columns := c.columns()
+	cw := csv.NewWriter(w)
+	cw.Write(columns)
+
+	for _, rec := range c.records {
+		values := make(map[string]string, len(rec.fields))
+		for _, field := range rec.fields {
+			values[field.name] = formatValue(field.value)
+		}
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = values[column]
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+}
+
+// writeXMLRecord writes a record as an item element with one child per field
+func writeXMLRecord(w *bufio.Writer, rec record, indent string) {
+	w.WriteString(indent + "<item>\n")
+	for _, field := range rec.fields {
+		fmt.Fprintf(w, "%s  <%s>", indent, field.name)
+		xml.EscapeText(w, []byte(formatValue(field.value)))
+		fmt.Fprintf(w, "</%s>\n", field.name)
+	}
+	w.WriteString(indent + "</item>\n")
+}
+
+// writeXML writes a single item element, or an items element holding a list
+func writeXML(w *bufio.Writer, c contentBody) {
+	w.WriteString(xml.Header)
+	if c.single && len(c.records) == 1 {
+		writeXMLRecord(w, c.records[0], "")
+		return
+	}
+
+	w.WriteString("<items>\n")
+	for _, rec := range c.records {
+		writeXMLRecord(w, rec, "  ")
+	}
+	w.WriteString("</items>\n")
+}
+
+// yamlValue returns a record value as a YAML scalar. Strings are always
+// double-quoted; Go's escapes are a subset of YAML's.
+func yamlValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return formatValue(value)
+}
+
+// writeYAML writes a single mapping, or a sequence of mappings for a list
+func writeYAML(w *bufio.Writer, c contentBody) {
+	if c.single && len(c.records) == 1 {
+		for _, field := range c.records[0].fields {
+			fmt.Fprintf(w, "%s: %s\n", field.name, yamlValue(field.value))
+		}
+		return
+	}
+
+	if len(c.records) == 0 {
+		w.WriteString("[]\n")
+		return
+	}
+	for _, rec := range c.records {
+		for i, field := range rec.fields {
+			prefix := "  "
+			if i == 0 {
+				prefix = "- "
+			}
+			fmt.Fprintf(w, "%s%s: %s\n", prefix, field.name, yamlValue(field.value))
+		}
+	}
+}
+
+// recordJSON encodes a record as a JSON object, keeping the field order
+func recordJSON(rec record) []byte {
+	buf := []byte{'{'}
+	for i, field := range rec.fields {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		name, _ := json.Marshal(field.name)
+		value, _ := json.Marshal(field.value)
+		buf = append(buf, name...)
+		buf = append(buf, ':')
+		buf = append(buf, value...)
+	}
+	return append(buf, '}')
+}
+
+// writeNDJSON writes one JSON object per line, flushing periodically so
+// clients can process long exports as they arrive
+func writeNDJSON(w *bufio.Writer, rw http.ResponseWriter, c contentBody) {
+	flusher, _ := rw.(http.Flusher)
+	for i, rec := range c.records {
+		w.Write(recordJSON(rec))
+		w.WriteByte('\n')
+
+		if flusher != nil && (i+1)%ndjsonFlushEvery == 0 {
+			w.Flush()
+			flusher.Flush()
+		}
+	}
+}
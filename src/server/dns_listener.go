@@ -0,0 +1,100 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/apimgr/quotes/src/dns"
+)
+
+const (
+	// dnsRandomTTL is how long resolvers may cache a random item, short so
+	// that each lookup soon gets a new one
+	dnsRandomTTL = 1
+
+	// dnsItemTTL is how long resolvers may cache an item by ID, which
+	// admins may edit
+	dnsItemTTL = 300
+)
+
+// dnsText returns an item as the text of a TXT record: on one line, with
+// its attribution
+func dnsText(info collection.Info, item collection.Item) string {
+	text := strings.Join(strings.Fields(item.Field(info.TextField)), " ")
+	if attribution := info.AttributionOf(item); attribution != "" {
+		text += " -- " + attribution
+	}
+	return text
+}
+
+// dnsAnswer returns the TXT record of a name below the DNS zone:
+// random.{collection}, daily.{collection} (also hourly and weekly) or
+// {id}.{collection}
+func (s *Server) dnsAnswer(labels []string) (dns.Answer, bool) {
+	switch len(labels) {
+	case 0:
+		return dns.Answer{}, true
+	case 1:
+		// Collections have no records of their own, only names below them
+		_, ok := collection.Get(labels[0])
+		return dns.Answer{}, ok
+	case 2:
+	default:
+		return dns.Answer{}, false
+	}
+
+	c, ok := collection.Get(labels[1])
+	if !ok {
+		return dns.Answer{}, false
+	}
+	var item collection.Item
+	var ttl uint32
+	var err error
+	if period := collection.Period(labels[0]); slices.Contains(collection.Periods, period) {
+		now := time.Now().UTC()
+		item, err = collection.PeriodicItem(c, period, now)
+		// Cached until the period ends, as the periodic HTTP responses are
+		_, end := period.Bounds(now)
+		ttl = uint32(math.Ceil(end.Sub(now).Seconds()))
+	} else if labels[0] == "random" {
+		item, err = s.randomItem(c)
+		ttl = dnsRandomTTL
+	} else {
+		id, convErr := strconv.Atoi(labels[0])
+		if convErr != nil {
+			return dns.Answer{}, false
+		}
+		if item, err = c.ItemByID(id); err != nil {
+			return dns.Answer{}, false
+		}
+		ttl = dnsItemTTL
+	}
+	if err != nil {
+		// An empty collection: the name exists, without records
+		return dns.Answer{}, true
+	}
+	return dns.Answer{Texts: []string{dnsText(c.Info(), item)}, TTL: ttl}, true
+}
+
+// startDNS answers TXT queries for the dns.zone setting on the DNS port,
+// over UDP and TCP
+func (s *Server) startDNS() error {
+	s.settingsMutex.RLock()
+	zone := s.settingsCache["dns.zone"].(string)
+	s.settingsMutex.RUnlock()
+
+	addr := net.JoinHostPort(s.address, s.ports.DNS)
+	var err error
+	if s.dns, err = dns.Listen(addr, zone, s.dnsAnswer); err != nil {
+		return fmt.Errorf("failed to listen for DNS on %s: %w", addr, err)
+	}
+	log.Printf("DNS listening on %s, UDP and TCP, for TXT records of %s", addr, strings.TrimSuffix(zone, "."))
+	return nil
+}
@@ -0,0 +1,292 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"image/color"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/apimgr/quotes/src/card"
+	"github.com/apimgr/quotes/src/collection"
+)
+
+const (
+	// minEmbedRefresh and maxEmbedRefresh bound the widget refresh interval in seconds
+	minEmbedRefresh = 10
+	maxEmbedRefresh = 86400
+
+	// defaultEmbedWidth and defaultEmbedHeight size oEmbed iframes
+	defaultEmbedWidth  = 560
+	defaultEmbedHeight = 220
+
+	// oembedCacheAge is how long consumers may cache oEmbed responses, in seconds
+	oembedCacheAge = 86400
+)
+
+// EmbedConfig is the configuration the widget page script reads
+type EmbedConfig struct {
+	Collection  string   `json:"collection"`
+	TextField   string   `json:"text_field"`
+	Attribution []string `json:"attribution"`
+	Category    string   `json:"category,omitempty"`
+	Refresh     int      `json:"refresh"`
+}
+
+// embedOptions are the query parameters of the widget page
+type embedOptions struct {
+	theme    card.Theme
+	category string
+	refresh  int
+}
+
+// parseEmbedOptions reads the theme, category and refresh parameters
+func parseEmbedOptions(r *http.Request) (embedOptions, error) {
+	q := r.URL.Query()
+	opts := embedOptions{theme: card.Themes[0], category: q.Get("category")}
+
+	if v := q.Get("theme"); v != "" {
+		var ok bool
+		if opts.theme, ok = card.ThemeByName(strings.ToLower(v)); !ok {
+			return opts, fmt.Errorf("unknown theme %q, supported themes are: %s", v, cardThemeNames())
+		}
+	}
+
+	if v := q.Get("refresh"); v != "" {
+		var err error
+		opts.refresh, err = strconv.Atoi(v)
+		if err != nil || opts.refresh != 0 && (opts.refresh < minEmbedRefresh || opts.refresh > maxEmbedRefresh) {
+			return opts, fmt.Errorf("refresh must be 0 or between %d and %d seconds", minEmbedRefresh, maxEmbedRefresh)
+		}
+	}
+
+	return opts, nil
+}
+
+// cssColor returns a color in CSS hex notation
+func cssColor(c color.RGBA) template.CSS {
+	return template.CSS(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+}
+
+// handleEmbed renders the widget page framed by the embed snippet and oEmbed
+// iframes. Without an ID it shows a random item, optionally of one category,
+// and refreshes it every refresh seconds; with an ID it shows that item.
+func handleEmbed(c collection.Collection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		info := c.Info()
+		opts, err := parseEmbedOptions(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var item collection.Item
+		if pathParam(r, "id") != "" {
+			id, _ := strconv.Atoi(pathParam(r, "id"))
+			if item, err = c.ItemByID(id); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			opts.refresh = 0
+		} else {
+			var filter collection.Filter
+			if opts.category != "" {
+				filter.Fields = map[string]string{"category": opts.category}
+			}
+			items, err := collection.RandomItems(c, filter, 1, nil)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("No %s match the category", info.Title), http.StatusNotFound)
+				return
+			}
+			item = items[0]
+			w.Header().Set("Cache-Control", "no-store")
+		}
+
+		tmpl, err := template.ParseFS(content, "templates/embed.html")
+		if err != nil {
+			http.Error(w, "Error loading template", http.StatusInternalServerError)
+			return
+		}
+
+		permalink := fmt.Sprintf("%s/api/v1/%s/%d", baseURL(r), info.Name, item.GetID())
+		data := map[string]interface{}{
+			"Title":       capitalize(info.ItemName),
+			"Text":        item.Field(info.TextField),
+			"Attribution": info.AttributionOf(item),
+			"Permalink":   permalink,
+			"OEmbedURL":   baseURL(r) + "/oembed?url=" + url.QueryEscape(baseURL(r)+r.URL.RequestURI()),
+			"Background":  cssColor(opts.theme.Background),
+			"Foreground":  cssColor(opts.theme.Text),
+			"Accent":      cssColor(opts.theme.Accent),
+			"Config": EmbedConfig{
+				Collection:  info.Name,
+				TextField:   info.TextField,
+				Attribution: info.Attribution,
+				Category:    opts.category,
+				Refresh:     opts.refresh,
+			},
+		}
+
+		if err := tmpl.ExecuteTemplate(w, "embed", data); err != nil {
+			http.Error(w, "Error rendering template", http.StatusInternalServerError)
+		}
+	}
+}
+
+// handleWidgetScript serves the embed snippet, which replaces its own
+// script tag with a widget iframe
+func handleWidgetScript(w http.ResponseWriter, r *http.Request) {
+	data, err := content.ReadFile("static/js/widget.js")
+	if err != nil {
+		http.Error(w, "Widget script not found", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// OEmbedResponse is a rich oEmbed response, see https://oembed.com
+type OEmbedResponse struct {
+	XMLName         xml.Name `json:"-" xml:"oembed"`
+	Type            string   `json:"type" xml:"type"`
+	Version         string   `json:"version" xml:"version"`
+	Title           string   `json:"title,omitempty" xml:"title,omitempty"`
+	AuthorName      string   `json:"author_name,omitempty" xml:"author_name,omitempty"`
+	ProviderName    string   `json:"provider_name" xml:"provider_name"`
+	ProviderURL     string   `json:"provider_url" xml:"provider_url"`
+	CacheAge        int      `json:"cache_age" xml:"cache_age"`
+	HTML            string   `json:"html" xml:"html"`
+	Width           int      `json:"width" xml:"width"`
+	Height          int      `json:"height" xml:"height"`
+	ThumbnailURL    string   `json:"thumbnail_url,omitempty" xml:"thumbnail_url,omitempty"`
+	ThumbnailWidth  int      `json:"thumbnail_width,omitempty" xml:"thumbnail_width,omitempty"`
+	ThumbnailHeight int      `json:"thumbnail_height,omitempty" xml:"thumbnail_height,omitempty"`
+}
+
+// embedTarget resolves a permalink to the collection and item it shows.
+// Item API URLs and widget pages are recognized; item is nil for a random
+// widget.
+func embedTarget(r *http.Request, permalink string) (collection.Collection, collection.Item, *url.URL, bool) {
+	u, err := url.Parse(permalink)
+	if err != nil || u.Host != r.Host || u.Scheme != "http" && u.Scheme != "https" {
+		return nil, nil, nil, false
+	}
+
+	path := strings.TrimPrefix(u.Path, "/api/v1")
+	path = strings.TrimPrefix(path, "/embed")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	c, ok := collection.Get(parts[0])
+	if !ok {
+		return nil, nil, nil, false
+	}
+	switch {
+	case len(parts) == 1 && strings.HasPrefix(u.Path, "/embed/"):
+		return c, nil, u, true
+	case len(parts) == 2:
+		id, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, nil, nil, false
+		}
+		item, err := c.ItemByID(id)
+		return c, item, u, err == nil
+	}
+	return nil, nil, nil, false
+}
+
+// parseMaxDimension reads an optional maxwidth or maxheight parameter
+func parseMaxDimension(r *http.Request, name string) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return n, nil
+}
+
+// handleOEmbed is the oEmbed endpoint, letting CMSes turn permalinks to
+// items and widget pages into an embedded widget iframe
+func handleOEmbed(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	kind := q.Get("format")
+	if kind == "" {
+		kind = "json"
+	}
+	if kind != "json" && kind != "xml" {
+		respondWithError(w, http.StatusNotImplemented, "format must be json or xml")
+		return
+	}
+
+	c, item, u, ok := embedTarget(r, q.Get("url"))
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "url is not an item or widget of this server")
+		return
+	}
+
+	maxWidth, err := parseMaxDimension(r, "maxwidth")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	maxHeight, err := parseMaxDimension(r, "maxheight")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	info := c.Info()
+	resp := OEmbedResponse{
+		Type:         "rich",
+		Version:      "1.0",
+		Title:        capitalize(info.Title),
+		ProviderName: "Quotes API",
+		ProviderURL:  baseURL(r) + "/",
+		CacheAge:     oembedCacheAge,
+		Width:        defaultEmbedWidth,
+		Height:       defaultEmbedHeight,
+	}
+	if maxWidth > 0 {
+		resp.Width = min(resp.Width, maxWidth)
+	}
+	if maxHeight > 0 {
+		resp.Height = min(resp.Height, maxHeight)
+	}
+
+	src := fmt.Sprintf("%s/embed/%s", baseURL(r), info.Name)
+	if item != nil {
+		src += "/" + strconv.Itoa(item.GetID())
+		resp.Title = summarize(item.Field(info.TextField), feedTitleLength)
+		resp.AuthorName = info.AttributionOf(item)
+
+		// The card thumbnail is only offered when it fits the requested bounds
+		thumb := card.Sizes[0]
+		if (maxWidth == 0 || thumb.Width <= maxWidth) && (maxHeight == 0 || thumb.Height <= maxHeight) {
+			resp.ThumbnailURL = fmt.Sprintf("%s/api/v1/%s/%d/card.png", baseURL(r), info.Name, item.GetID())
+			resp.ThumbnailWidth = thumb.Width
+			resp.ThumbnailHeight = thumb.Height
+		}
+	}
+	if u.RawQuery != "" {
+		src += "?" + u.RawQuery
+	}
+	resp.HTML = fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" style="border:0" loading="lazy" title="%s"></iframe>`,
+		template.HTMLEscapeString(src), resp.Width, resp.Height, template.HTMLEscapeString(resp.Title))
+
+	if kind == "xml" {
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(xml.Header))
+		xml.NewEncoder(w).Encode(resp)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
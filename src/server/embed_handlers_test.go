@@ -0,0 +1,80 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestEmbedEscaping(t *testing.T) {
+	const category = `</script><script>alert(1)</script>`
+	body := fmt.Sprintf(`{"joke": "<img src=x onerror=alert(1)> & \"friends\"", "category": %q}`, category)
+	w := serve(t, http.MethodPost, "/api/v1/admin/items/programming", body, adminHeader())
+	var item struct {
+		ID int `json:"id"`
+	}
+	if decodeResponse(t, w, &item); w.Code != http.StatusCreated {
+		t.Fatalf("adding an item: status %d: %s", w.Code, w.Body)
+	}
+
+	for _, target := range []string{
+		fmt.Sprintf("/embed/programming/%d", item.ID),
+		"/embed/programming?category=" + strings.ReplaceAll(category, "/", "%2F"),
+	} {
+		w := get(t, target)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d: %s", target, w.Code, w.Body)
+		}
+		page := w.Body.String()
+		if !strings.Contains(page, "&lt;img src=x onerror=alert(1)&gt; &amp; &#34;friends&#34;") {
+			t.Errorf("GET %s: item text is not escaped", target)
+		}
+		if strings.Contains(page, "<img") || strings.Contains(page, "<script>alert") {
+			t.Errorf("GET %s: markup from the item reached the page", target)
+		}
+		if strings.Count(page, "</script>") != 1 {
+			t.Errorf("GET %s: the category closes the script element", target)
+		}
+	}
+
+	if w := get(t, "/embed/programming?theme=nope"); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown theme: status %d, want 400", w.Code)
+	}
+	if w := get(t, "/embed/programming?refresh=5"); w.Code != http.StatusBadRequest {
+		t.Errorf("refresh below the minimum: status %d, want 400", w.Code)
+	}
+}
+
+func TestEmbedFraming(t *testing.T) {
+	w := get(t, "/embed/quotes/1")
+	if w.Header().Get("X-Frame-Options") != "" {
+		t.Errorf("embed page sets X-Frame-Options %q", w.Header().Get("X-Frame-Options"))
+	}
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.HasSuffix(csp, "; frame-ancestors *") {
+		t.Errorf("embed page policy %q does not allow framing", csp)
+	}
+
+	testServer.settingsMutex.Lock()
+	testServer.settingsCache["embed.frame_ancestors"] = "https://example.com https://*.example.org"
+	testServer.settingsMutex.Unlock()
+	defer func() {
+		testServer.settingsMutex.Lock()
+		testServer.settingsCache["embed.frame_ancestors"] = "*"
+		testServer.settingsMutex.Unlock()
+	}()
+
+	w = get(t, "/embed/quotes")
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.HasSuffix(csp, "; frame-ancestors https://example.com https://*.example.org") {
+		t.Errorf("embed page policy %q ignores the setting", csp)
+	}
+	if strings.Count(w.Header().Get("Content-Security-Policy"), "frame-ancestors") != 1 {
+		t.Errorf("embed page policy %q has several frame-ancestors", w.Header().Get("Content-Security-Policy"))
+	}
+
+	// Other pages still refuse to be framed
+	w = get(t, "/api/v1/quotes/1")
+	if w.Header().Get("X-Frame-Options") != "DENY" || strings.Contains(w.Header().Get("Content-Security-Policy"), "frame-ancestors") {
+		t.Errorf("API response headers %v allow framing", w.Header())
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apimgr/quotes/src/collection"
+)
+
+const (
+	// defaultSuggestLimit is the number of suggestions returned when no limit is given
+	defaultSuggestLimit = 10
+
+	// maxSuggestLimit is the largest number of suggestions a client may request
+	maxSuggestLimit = 100
+)
+
+// lookupField resolves a field name or route path of a collection
+func lookupField(c collection.Collection, name string) (collection.Field, error) {
+	field, ok := c.Info().FieldByName(name)
+	if !ok {
+		var names []string
+		for _, f := range c.Info().Fields {
+			names = append(names, f.Name)
+		}
+		return field, fmt.Errorf("unknown field %q, valid fields are: %s", name, strings.Join(names, ", "))
+	}
+	return field, nil
+}
+
+// handleCollectionSuggest returns field values starting with the q parameter
+func handleCollectionSuggest(c collection.Collection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		name := q.Get("field")
+		if name == "" {
+			name = "category"
+		}
+		field, err := lookupField(c, name)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		limit := defaultSuggestLimit
+		if v := q.Get("limit"); v != "" {
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit < 1 {
+				respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+				return
+			}
+			if limit > maxSuggestLimit {
+				limit = maxSuggestLimit
+			}
+		}
+
+		suggestions := c.Suggest(field.Name, q.Get("q"), limit)
+		respondWithContent(w, r, APIResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"field":       field.Name,
+				"query":       q.Get("q"),
+				"suggestions": suggestions,
+			},
+		}, func() contentBody {
+			return facetContent(suggestions)
+		})
+	}
+}
+
+// handleCollectionFacets returns the distinct values and counts of every field
+func handleCollectionFacets(c collection.Collection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		facets := make(map[string][]collection.Facet)
+		for _, field := range c.Info().Fields {
+			facets[field.Name] = c.Facets(field.Name)
+		}
+
+		respondWithJSON(w, http.StatusOK, APIResponse{
+			Success: true,
+			Data:    facets,
+		})
+	}
+}
+
+// handleCollectionFieldFacets returns the distinct values and counts of one field
+func handleCollectionFieldFacets(c collection.Collection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		field, err := lookupField(c, pathParam(r, "field"))
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		facets := c.Facets(field.Name)
+		respondWithContent(w, r, APIResponse{
+			Success: true,
+			Data:    facets,
+		}, func() contentBody {
+			return facetContent(facets)
+		})
+	}
+}
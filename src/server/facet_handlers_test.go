@@ -0,0 +1,101 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/apimgr/quotes/src/anime"
+)
+
+// suggestData is the data of a suggest response
+type suggestData struct {
+	Field       string `json:"field"`
+	Query       string `json:"query"`
+	Suggestions []struct {
+		Value string `json:"value"`
+		Count int    `json:"count"`
+	} `json:"suggestions"`
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		target string
+		field  string
+		want   []string
+	}{
+		{"/api/v1/anime/suggest?field=show&q=nar", "anime", []string{"Naruto"}},
+		// Any word of a value matches, and route paths name fields
+		{"/api/v1/anime/suggest?field=anime&q=pie", "anime", []string{"One Piece"}},
+		{"/api/v1/anime/suggest?field=show&q=ACAD", "anime", []string{"My Hero Academia"}},
+		// The default field is the category
+		{"/api/v1/quotes/suggest?q=lea", "category", []string{"leadership"}},
+		{"/api/v1/quotes/suggest?q=zzz", "category", nil},
+	}
+	for _, tt := range tests {
+		var data suggestData
+		resp := decodeResponse(t, get(t, tt.target), &data)
+		if !resp.Success || data.Field != tt.field {
+			t.Errorf("GET %s: %+v, field %q", tt.target, resp, data.Field)
+			continue
+		}
+		var got []string
+		for _, s := range data.Suggestions {
+			got = append(got, s.Value)
+			if s.Count == 0 {
+				t.Errorf("GET %s: %q has no count", tt.target, s.Value)
+			}
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("GET %s = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestLimit(t *testing.T) {
+	var data suggestData
+	decodeResponse(t, get(t, "/api/v1/quotes/suggest?field=author&q=author&limit=3"), &data)
+	if len(data.Suggestions) != 3 {
+		t.Errorf("limit=3: %d suggestions", len(data.Suggestions))
+	}
+	decodeResponse(t, get(t, "/api/v1/quotes/suggest?field=author&q=author&limit=1000"), &data)
+	if len(data.Suggestions) != maxSuggestLimit {
+		t.Errorf("limit=1000: %d suggestions, want %d", len(data.Suggestions), maxSuggestLimit)
+	}
+	decodeResponse(t, get(t, "/api/v1/anime/suggest?field=character&q=char"), &data)
+	if len(data.Suggestions) != defaultSuggestLimit {
+		t.Errorf("no limit: %d suggestions, want %d", len(data.Suggestions), defaultSuggestLimit)
+	}
+
+	for _, target := range []string{
+		"/api/v1/quotes/suggest?limit=0",
+		"/api/v1/quotes/suggest?limit=many",
+		"/api/v1/quotes/suggest?field=show",
+	} {
+		if w := get(t, target); w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s = %d, want 400", target, w.Code)
+		}
+	}
+}
+
+func TestFacets(t *testing.T) {
+	var facets []struct {
+		Value string `json:"value"`
+		Count int    `json:"count"`
+	}
+	decodeResponse(t, get(t, "/api/v1/anime/facets/show"), &facets)
+	total := 0
+	for i, f := range facets {
+		total += f.Count
+		if i > 0 && f.Count > facets[i-1].Count {
+			t.Errorf("facet %q (%d) after %q (%d)", f.Value, f.Count, facets[i-1].Value, facets[i-1].Count)
+		}
+	}
+	if len(facets) != 5 || total != anime.Collection.Count() {
+		t.Errorf("anime facets %v add up to %d", facets, total)
+	}
+
+	if w := get(t, "/api/v1/anime/facets/nope"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown field: status %d, want 404", w.Code)
+	}
+}
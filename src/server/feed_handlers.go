@@ -0,0 +1,202 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/apimgr/quotes/src/feed"
+)
+
+const (
+	// defaultFeedLimit is the number of latest items listed when no limit is given
+	defaultFeedLimit = 20
+
+	// maxFeedLimit is the largest number of latest items a feed may list
+	maxFeedLimit = 100
+
+	// feedTitleLength is the length in characters entry titles are cut to
+	feedTitleLength = 80
+)
+
+// feedFormats maps feed file extensions to their content types and writers
+var feedFormats = map[string]struct {
+	contentType string
+	write       func(f *feed.Feed, w io.Writer) error
+}{
+	"rss":  {"application/rss+xml; charset=utf-8", func(f *feed.Feed, w io.Writer) error { return f.WriteRSS(w) }},
+	"atom": {"application/atom+xml; charset=utf-8", func(f *feed.Feed, w io.Writer) error { return f.WriteAtom(w) }},
+	"json": {"application/feed+json; charset=utf-8", func(f *feed.Feed, w io.Writer) error { return f.WriteJSON(w) }},
+}
+
+// datedItem is an item with the times it was added and last changed
+type datedItem struct {
+	item  collection.Item
+	times collection.Times
+}
+
+// changed returns when the item was last added or changed
+func (d datedItem) changed() time.Time {
+	if d.times.Updated.After(d.times.Created) {
+		return d.times.Updated
+	}
+	return d.times.Created
+}
+
+// baseURL returns the scheme and host the request was made to
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// capitalize returns s with its first letter in upper case
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return strings.ToUpper(string(r)) + s[size:]
+}
+
+// summarize returns the first line of text, cut to max characters
+func summarize(text string, max int) string {
+	text, _, _ = strings.Cut(text, "\n")
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
+
+// feedEntry builds the feed entry of an item
+func feedEntry(r *http.Request, info collection.Info, item collection.Item) feed.Entry {
+	entry := feed.Entry{
+		ID:      feed.GUID(info.Name, item.GetID()),
+		Title:   summarize(item.Field(info.TextField), feedTitleLength),
+		URL:     fmt.Sprintf("%s/api/v1/%s/%d", baseURL(r), info.Name, item.GetID()),
+		Content: itemText(info, item),
+		Author:  info.AttributionOf(item),
+		Item:    item,
+	}
+	if category := item.Field("category"); category != "" {
+		entry.Categories = []string{category}
+	}
+	return entry
+}
+
+// handleCollectionFeed returns an RSS, Atom or JSON feed of a collection,
+// listing the item of the day followed by the latest items. Field filters
+// in the query string restrict both. Caches must revalidate the feed,
+// whose ETag and Last-Modified change with the day and the collection.
+func handleCollectionFeed(c collection.Collection, kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		info := c.Info()
+		filter, err := parseFilter(r, info)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		limit := defaultFeedLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit < 1 {
+				respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+				return
+			}
+			limit = min(limit, maxFeedLimit)
+		}
+
+		t, err := parsePeriodTime(r)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		daily, err := collection.PeriodicMatch(c, filter, collection.Daily, t)
+		if errors.Is(err, collection.ErrNoMatch) {
+			respondWithError(w, http.StatusNotFound, fmt.Sprintf("No %s match the filters", info.Title))
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		// The latest items were added or changed last. Items of the embedded
+		// datasets have no times, and are appended to them, so the highest
+		// IDs are the latest.
+		matches := collection.FilterItems(c, filter)
+		latest := make([]datedItem, len(matches))
+		for i, item := range matches {
+			latest[i] = datedItem{item: item, times: c.Times(item.GetID())}
+		}
+		sort.Slice(latest, func(i, j int) bool {
+			if a, b := latest[i].changed(), latest[j].changed(); !a.Equal(b) {
+				return a.After(b)
+			}
+			return latest[i].item.GetID() > latest[j].item.GetID()
+		})
+		latest = latest[:min(limit, len(latest))]
+
+		start, end := collection.Daily.Bounds(t)
+		f := &feed.Feed{
+			Title:       capitalize(info.Title),
+			Description: fmt.Sprintf("The %s of the day and the latest %s", info.ItemName, info.Title),
+			HomeURL:     baseURL(r) + "/",
+			FeedURL:     baseURL(r) + r.URL.RequestURI(),
+			Updated:     start,
+		}
+		if len(filter.Fields) > 0 {
+			var parts []string
+			for _, field := range info.Fields {
+				if value, ok := filter.Fields[field.Name]; ok {
+					parts = append(parts, field.Name+": "+value)
+				}
+			}
+			f.Title += " (" + strings.Join(parts, ", ") + ")"
+		}
+
+		entry := feedEntry(r, info, daily)
+		entry.ID += "/daily/" + collection.Daily.Key(t)
+		entry.Title = capitalize(info.ItemName) + " of the day: " + entry.Title
+		entry.Published = start
+		f.Entries = append(f.Entries, entry)
+
+		// Items of the embedded datasets have no known dates, so their entries
+		// leave them out; items added or edited by an admin carry their own
+		for _, d := range latest {
+			entry := feedEntry(r, info, d.item)
+			entry.Published = d.times.Created
+			entry.Updated = d.times.Updated
+			f.Entries = append(f.Entries, entry)
+			if d.times.Updated.After(f.Updated) && d.times.Updated.Before(end) {
+				f.Updated = d.times.Updated
+			}
+		}
+
+		var body bytes.Buffer
+		format := feedFormats[kind]
+		if err := format.write(f, &body); err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		modified := start
+		if loaded := c.LoadedAt(); loaded.After(modified) {
+			modified = loaded
+		}
+		w.Header().Set("Content-Type", format.contentType)
+		w.Header().Set("Cache-Control", "public, no-cache")
+		w.Header().Set("ETag", fmt.Sprintf(`"%s-%x"`, collection.Daily.Key(t), c.LoadedAt().UnixNano()))
+		http.ServeContent(w, r, "", modified, bytes.NewReader(body.Bytes()))
+	}
+}
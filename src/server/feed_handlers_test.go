@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apimgr/quotes/src/chucknorris"
+	"github.com/apimgr/quotes/src/database"
+)
+
+func TestFeedItemTimes(t *testing.T) {
+	w := serve(t, http.MethodPost, "/api/v1/admin/items/chucknorris", `{"joke": "Chuck Norris can time travel to his own feeds.", "category": "power"}`, adminHeader())
+	var added struct {
+		ID int `json:"id"`
+	}
+	if decodeResponse(t, w, &added); w.Code != http.StatusCreated {
+		t.Fatalf("adding an item: status %d: %s", w.Code, w.Body)
+	}
+	times := chucknorris.Collection.Times(added.ID)
+	if times.Created.IsZero() || !times.Updated.Equal(times.Created) {
+		t.Fatalf("added item times %+v", times)
+	}
+
+	// The database keeps the times for restarts
+	edits, err := database.GetItemEdits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, edit := range edits {
+		if edit.Collection == "chucknorris" && edit.ID == added.ID {
+			found = true
+			if !edit.CreatedAt.Equal(times.Created) || !edit.UpdatedAt.Equal(times.Updated) {
+				t.Errorf("saved times %v, %v, want %+v", edit.CreatedAt, edit.UpdatedAt, times)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("item %d was not saved", added.ID)
+	}
+
+	w = get(t, "/feeds/chucknorris.json?limit=3")
+	var f struct {
+		Items []struct {
+			ID            string `json:"id"`
+			DatePublished string `json:"date_published"`
+			DateModified  string `json:"date_modified"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &f); err != nil || len(f.Items) != 4 {
+		t.Fatalf("feed %s: %v", w.Body, err)
+	}
+
+	// The item of the day is published at the start of the day
+	day, err := time.Parse(time.RFC3339, f.Items[0].DatePublished)
+	if err != nil || !day.Equal(day.Truncate(24*time.Hour)) {
+		t.Errorf("item of the day published %q", f.Items[0].DatePublished)
+	}
+
+	latest := f.Items[1]
+	if latest.ID != fmt.Sprintf("tag:github.com,2025:apimgr/quotes/chucknorris/%d", added.ID) {
+		t.Fatalf("latest entry %q, want the added item", latest.ID)
+	}
+	if latest.DatePublished != times.Created.UTC().Format(time.RFC3339) || latest.DateModified != latest.DatePublished {
+		t.Errorf("added item dated %q and %q, want %v", latest.DatePublished, latest.DateModified, times.Created)
+	}
+
+	// Dataset items have no known dates
+	for _, entry := range f.Items[2:] {
+		if entry.DatePublished != "" || entry.DateModified != "" {
+			t.Errorf("dataset item %s dated %q and %q", entry.ID, entry.DatePublished, entry.DateModified)
+		}
+	}
+}
+
+func TestFeedOrderAndRevalidation(t *testing.T) {
+	w := get(t, "/feeds/dadjokes.json?limit=2")
+	etag := w.Header().Get("ETag")
+	if got := w.Header().Get("Cache-Control"); got != "public, no-cache" || etag == "" {
+		t.Fatalf("Cache-Control = %q, ETag = %q", got, etag)
+	}
+	w = serve(t, http.MethodGet, "/feeds/dadjokes.json?limit=2", "", http.Header{"If-None-Match": {etag}})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("revalidating an unchanged feed: status %d", w.Code)
+	}
+
+	// Editing the first joke makes it the latest and changes the feed
+	w = serve(t, http.MethodPut, "/api/v1/admin/items/dadjokes/1", `{"joke": "I used to hate feeds, but they grew on me.", "category": "general"}`, adminHeader())
+	if w.Code != http.StatusOK {
+		t.Fatalf("editing an item: status %d: %s", w.Code, w.Body)
+	}
+	w = serve(t, http.MethodGet, "/feeds/dadjokes.json?limit=2", "", http.Header{"If-None-Match": {etag}})
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Fatalf("revalidating a changed feed: status %d, ETag %q", w.Code, w.Header().Get("ETag"))
+	}
+	var f struct {
+		Items []struct {
+			ID string `json:"id"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &f); err != nil || len(f.Items) != 3 {
+		t.Fatalf("feed %s: %v", w.Body, err)
+	}
+	if want := "tag:github.com,2025:apimgr/quotes/dadjokes/1"; f.Items[1].ID != want {
+		t.Errorf("latest entry %q, want the edited joke %q", f.Items[1].ID, want)
+	}
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/apimgr/quotes/src/finger"
+	"github.com/apimgr/quotes/src/fortune"
+	"github.com/apimgr/quotes/src/lineserver"
+)
+
+// fingerName returns the Finger user of a collection: its item name in
+// lower case without spaces, as in "dadjoke"
+func fingerName(info collection.Info) string {
+	return strings.ToLower(strings.ReplaceAll(info.ItemName, " ", ""))
+}
+
+// fingerUser returns the collection a Finger user stands for: its Finger
+// name, or its collection name, as in "dadjokes"
+func fingerUser(user string) (collection.Collection, bool) {
+	user = strings.ToLower(user)
+	for _, c := range collection.All() {
+		info := c.Info()
+		if user == info.Name || user == fingerName(info) {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// startFinger serves random items on the Finger port
+func (s *Server) startFinger() error {
+	addr := net.JoinHostPort(s.address, s.ports.Finger)
+	var err error
+	if s.finger, err = lineserver.Listen(addr, s.serveFinger); err != nil {
+		return fmt.Errorf("failed to listen for Finger on %s: %w", addr, err)
+	}
+	log.Printf("Finger listening on %s", addr)
+	return nil
+}
+
+// serveFinger answers a Finger query: "quote" gets a random quote,
+// "dadjoke" a dad joke and so on, and no user the list of users. /W adds
+// the fields of the item.
+func (s *Server) serveFinger(w io.Writer, line string) {
+	q := finger.ParseQuery(line)
+	if q.Host != "" {
+		finger.WriteReply(w, "Finger forwarding is not supported.")
+		return
+	}
+
+	if q.User == "" {
+		var b strings.Builder
+		b.WriteString("Finger a user for a random item:\n\n")
+		for _, c := range collection.All() {
+			info := c.Info()
+			fmt.Fprintf(&b, "  %-20s a random %s\n", fingerName(info), info.ItemName)
+		}
+		finger.WriteReply(w, b.String())
+		return
+	}
+
+	c, ok := fingerUser(q.User)
+	if !ok {
+		finger.WriteReply(w, fmt.Sprintf("finger: %s: no such user.", q.User))
+		return
+	}
+	info := c.Info()
+	item, err := s.randomItem(c)
+	if err != nil {
+		finger.WriteReply(w, fmt.Sprintf("No %s available", info.Title))
+		return
+	}
+
+	reply := fortune.Entry(info, item)
+	if q.Verbose {
+		reply = itemDocument(info, item)
+	}
+	finger.WriteReply(w, reply)
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// format is an output format content endpoints can respond in
+type format struct {
+	name        string   // Value of the format parameter, e.g. "csv"
+	contentType string   // Content-Type of responses
+	mediaTypes  []string // Accept media types selecting the format
+	aliases     []string // Other accepted format parameter values
+}
+
+var (
+	formatJSON   = &format{"json", "application/json", []string{"application/json"}, nil}
+	formatText   = &format{"text", "text/plain; charset=utf-8", []string{"text/plain"}, []string{"txt", "plain"}}
+	formatCSV    = &format{"csv", "text/csv; charset=utf-8", []string{"text/csv"}, nil}
+	formatXML    = &format{"xml", "application/xml; charset=utf-8", []string{"application/xml", "text/xml"}, nil}
+	formatYAML   = &format{"yaml", "application/yaml; charset=utf-8", []string{"application/yaml", "application/x-yaml", "text/yaml"}, []string{"yml"}}
+	formatNDJSON = &format{"ndjson", "application/x-ndjson", []string{"application/x-ndjson", "application/ndjson"}, []string{"jsonl"}}
+)
+
+// formats lists the supported formats
+var formats = []*format{formatJSON, formatText, formatCSV, formatXML, formatYAML, formatNDJSON}
+
+// formatNames returns the format parameter values of every format
+func formatNames() string {
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = f.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// formatByName returns the format for a format parameter value
+func formatByName(name string) (*format, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, f := range formats {
+		if f.name == name {
+			return f, true
+		}
+		for _, alias := range f.aliases {
+			if alias == name {
+				return f, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// formatForMediaType returns the format with exactly the given media type
+func formatForMediaType(mediaType string) (*format, bool) {
+	for _, f := range formats {
+		for _, candidate := range f.mediaTypes {
+			if candidate == mediaType {
+				return f, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// negotiateFormat picks the response format from the format parameter, which
+// takes precedence, or else the Accept header. JSON is the default: another
+// format is only chosen when the client prefers its exact media type, so
+// browsers, which prefer HTML and accept */*, get JSON rather than XML.
+func negotiateFormat(r *http.Request) (*format, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		f, ok := formatByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unsupported format %q, supported formats are: %s", name, formatNames())
+		}
+		return f, nil
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return formatJSON, nil
+	}
+
+	type mediaRange struct {
+		mediaType string
+		q         float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, mediaRange{mediaType, q})
+		}
+	}
+
+	// Highest quality first, earlier ranges break ties
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	// An exact media type among the most preferred ranges selects its format
+	for _, mr := range ranges {
+		if mr.q < ranges[0].q {
+			break
+		}
+		if f, ok := formatForMediaType(mr.mediaType); ok {
+			return f, nil
+		}
+	}
+
+	// Wildcards, and ties between them and other formats, mean JSON
+	for _, mr := range ranges {
+		if strings.HasSuffix(mr.mediaType, "/*") {
+			return formatJSON, nil
+		}
+	}
+
+	// Otherwise the most preferred of the supported media types
+	for _, mr := range ranges {
+		if f, ok := formatForMediaType(mr.mediaType); ok {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("none of the accepted media types are supported, supported formats are: %s", formatNames())
+}
+
+// formatKey is the context key of the negotiated format
+type formatKey struct{}
+
+// formatMiddleware negotiates the response format of content endpoints,
+// rejecting requests for unsupported formats with 406 Not Acceptable
+func formatMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+
+		f, err := negotiateFormat(r)
+		if err != nil {
+			respondWithError(w, http.StatusNotAcceptable, err.Error())
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), formatKey{}, f)))
+	})
+}
+
+// requestFormat returns the negotiated format of a request, or JSON
+func requestFormat(r *http.Request) *format {
+	if f, ok := r.Context().Value(formatKey{}).(*format); ok {
+		return f
+	}
+	return formatJSON
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		accept string
+		want   *format
+	}{
+		{"default", "", "", formatJSON},
+		{"browser", "", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", formatJSON},
+		{"any", "", "*/*", formatJSON},
+		{"text wildcard", "", "text/*", formatJSON},
+		{"wildcard tie", "", "text/csv, */*", formatCSV},
+		{"wildcard preferred", "", "*/*, text/csv;q=0.5", formatJSON},
+		{"exact", "", "text/csv", formatCSV},
+		{"exact alias", "", "text/xml", formatXML},
+		{"quality", "", "application/xml;q=0.5, application/x-yaml", formatYAML},
+		{"unsupported preferred", "", "text/html, application/x-ndjson;q=0.5", formatNDJSON},
+		{"refused", "", "application/json;q=0, text/plain", formatText},
+		{"parameter", "format=csv", "application/xml", formatCSV},
+		{"parameter alias", "format=yml", "", formatYAML},
+		{"parameter case", "format=NDJSON", "", formatNDJSON},
+		{"text alias", "format=txt", "", formatText},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			got, err := negotiateFormat(r)
+			if err != nil {
+				t.Fatalf("negotiateFormat failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("format = %s, want %s", got.name, tt.want.name)
+			}
+		})
+	}
+}
+
+func TestNegotiateFormatErrors(t *testing.T) {
+	for _, target := range []string{"/?format=pdf", "/"} {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.Header.Set("Accept", "text/html, image/png")
+		if f, err := negotiateFormat(r); err == nil {
+			t.Errorf("%s negotiated %s, want an error", target, f.name)
+		}
+	}
+}
+
+func TestFormatNotAcceptable(t *testing.T) {
+	w := serve(t, http.MethodGet, "/api/v1/quotes/1", "", http.Header{"Accept": {"image/png"}})
+	if w.Code != http.StatusNotAcceptable {
+		t.Errorf("status %d, want 406", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+		t.Errorf("error Content-Type %q, want JSON", got)
+	}
+}
+
+// testContent is a list of two records exercising escaping in every format
+var testContent = contentBody{records: []record{
+	{fields: []recordField{{"id", 1}, {"quote", `Say "hi", <friend> & go`}}, text: "Say hi\n  — A"},
+	{fields: []recordField{{"id", 2}, {"quote", "Second"}, {"score", 0.5}}, text: "Second"},
+}}
+
+func TestContentWriters(t *testing.T) {
+	single := contentBody{records: testContent.records[:1], single: true}
+	tests := []struct {
+		name  string
+		write func(*bufio.Writer, contentBody)
+		body  contentBody
+		want  string
+	}{
+		{"text", writeText, testContent, "Say hi\n  — A\n\nSecond\n"},
+		{"text single line", writeText, contentBody{records: testContent.records[1:]}, "Second\n"},
+		{"csv", writeCSV, testContent, "id,quote,score\n1,\"Say \"\"hi\"\", <friend> & go\",\n2,Second,0.5\n"},
+		{"csv empty", writeCSV, contentBody{}, "\n"},
+		{"xml", writeXML, testContent, `<?xml version="1.0" encoding="UTF-8"?>
+<items>
+  <item>
+    <id>1</id>
+    <quote>Say &#34;hi&#34;, &lt;friend&gt; &amp; go</quote>
+  </item>
+  <item>
+    <id>2</id>
+    <quote>Second</quote>
+    <score>0.5</score>
+  </item>
+</items>
+`},
+		{"xml single", writeXML, single, `<?xml version="1.0" encoding="UTF-8"?>
+<item>
+  <id>1</id>
+  <quote>Say &#34;hi&#34;, &lt;friend&gt; &amp; go</quote>
+</item>
+`},
+		{"yaml", writeYAML, testContent, `- id: 1
+  quote: "Say \"hi\", <friend> & go"
+- id: 2
+  quote: "Second"
+  score: 0.5
+`},
+		{"yaml single", writeYAML, single, "id: 1\nquote: \"Say \\\"hi\\\", <friend> & go\"\n"},
+		{"yaml empty", writeYAML, contentBody{}, "[]\n"},
+		{"ndjson", func(w *bufio.Writer, c contentBody) { writeNDJSON(w, httptest.NewRecorder(), c) }, testContent,
+			`{"id":1,"quote":"Say \"hi\", \u003cfriend\u003e \u0026 go"}` + "\n" +
+				`{"id":2,"quote":"Second","score":0.5}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			w := bufio.NewWriter(&sb)
+			tt.write(w, tt.body)
+			w.Flush()
+			if sb.String() != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", sb.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestContentResponses(t *testing.T) {
+	tests := []struct {
+		target      string
+		accept      string
+		contentType string
+		contains    string
+	}{
+		{"/api/v1/quotes/1", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "application/json", `"success":true`},
+		{"/api/v1/quotes/1", "text/csv", "text/csv", "id,"},
+		{"/api/v1/quotes/1?format=xml", "", "application/xml", "<item>"},
+		{"/api/v1/quotes/1?format=yaml", "", "application/yaml", "id: 1\n"},
+		{"/api/v1/quotes/1?format=text", "", "text/plain", "Inspirational quote #1"},
+		{"/api/v1/quotes?format=ndjson&limit=3", "", "application/x-ndjson", `{"id":`},
+	}
+	for _, tt := range tests {
+		w := serve(t, http.MethodGet, tt.target, "", http.Header{"Accept": {tt.accept}})
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status %d", tt.target, w.Code)
+			continue
+		}
+		if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.contentType) {
+			t.Errorf("%s (Accept %q): Content-Type %q, want %s", tt.target, tt.accept, got, tt.contentType)
+		}
+		if !strings.Contains(w.Body.String(), tt.contains) {
+			t.Errorf("%s: body %q does not contain %q", tt.target, w.Body.String(), tt.contains)
+		}
+		if vary := w.Header().Values("Vary"); !strings.Contains(strings.Join(vary, ","), "Accept") {
+			t.Errorf("%s: Vary %v lacks Accept", tt.target, vary)
+		}
+	}
+}
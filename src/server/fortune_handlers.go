@@ -0,0 +1,33 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/apimgr/quotes/src/fortune"
+)
+
+// handleCollectionFortune returns a collection as a fortune(6) cookie file
+func handleCollectionFortune(c collection.Collection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondWithFile(w, "text/plain; charset=utf-8", c.Info().Name, fortune.Cookies(c))
+	}
+}
+
+// handleCollectionFortuneIndex returns the strfile(1) index of the cookie file
+func handleCollectionFortuneIndex(c collection.Collection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondWithFile(w, "application/octet-stream", c.Info().Name+".dat", fortune.Index(fortune.Cookies(c)))
+	}
+}
+
+// respondWithFile sends data as a download named filename
+func respondWithFile(w http.ResponseWriter, contentType, filename string, data []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -0,0 +1,295 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/apimgr/quotes/src/fortune"
+	"github.com/apimgr/quotes/src/gopher"
+	"github.com/apimgr/quotes/src/lineserver"
+	"github.com/apimgr/quotes/src/paths"
+)
+
+const (
+	// gopherPageSize is the number of entries of a menu page
+	gopherPageSize = 100
+
+	// gopherBannerFile is the optional file, in the config directory, whose
+	// text heads the root menu
+	gopherBannerFile = "gopher-banner.txt"
+)
+
+// gopherSite answers Gopher requests with a menu per collection, browsable
+// by field value, and the items as text documents
+type gopherSite struct {
+	server *Server
+	host   string // Host and port menus link to
+	port   int
+	banner string
+}
+
+// newGopherSite returns the site served on the Gopher port, linking to the
+// gopher.hostname and gopher.port settings, or this host and the port
+// listened on
+func (s *Server) newGopherSite() (*gopherSite, error) {
+	s.settingsMutex.RLock()
+	host := s.settingsCache["gopher.hostname"].(string)
+	portSetting := s.settingsCache["gopher.port"].(string)
+	s.settingsMutex.RUnlock()
+
+	if host == "" {
+		var err error
+		if host, err = os.Hostname(); err != nil {
+			host = "localhost"
+		}
+	}
+	if portSetting == "" {
+		portSetting = s.ports.Gopher
+	}
+	port, err := strconv.Atoi(portSetting)
+	if err != nil || port < 1 || port > 65535 {
+		return nil, fmt.Errorf("invalid Gopher port %q", portSetting)
+	}
+
+	site := &gopherSite{server: s, host: host, port: port, banner: fmt.Sprintf("Quotes API v%s", Version)}
+	banner, err := os.ReadFile(filepath.Join(paths.GetConfigDir(), gopherBannerFile))
+	if err == nil {
+		site.banner = string(banner)
+	} else if !os.IsNotExist(err) {
+		log.Printf("⚠️  Warning: Failed to read Gopher banner: %v", err)
+	}
+	return site, nil
+}
+
+// startGopher serves the Gopher site on the Gopher port
+func (s *Server) startGopher() error {
+	site, err := s.newGopherSite()
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(s.address, s.ports.Gopher)
+	if s.gopher, err = lineserver.Listen(addr, site.serve); err != nil {
+		return fmt.Errorf("failed to listen for Gopher on %s: %w", addr, err)
+	}
+	log.Printf("Gopher listening on %s, as gopher://%s:%d/", addr, site.host, site.port)
+	return nil
+}
+
+// serve answers one Gopher request
+func (g *gopherSite) serve(w io.Writer, line string) {
+	req := gopher.ParseRequest(line)
+	selector, pageParam, _ := strings.Cut(req.Selector, "?page=")
+	page := 1
+	if pageParam != "" {
+		var err error
+		if page, err = strconv.Atoi(pageParam); err != nil || page < 1 {
+			gopher.WriteError(w, "Invalid page")
+			return
+		}
+	}
+
+	parts := strings.Split(strings.Trim(selector, "/"), "/")
+	if parts[0] == "" {
+		g.rootMenu().WriteTo(w)
+		return
+	}
+	c, ok := collection.Get(parts[0])
+	if !ok {
+		gopher.WriteError(w, "Not found: "+req.Selector)
+		return
+	}
+	info := c.Info()
+
+	switch {
+	case len(parts) == 1:
+		g.collectionMenu(c).WriteTo(w)
+
+	case len(parts) == 2 && parts[1] == "random":
+		item, err := g.server.randomItem(c)
+		if err != nil {
+			gopher.WriteError(w, fmt.Sprintf("No %s available", info.Title))
+			return
+		}
+		gopher.WriteText(w, itemDocument(info, item))
+
+	case len(parts) == 2 && parts[1] == "daily":
+		item, err := collection.PeriodicItem(c, collection.Daily, time.Now().UTC())
+		if err != nil {
+			gopher.WriteError(w, err.Error())
+			return
+		}
+		gopher.WriteText(w, itemDocument(info, item))
+
+	case len(parts) == 2 && parts[1] == "all":
+		m := gopher.NewMenu(g.host, g.port)
+		m.Info(fmt.Sprintf("All %s", info.Title))
+		g.itemPage(m, c, c.Items(), "/"+info.Name+"/all", page)
+		m.WriteTo(w)
+
+	case len(parts) == 2 && parts[1] == "search":
+		g.searchMenu(c, req.Query).WriteTo(w)
+
+	case len(parts) == 3 && parts[1] == "item":
+		id, err := strconv.Atoi(parts[2])
+		if err != nil {
+			gopher.WriteError(w, fmt.Sprintf("Invalid %s ID", info.ItemName))
+			return
+		}
+		item, err := c.ItemByID(id)
+		if err != nil {
+			gopher.WriteError(w, err.Error())
+			return
+		}
+		gopher.WriteText(w, itemDocument(info, item))
+
+	case len(parts) == 2 && info.HasField(parts[1]):
+		g.facetMenu(c, parts[1], page).WriteTo(w)
+
+	case len(parts) == 3 && info.HasField(parts[1]):
+		value, err := url.PathUnescape(parts[2])
+		if err != nil {
+			gopher.WriteError(w, "Not found: "+req.Selector)
+			return
+		}
+		m := gopher.NewMenu(g.host, g.port)
+		m.Info(fmt.Sprintf("%s with %s %q", capitalize(info.Title), parts[1], value))
+		m.Link(gopher.TypeMenu, "Back to "+info.Title, "/"+info.Name)
+		m.Info("")
+		g.itemPage(m, c, c.ItemsByField(parts[1], value), selector, page)
+		m.WriteTo(w)
+
+	default:
+		gopher.WriteError(w, "Not found: "+req.Selector)
+	}
+}
+
+// rootMenu lists the collections
+func (g *gopherSite) rootMenu() *gopher.Menu {
+	m := gopher.NewMenu(g.host, g.port)
+	m.Info(g.banner)
+	m.Info("")
+	for _, c := range collection.All() {
+		info := c.Info()
+		m.Link(gopher.TypeMenu, fmt.Sprintf("%s (%d)", capitalize(info.Title), c.Count()), "/"+info.Name)
+	}
+	return m
+}
+
+// collectionMenu links to the random and daily items of a collection, its
+// items browsed by field value or in full, and its search
+func (g *gopherSite) collectionMenu(c collection.Collection) *gopher.Menu {
+	info := c.Info()
+	base := "/" + info.Name
+	m := gopher.NewMenu(g.host, g.port)
+	m.Info(fmt.Sprintf("%s: %d %s", capitalize(info.Title), c.Count(), info.Title))
+	m.Info("")
+	m.Link(gopher.TypeText, "Random "+info.ItemName, base+"/random")
+	m.Link(gopher.TypeText, capitalize(info.ItemName)+" of the day", base+"/daily")
+	m.Link(gopher.TypeSearch, "Search "+info.Title, base+"/search")
+	m.Info("")
+	for _, f := range info.Fields {
+		m.Link(gopher.TypeMenu, "Browse by "+f.Name, base+"/"+f.Name)
+	}
+	m.Link(gopher.TypeMenu, "All "+info.Title, base+"/all")
+	m.Info("")
+	m.Link(gopher.TypeMenu, "Back to the collections", "/")
+	return m
+}
+
+// facetMenu lists the values of a field, most common first
+func (g *gopherSite) facetMenu(c collection.Collection, field string, page int) *gopher.Menu {
+	info := c.Info()
+	base := "/" + info.Name + "/" + field
+	facets := c.Facets(field)
+
+	m := gopher.NewMenu(g.host, g.port)
+	m.Info(fmt.Sprintf("%s by %s", capitalize(info.Title), field))
+	m.Link(gopher.TypeMenu, "Back to "+info.Title, "/"+info.Name)
+	m.Info("")
+	start, end := gopherPageBounds(len(facets), page)
+	for _, f := range facets[start:end] {
+		m.Link(gopher.TypeMenu, fmt.Sprintf("%s (%d)", f.Value, f.Count), base+"/"+url.PathEscape(f.Value))
+	}
+	gopherPageLinks(m, base, len(facets), page)
+	return m
+}
+
+// searchMenu lists the best matches of a query
+func (g *gopherSite) searchMenu(c collection.Collection, query string) *gopher.Menu {
+	info := c.Info()
+	m := gopher.NewMenu(g.host, g.port)
+	query = strings.TrimSpace(query)
+	if query == "" {
+		m.Error("Enter words to search for")
+		return m
+	}
+
+	hits := rankedHits([]collection.Collection{c}, query)
+	m.Info(fmt.Sprintf("%d %s matching %q", len(hits), info.Title, query))
+	m.Link(gopher.TypeMenu, "Back to "+info.Title, "/"+info.Name)
+	m.Info("")
+	for _, h := range hits[:min(gopherPageSize, len(hits))] {
+		m.Link(gopher.TypeText, summarize(h.result().Snippet, 70), fmt.Sprintf("/%s/item/%d", info.Name, h.hit.Item.GetID()))
+	}
+	return m
+}
+
+// itemPage adds a page of items to a menu, linking to their documents
+func (g *gopherSite) itemPage(m *gopher.Menu, c collection.Collection, items []collection.Item, base string, page int) {
+	info := c.Info()
+	start, end := gopherPageBounds(len(items), page)
+	for _, item := range items[start:end] {
+		m.Link(gopher.TypeText, summarize(item.Field(info.TextField), 70), fmt.Sprintf("/%s/item/%d", info.Name, item.GetID()))
+	}
+	if len(items) == 0 {
+		m.Info("Nothing here")
+	}
+	gopherPageLinks(m, base, len(items), page)
+}
+
+// gopherPageBounds returns the range of the entries on a page
+func gopherPageBounds(total, page int) (int, int) {
+	start := min((page-1)*gopherPageSize, total)
+	return start, min(start+gopherPageSize, total)
+}
+
+// gopherPageLinks adds links to the pages before and after page
+func gopherPageLinks(m *gopher.Menu, base string, total, page int) {
+	pages := (total + gopherPageSize - 1) / gopherPageSize
+	if pages <= 1 {
+		return
+	}
+	m.Info("")
+	m.Info(fmt.Sprintf("Page %d of %d", page, pages))
+	if page > 1 {
+		m.Link(gopher.TypeMenu, "Previous page", fmt.Sprintf("%s?page=%d", base, page-1))
+	}
+	if page < pages {
+		m.Link(gopher.TypeMenu, "Next page", fmt.Sprintf("%s?page=%d", base, page+1))
+	}
+}
+
+// itemDocument returns an item as a plain text document: the item in
+// fortune style, followed by its fields
+func itemDocument(info collection.Info, item collection.Item) string {
+	var b strings.Builder
+	b.WriteString(fortune.Entry(info, item))
+	b.WriteString("\n\n")
+	fmt.Fprintf(&b, "%s #%d\n", capitalize(info.ItemName), item.GetID())
+	for _, f := range info.Fields {
+		if v := item.Field(f.Name); v != "" {
+			fmt.Fprintf(&b, "%s: %s\n", capitalize(f.Name), v)
+		}
+	}
+	return b.String()
+}
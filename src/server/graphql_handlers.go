@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// maxGraphQLRequestSize is the largest GraphQL request body accepted
+const maxGraphQLRequestSize = 256 << 10
+
+// readGraphQLRequest reads a GraphQL request from the query string of a GET
+// request, or from a POST body holding JSON or, as application/graphql,
+// the bare query
+func readGraphQLRequest(w http.ResponseWriter, r *http.Request) (graphQLRequest, error) {
+	var req graphQLRequest
+
+	if r.Method == http.MethodGet {
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return req, fmt.Errorf("variables must be a JSON object")
+			}
+		}
+		return req, nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxGraphQLRequestSize))
+	if err != nil {
+		return req, fmt.Errorf("request body must be at most %d bytes", maxGraphQLRequestSize)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/graphql" {
+		req.Query = string(body)
+		return req, nil
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return req, fmt.Errorf("request body must be a JSON object with a query")
+	}
+	return req, nil
+}
+
+// handleGraphQL runs a GraphQL query over every collection. Errors in the
+// query are reported in the errors of the response, with 200 OK; only
+// requests without a readable query get 400 Bad Request.
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	req, err := readGraphQLRequest(w, r)
+	if err == nil && req.Query == "" {
+		err = fmt.Errorf("query is required")
+	}
+	if err != nil {
+		respondWithJSON(w, http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, s.graphql.execute(r.Context(), req))
+}
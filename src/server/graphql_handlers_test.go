@@ -0,0 +1,201 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// graphQLResponse is the body of a GraphQL response
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// postGraphQL sends a query to the GraphQL endpoint and decodes the response
+func postGraphQL(t *testing.T, query string) graphQLResponse {
+	t.Helper()
+	return decodeGraphQL(t, serve(t, http.MethodPost, "/api/v1/graphql", query, http.Header{"Content-Type": {"application/graphql"}}))
+}
+
+// postGraphQLVariables sends a query with variables as JSON to the GraphQL
+// endpoint and decodes the response
+func postGraphQLVariables(t *testing.T, query string, variables map[string]interface{}) graphQLResponse {
+	t.Helper()
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decodeGraphQL(t, serve(t, http.MethodPost, "/api/v1/graphql", string(body), http.Header{"Content-Type": {"application/json"}}))
+}
+
+// decodeGraphQL decodes a GraphQL response, which must have status 200
+func decodeGraphQL(t *testing.T, w *httptest.ResponseRecorder) graphQLResponse {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	var resp graphQLResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding %q: %v", w.Body.String(), err)
+	}
+	return resp
+}
+
+func TestGraphQLComplexity(t *testing.T) {
+	var page struct {
+		Quotes struct {
+			Items []struct {
+				ID int `json:"id"`
+			} `json:"items"`
+		} `json:"quotes"`
+	}
+	resp := postGraphQL(t, `{ quotes(limit: 1000) { total items { id text author } } }`)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("full page rejected: %v", resp.Errors)
+	}
+	if err := json.Unmarshal(resp.Data, &page); err != nil || len(page.Quotes.Items) == 0 {
+		t.Fatalf("data %s: %v", resp.Data, err)
+	}
+
+	// 100 anime with 100 characters each could resolve over 20,000 fields
+	nested := `{ allAnime(limit: 100) { items { characters(limit: 100) { items { name } } } } }`
+	resp = postGraphQL(t, nested)
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "complexity exceeds the limit") || string(resp.Data) != "null" {
+		t.Errorf("nested pages not rejected: %+v", resp)
+	}
+	if resp = postGraphQL(t, strings.Replace(nested, "limit: 100", "limit: 10", 1)); len(resp.Errors) > 0 {
+		t.Errorf("smaller pages rejected: %v", resp.Errors)
+	}
+
+	// Many aliases are rejected quickly, before the quadratic validation
+	var b strings.Builder
+	b.WriteString("{")
+	for i := 0; b.Len() < maxGraphQLRequestSize-100; i++ {
+		fmt.Fprintf(&b, " q%d: randomQuotes(count: 100) { text }", i)
+	}
+	b.WriteString(" }")
+	start := time.Now()
+	resp = postGraphQL(t, b.String())
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "more than 500 selections") {
+		t.Errorf("aliased query not rejected: %+v", resp.Errors)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("aliased query took %v", elapsed)
+	}
+}
+
+func TestGraphQLQuery(t *testing.T) {
+	resp := postGraphQLVariables(t, `query($id: Int!, $n: Int = 2) {
+		quote(id: $id) { __typename id collection ...text }
+		quotes(limit: $n, order: DESC) { limit hasMore items { id } }
+		search(q: "life", limit: 1) { results { item { __typename ... on Quote { author } } } }
+	}
+	fragment text on Item { text attribution }`, map[string]interface{}{"id": 1})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors: %v", resp.Errors)
+	}
+	var data struct {
+		Quote struct {
+			Typename   string `json:"__typename"`
+			ID         int    `json:"id"`
+			Collection string `json:"collection"`
+			Text       string `json:"text"`
+		} `json:"quote"`
+		Quotes struct {
+			Limit   int  `json:"limit"`
+			HasMore bool `json:"hasMore"`
+			Items   []struct {
+				ID int `json:"id"`
+			} `json:"items"`
+		} `json:"quotes"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatalf("data %s: %v", resp.Data, err)
+	}
+	if q := data.Quote; q.Typename != "Quote" || q.ID != 1 || q.Collection != "QUOTES" || q.Text == "" {
+		t.Errorf("quote = %+v", q)
+	}
+	if p := data.Quotes; p.Limit != 2 || !p.HasMore || len(p.Items) != 2 || p.Items[0].ID < p.Items[1].ID {
+		t.Errorf("quotes = %+v", p)
+	}
+
+	resp = postGraphQL(t, `{ quotes { items { id nope } } }`)
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "nope") || string(resp.Data) != "null" {
+		t.Errorf("unknown field: %+v", resp)
+	}
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		err       string
+	}{
+		{
+			name:  "deep",
+			query: `{ character(name: "x") { anime { characters(limit: 1) { items { anime { characters(limit: 1) { items { anime { characters(limit: 1) { items { anime { characters(limit: 1) { total } } } } } } } } } } } } }`,
+			err:   "nested deeper than the limit of 12 levels",
+		},
+		{
+			name:  "brackets",
+			query: "{ quotes(excludeIds: " + strings.Repeat("[", maxGraphQLNesting) + "1" + strings.Repeat("]", maxGraphQLNesting) + ") { total } }",
+			err:   "nested deeper than the limit of 100 brackets",
+		},
+		{
+			name:  "brackets in strings",
+			query: `{ search(q: "` + strings.Repeat("{", 2*maxGraphQLNesting) + `", limit: 1) { total } } # ` + strings.Repeat("[", 2*maxGraphQLNesting),
+		},
+		{
+			name:      "variable limit",
+			query:     `query($n: Int) { allAnime(limit: $n) { items { characters(limit: $n) { items { name } } } } }`,
+			variables: map[string]interface{}{"n": 100},
+			err:       "complexity exceeds the limit",
+		},
+		{
+			name:      "variable default",
+			query:     `query($n: Int = 10) { allAnime(limit: $n) { items { characters(limit: $n) { items { name } } } } }`,
+			variables: map[string]interface{}{},
+		},
+		{
+			name:  "fragment",
+			query: `{ allAnime(limit: 100) { items { ...chars } } } fragment chars on Anime { characters(limit: 100) { items { name } } }`,
+			err:   "complexity exceeds the limit",
+		},
+		{
+			name:  "introspection",
+			query: `{ __type(name: "Quote") { name fields { name args { name } type { kind name ofType { name } } } } }`,
+		},
+		{
+			name:  "introspection cycle",
+			query: `{ __schema { types { fields { type { fields { type { fields { name } } } } } } } }`,
+			err:   "complexity exceeds the limit",
+		},
+		{
+			name:      "skipped",
+			query:     `query($skip: Boolean!) { allAnime(limit: 100) { items { characters(limit: 100) @skip(if: $skip) { items { name } } } } }`,
+			variables: map[string]interface{}{"skip": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := postGraphQLVariables(t, tt.query, tt.variables)
+			if tt.err == "" {
+				if len(resp.Errors) > 0 {
+					t.Errorf("errors: %v", resp.Errors)
+				}
+				return
+			}
+			if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.err) {
+				t.Errorf("errors = %v, want %q", resp.Errors, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// graphQLRequest is a GraphQL request: a query, the operation of it to run
+// and the values of its variables
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// execute parses, validates and runs a request. Queries nested too deeply
+// or estimated too costly fail before any field resolves.
+func (gs *graphQLSchema) execute(ctx context.Context, req graphQLRequest) *graphql.Result {
+	if err := checkGraphQLNesting(req.Query); err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"})})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if err := checkGraphQLSelections(doc); err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if validation := graphql.ValidateDocument(&gs.schema, doc, nil); !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+	if err := gs.checkLimits(doc, req.OperationName, req.Variables); err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        gs.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+}
+
+// checkGraphQLNesting rejects documents whose brackets nest deeper than
+// maxGraphQLNesting, so a hostile document cannot exhaust the stack of
+// the parser. Brackets in strings and comments are skipped.
+func checkGraphQLNesting(query string) error {
+	depth := 0
+	for i := 0; i < len(query); i++ {
+		switch query[i] {
+		case '{', '[', '(':
+			if depth++; depth > maxGraphQLNesting {
+				return fmt.Errorf("Query is nested deeper than the limit of %d brackets.", maxGraphQLNesting)
+			}
+		case '}', ']', ')':
+			depth--
+		case '#':
+			for i < len(query) && query[i] != '\n' && query[i] != '\r' {
+				i++
+			}
+		case '"':
+			if len(query) >= i+3 && query[i:i+3] == `"""` {
+				for i += 3; i < len(query) && !(len(query) >= i+3 && query[i:i+3] == `"""`); i++ {
+					if query[i] == '\\' && len(query) >= i+4 && query[i+1:i+4] == `"""` {
+						i += 3
+					}
+				}
+				i += 2
+				continue
+			}
+			for i++; i < len(query) && query[i] != '"' && query[i] != '\n'; i++ {
+				if query[i] == '\\' {
+					i++
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkGraphQLSelections rejects documents with more than
+// maxGraphQLSelections selections, counting each as written
+func checkGraphQLSelections(doc *ast.Document) error {
+	var sets []*ast.SelectionSet
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			sets = append(sets, def.SelectionSet)
+		case *ast.FragmentDefinition:
+			sets = append(sets, def.SelectionSet)
+		}
+	}
+
+	selections := 0
+	for len(sets) > 0 {
+		set := sets[len(sets)-1]
+		sets = sets[:len(sets)-1]
+		if set == nil {
+			continue
+		}
+		if selections += len(set.Selections); selections > maxGraphQLSelections {
+			return fmt.Errorf("Query has more than %d selections; request fewer fields.", maxGraphQLSelections)
+		}
+		for _, sel := range set.Selections {
+			sets = append(sets, sel.GetSelectionSet())
+		}
+	}
+	return nil
+}
+
+// queryLimits walks the operation of a query to find how deep it nests
+// and estimate what it costs
+type queryLimits struct {
+	schema    *graphql.Schema
+	costs     map[string]graphCost
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	defaults  map[string]ast.Value // Default values of variables
+	walked    int                  // Selections visited
+	tooDeep   bool
+}
+
+// checkLimits rejects a valid query nested deeper than maxGraphQLDepth or
+// estimated to cost more than maxGraphQLComplexity. A field costs 1 plus
+// its subselection, which list fields multiply by their limit or count.
+// Operations that cannot be chosen or are not queries are left for
+// Execute to report.
+func (gs *graphQLSchema) checkLimits(doc *ast.Document, operationName string, variables map[string]interface{}) error {
+	l := &queryLimits{
+		schema:    &gs.schema,
+		costs:     gs.costs,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+		defaults:  make(map[string]ast.Value),
+	}
+
+	var op *ast.OperationDefinition
+	operations := 0
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			l.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			operations++
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				op = def
+			}
+		}
+	}
+	if op == nil || (operationName == "" && operations > 1) || op.Operation != ast.OperationTypeQuery {
+		return nil
+	}
+	for _, v := range op.VariableDefinitions {
+		if v.DefaultValue != nil {
+			l.defaults[v.Variable.Name.Value] = v.DefaultValue
+		}
+	}
+
+	cost := l.complexity(gs.schema.QueryType(), op.SelectionSet, 1)
+	if l.tooDeep {
+		return fmt.Errorf("Query is nested deeper than the limit of %d levels.", maxGraphQLDepth)
+	}
+	if cost > maxGraphQLComplexity {
+		return fmt.Errorf("Query complexity exceeds the limit of %d; request fewer fields or smaller pages.", maxGraphQLComplexity)
+	}
+	return nil
+}
+
+// complexity returns the estimated cost of a selection set at depth on a
+// value of type t: the sum of the costs of its fields, taking the most
+// costly object type for interfaces. Costs saturate at
+// maxGraphQLComplexity+1, and the walk stops once it has visited more
+// than maxGraphQLComplexity selections, so aliases and repeated fragments
+// cannot make the estimate itself costly.
+func (l *queryLimits) complexity(t graphql.Type, set *ast.SelectionSet, depth int) int {
+	if set == nil {
+		return 0
+	}
+	if depth > maxGraphQLDepth {
+		l.tooDeep = true
+		return 0
+	}
+
+	var objects []*graphql.Object
+	switch t := graphql.GetNamed(t).(type) {
+	case *graphql.Object:
+		objects = []*graphql.Object{t}
+	case graphql.Abstract:
+		objects = l.schema.PossibleTypes(t)
+	}
+
+	cost := 0
+	for _, obj := range objects {
+		cost = max(cost, l.selectionsCost(obj, set.Selections, depth))
+		if l.exhausted() {
+			break
+		}
+	}
+	return saturate(cost)
+}
+
+// selectionsCost returns the estimated cost of selections on obj
+func (l *queryLimits) selectionsCost(obj *graphql.Object, selections []ast.Selection, depth int) int {
+	cost := 0
+	for _, sel := range selections {
+		if l.walked++; l.exhausted() {
+			return maxGraphQLComplexity + 1
+		}
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if l.included(sel.Directives) {
+				cost = saturate(cost + l.fieldCost(obj, sel, depth))
+			}
+		case *ast.InlineFragment:
+			if l.included(sel.Directives) && l.applies(sel.TypeCondition, obj) {
+				cost = saturate(cost + l.selectionsCost(obj, sel.SelectionSet.Selections, depth))
+			}
+		case *ast.FragmentSpread:
+			frag := l.fragments[sel.Name.Value]
+			if frag != nil && l.included(sel.Directives) && l.applies(frag.TypeCondition, obj) {
+				cost = saturate(cost + l.selectionsCost(obj, frag.SelectionSet.Selections, depth))
+			}
+		}
+	}
+	return cost
+}
+
+// fieldCost returns the estimated cost of a field of obj
+func (l *queryLimits) fieldCost(obj *graphql.Object, f *ast.Field, depth int) int {
+	def := graphql.DefaultTypeInfoFieldDef(l.schema, obj, f)
+	if def == nil {
+		return 1
+	}
+	child := l.complexity(def.Type, f.SelectionSet, depth+1)
+
+	c, ok := l.costs[obj.Name()+"."+f.Name.Value]
+	if !ok {
+		return saturate(1 + child)
+	}
+	n := c.max
+	if c.arg != "" {
+		n = min(max(l.intArgument(def, f, c.arg, c.max), 0), c.max)
+	}
+	return saturate(1 + n*child)
+}
+
+// intArgument returns the integer argument name of a field, its default
+// when it is not given, or fallback when its value is not known
+func (l *queryLimits) intArgument(def *graphql.FieldDefinition, f *ast.Field, name string, fallback int) int {
+	for _, arg := range f.Arguments {
+		if arg.Name.Value == name {
+			return l.intValue(arg.Value, fallback)
+		}
+	}
+	for _, arg := range def.Args {
+		if n, ok := arg.DefaultValue.(int); ok && arg.Name() == name {
+			return n
+		}
+	}
+	return fallback
+}
+
+// intValue returns the integer a value stands for, or fallback
+func (l *queryLimits) intValue(v ast.Value, fallback int) int {
+	switch v := v.(type) {
+	case *ast.IntValue:
+		if n, err := strconv.Atoi(v.Value); err == nil {
+			return n
+		}
+	case *ast.Variable:
+		switch n := l.variables[v.Name.Value].(type) {
+		case nil:
+			if def, ok := l.defaults[v.Name.Value]; ok {
+				return l.intValue(def, fallback)
+			}
+		case float64:
+			if n == math.Trunc(n) && math.Abs(n) <= math.MaxInt32 {
+				return int(n)
+			}
+		case int:
+			return n
+		}
+	}
+	return fallback
+}
+
+// included reports whether the @skip and @include directives keep a
+// selection. Conditions whose value is not known keep it.
+func (l *queryLimits) included(directives []*ast.Directive) bool {
+	for _, d := range directives {
+		for _, arg := range d.Arguments {
+			if arg.Name.Value != "if" {
+				continue
+			}
+			cond, ok := l.boolValue(arg.Value)
+			if ok && ((d.Name.Value == "skip" && cond) || (d.Name.Value == "include" && !cond)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// boolValue returns the boolean a value stands for, if it is known
+func (l *queryLimits) boolValue(v ast.Value) (bool, bool) {
+	switch v := v.(type) {
+	case *ast.BooleanValue:
+		return v.Value, true
+	case *ast.Variable:
+		if b, ok := l.variables[v.Name.Value].(bool); ok {
+			return b, true
+		}
+		if def, ok := l.defaults[v.Name.Value]; ok {
+			return l.boolValue(def)
+		}
+	}
+	return false, false
+}
+
+// applies reports whether a fragment with a type condition applies to obj
+func (l *queryLimits) applies(cond *ast.Named, obj *graphql.Object) bool {
+	if cond == nil {
+		return true
+	}
+	switch t := l.schema.Type(cond.Name.Value).(type) {
+	case *graphql.Object:
+		return t == obj
+	case graphql.Abstract:
+		return l.schema.IsPossibleType(t, obj)
+	}
+	return false
+}
+
+// exhausted reports whether the walk has visited more than
+// maxGraphQLComplexity selections
+func (l *queryLimits) exhausted() bool {
+	return l.walked > maxGraphQLComplexity
+}
+
+// saturate caps a cost at maxGraphQLComplexity+1
+func saturate(cost int) int {
+	return min(cost, maxGraphQLComplexity+1)
+}
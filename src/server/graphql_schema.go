@@ -0,0 +1,1039 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apimgr/quotes/src/anime"
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/graphql-go/graphql"
+)
+
+// GraphQL queries run on github.com/graphql-go/graphql, whose schema is
+// built from the registered collections at startup. The library has no
+// limits of its own, so every way a query can grow is bounded here: the
+// body size, the nesting of the document, checked before it is parsed,
+// the number of selections, checked before the library's validation,
+// which is quadratic in them, and the selection depth and estimated
+// complexity, checked before any field resolves.
+const (
+	// maxGraphQLNesting bounds how deeply the brackets of a GraphQL document may nest
+	maxGraphQLNesting = 100
+
+	// maxGraphQLSelections bounds the fields, fragment spreads and inline
+	// fragments written in a GraphQL document
+	maxGraphQLSelections = 500
+
+	// maxGraphQLDepth bounds how deeply the selections of a GraphQL query may nest
+	maxGraphQLDepth = 12
+
+	// maxGraphQLComplexity bounds the estimated cost of a GraphQL query,
+	// roughly the fields it resolves if every page is full
+	maxGraphQLComplexity = 20000
+)
+
+// graphItem is the source of item objects: an item and its collection
+type graphItem struct {
+	coll collection.Collection
+	item collection.Item
+}
+
+// graphPage is the source of page objects. Query is set for search pages.
+type graphPage struct {
+	query  string
+	total  int
+	limit  int
+	offset int
+	items  []interface{}
+}
+
+// animeEntity is the source of Anime objects
+type animeEntity struct {
+	name string
+}
+
+// characterEntity is the source of Character objects
+type characterEntity struct {
+	name  string
+	anime string
+}
+
+// graphCost is how a list field multiplies the cost of its subselection:
+// by its argument arg, capped at max, or by max when arg is empty
+type graphCost struct {
+	arg string
+	max int
+}
+
+// graphQLSchema is the GraphQL schema with the costs of its list fields
+type graphQLSchema struct {
+	schema graphql.Schema
+	costs  map[string]graphCost // By "Type.field"
+}
+
+// graphSchema holds the types shared while the GraphQL schema is built
+type graphSchema struct {
+	server      *Server
+	item        *graphql.Interface
+	objects     map[string]*graphql.Object // Item type of each collection
+	pages       map[string]*graphql.Object // Page type of each item type
+	costs       map[string]graphCost
+	collections *graphql.Enum
+	periods     *graphql.Enum
+	order       *graphql.Enum
+}
+
+// newGraphQLSchema builds the GraphQL schema of the registered collections
+func (s *Server) newGraphQLSchema() (*graphQLSchema, error) {
+	g := &graphSchema{
+		server:  s,
+		objects: make(map[string]*graphql.Object),
+		pages:   make(map[string]*graphql.Object),
+		costs:   make(map[string]graphCost),
+	}
+
+	collections := graphql.EnumValueConfigMap{}
+	for _, c := range collection.All() {
+		info := c.Info()
+		collections[strings.ToUpper(info.Name)] = &graphql.EnumValueConfig{Value: info.Name, Description: capitalize(info.Title)}
+	}
+	g.collections = graphql.NewEnum(graphql.EnumConfig{Name: "CollectionName", Description: "A collection of items", Values: collections})
+	periods := graphql.EnumValueConfigMap{}
+	for _, p := range collection.Periods {
+		periods[strings.ToUpper(string(p))] = &graphql.EnumValueConfig{Value: p}
+	}
+	g.periods = graphql.NewEnum(graphql.EnumConfig{
+		Name:        "Period",
+		Description: "A span of time during which a periodic item stays the same",
+		Values:      periods,
+	})
+	g.order = graphql.NewEnum(graphql.EnumConfig{
+		Name:        "SortOrder",
+		Description: "The direction of a sort",
+		Values: graphql.EnumValueConfigMap{
+			"ASC":  {Description: "Smallest first", Value: false},
+			"DESC": {Description: "Largest first", Value: true},
+		},
+	})
+
+	g.item = graphql.NewInterface(graphql.InterfaceConfig{
+		Name:        "Item",
+		Description: "An item of any collection",
+		Fields:      g.itemFields(),
+		ResolveType: func(p graphql.ResolveTypeParams) *graphql.Object {
+			if gi, ok := p.Value.(graphItem); ok {
+				return g.objects[gi.coll.Info().Name]
+			}
+			return nil
+		},
+	})
+
+	fields := graphql.Fields{}
+	var objects []graphql.Type
+	for _, c := range collection.All() {
+		obj := g.itemObject(c)
+		g.objects[c.Info().Name] = obj
+		objects = append(objects, obj)
+		addFields(fields, g.collectionFields(c, obj))
+	}
+	if animeType, ok := g.objects[anime.Collection.Info().Name]; ok {
+		addFields(fields, g.animeFields(animeType))
+	}
+	addFields(fields, g.globalFields())
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name:        "Query",
+			Description: "Quotes, anime quotes and jokes",
+			Fields:      fields,
+		}),
+		Types: objects,
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.introspectionCosts(&schema)
+	return &graphQLSchema{schema: schema, costs: g.costs}, nil
+}
+
+// introspectionCosts records the costs of the list fields of introspection,
+// each multiplying its subselection by the longest such list in schema, so
+// queries cycling through types and their fields cannot grow unbounded
+func (g *graphSchema) introspectionCosts(schema *graphql.Schema) {
+	widest := make(map[string]int)
+	widen := func(field string, n int) { widest[field] = max(widest[field], n) }
+	widenArgs := func(fields graphql.FieldDefinitionMap) {
+		for _, f := range fields {
+			widen("__Field.args", len(f.Args))
+		}
+	}
+
+	widen("__Schema.types", len(schema.TypeMap()))
+	widen("__Schema.directives", len(schema.Directives()))
+	for _, d := range schema.Directives() {
+		widen("__Directive.args", len(d.Args))
+	}
+	for _, t := range schema.TypeMap() {
+		switch t := t.(type) {
+		case *graphql.Object:
+			widen("__Type.fields", len(t.Fields()))
+			widen("__Type.interfaces", len(t.Interfaces()))
+			widenArgs(t.Fields())
+		case *graphql.Interface:
+			widen("__Type.fields", len(t.Fields()))
+			widen("__Type.possibleTypes", len(schema.PossibleTypes(t)))
+			widenArgs(t.Fields())
+		case *graphql.Union:
+			widen("__Type.possibleTypes", len(t.Types()))
+		case *graphql.Enum:
+			widen("__Type.enumValues", len(t.Values()))
+		case *graphql.InputObject:
+			widen("__Type.inputFields", len(t.Fields()))
+		}
+	}
+
+	for field, n := range widest {
+		typeName, fieldName, _ := strings.Cut(field, ".")
+		g.cost(typeName, fieldName, "", n)
+	}
+}
+
+// addFields adds the fields of src to dst
+func addFields(dst, src graphql.Fields) {
+	for name, f := range src {
+		dst[name] = f
+	}
+}
+
+// mergeArgs returns the arguments of every set, later sets winning
+func mergeArgs(sets ...graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	args := graphql.FieldConfigArgument{}
+	for _, set := range sets {
+		for name, arg := range set {
+			args[name] = arg
+		}
+	}
+	return args
+}
+
+// cost records that the field of typeName multiplies the cost of its
+// subselection by its argument arg, capped at maxLimit
+func (g *graphSchema) cost(typeName, field, arg string, maxLimit int) {
+	g.costs[typeName+"."+field] = graphCost{arg: arg, max: maxLimit}
+}
+
+// graphTypeName turns a display name such as "Chuck Norris joke" into a
+// type name such as "ChuckNorrisJoke"
+func graphTypeName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.Fields(name) {
+		b.WriteString(capitalize(word))
+	}
+	return b.String()
+}
+
+// graphFieldName turns a display name such as "Chuck Norris jokes" into a
+// field name such as "chuckNorrisJokes"
+func graphFieldName(name string) string {
+	typeName := graphTypeName(name)
+	return strings.ToLower(typeName[:1]) + typeName[1:]
+}
+
+// itemOf returns the item of a resolver source
+func itemOf(p graphql.ResolveParams) collection.Item {
+	return p.Source.(graphItem).item
+}
+
+// wrapItems returns the items of c as item object sources
+func wrapItems(c collection.Collection, items []collection.Item) []interface{} {
+	wrapped := make([]interface{}, len(items))
+	for i, item := range items {
+		wrapped[i] = graphItem{coll: c, item: item}
+	}
+	return wrapped
+}
+
+// itemFields returns the fields every item type has
+func (g *graphSchema) itemFields() graphql.Fields {
+	return graphql.Fields{
+		"id": {
+			Description: "ID, unique within the collection",
+			Type:        graphql.NewNonNull(graphql.Int),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return itemOf(p).GetID(), nil
+			},
+		},
+		"text": {
+			Description: "Main text",
+			Type:        graphql.NewNonNull(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				gi := p.Source.(graphItem)
+				return gi.item.Field(gi.coll.Info().TextField), nil
+			},
+		},
+		"collection": {
+			Description: "Collection the item belongs to",
+			Type:        graphql.NewNonNull(g.collections),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(graphItem).coll.Info().Name, nil
+			},
+		},
+		"attribution": {
+			Description: "Who the item is attributed to, or an empty string",
+			Type:        graphql.NewNonNull(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				gi := p.Source.(graphItem)
+				return gi.coll.Info().AttributionOf(gi.item), nil
+			},
+		},
+	}
+}
+
+// itemObject returns the object type of the items of c, with a field for
+// the text and every filterable field. The anime and character of anime
+// quotes are objects of their own.
+func (g *graphSchema) itemObject(c collection.Collection) *graphql.Object {
+	info := c.Info()
+	fields := g.itemFields()
+
+	names := []string{info.TextField}
+	for _, f := range info.Fields {
+		names = append(names, f.Name)
+	}
+	for _, name := range names {
+		if c == collection.Collection(anime.Collection) && (name == "anime" || name == "character") {
+			continue
+		}
+		fields[name] = &graphql.Field{
+			Type: graphql.NewNonNull(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return itemOf(p).Field(name), nil
+			},
+		}
+	}
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name:        graphTypeName(info.ItemName),
+		Description: capitalize(info.ItemName),
+		Interfaces:  []*graphql.Interface{g.item},
+		Fields:      fields,
+	})
+}
+
+// pageArgs returns the limit and offset arguments of a paginated field
+func pageArgs(defaultLimit int) graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"limit":  {Description: "Maximum number of results", Type: graphql.Int, DefaultValue: defaultLimit},
+		"offset": {Description: "Number of results to skip", Type: graphql.Int, DefaultValue: 0},
+	}
+}
+
+// pageBounds reads the limit and offset arguments, capping limit at maxLimit
+func pageBounds(args map[string]interface{}, maxLimit int) (int, int, error) {
+	limit, _ := args["limit"].(int)
+	if limit < 1 {
+		return 0, 0, errors.New("limit must be a positive integer")
+	}
+	offset, _ := args["offset"].(int)
+	if offset < 0 {
+		return 0, 0, errors.New("offset must be a non-negative integer")
+	}
+	return min(limit, maxLimit), offset, nil
+}
+
+// newGraphPage returns the page of values between offset and offset+limit
+func newGraphPage[T any](values []T, limit, offset int, wrap func(T) interface{}) *graphPage {
+	start := min(offset, len(values))
+	end := min(start+limit, len(values))
+	page := &graphPage{total: len(values), limit: limit, offset: offset, items: make([]interface{}, 0, end-start)}
+	for _, v := range values[start:end] {
+		page.items = append(page.items, wrap(v))
+	}
+	return page
+}
+
+// pageFields returns the fields every page type has
+func pageFields() graphql.Fields {
+	page := func(p graphql.ResolveParams) *graphPage { return p.Source.(*graphPage) }
+	return graphql.Fields{
+		"total": {
+			Description: "Number of results across all pages",
+			Type:        graphql.NewNonNull(graphql.Int),
+			Resolve:     func(p graphql.ResolveParams) (interface{}, error) { return page(p).total, nil },
+		},
+		"limit": {
+			Description: "Maximum number of results of the page",
+			Type:        graphql.NewNonNull(graphql.Int),
+			Resolve:     func(p graphql.ResolveParams) (interface{}, error) { return page(p).limit, nil },
+		},
+		"offset": {
+			Description: "Number of results before the page",
+			Type:        graphql.NewNonNull(graphql.Int),
+			Resolve:     func(p graphql.ResolveParams) (interface{}, error) { return page(p).offset, nil },
+		},
+		"hasMore": {
+			Description: "Whether results follow the page",
+			Type:        graphql.NewNonNull(graphql.Boolean),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return page(p).offset+len(page(p).items) < page(p).total, nil
+			},
+		},
+	}
+}
+
+// pageType returns the type of a page listing values of type t in the
+// field named list
+func pageType(name, description, list string, t graphql.Type) *graphql.Object {
+	fields := pageFields()
+	if list == "results" {
+		fields["query"] = &graphql.Field{
+			Description: "The search query",
+			Type:        graphql.NewNonNull(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*graphPage).query, nil
+			},
+		}
+	}
+	fields[list] = &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t))),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphPage).items, nil
+		},
+	}
+	return graphql.NewObject(graphql.ObjectConfig{Name: name, Description: description, Fields: fields})
+}
+
+// searchResultType returns the type of a search hit whose item has type t
+func searchResultType(name string, t graphql.Output, collections *graphql.Enum) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name:        name,
+		Description: "A ranked search hit",
+		Fields: graphql.Fields{
+			"collection": {
+				Description: "Collection of the item",
+				Type:        graphql.NewNonNull(collections),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(searchHit).collection.Info().Name, nil
+				},
+			},
+			"score": {
+				Description: "Relevance; higher is better",
+				Type:        graphql.NewNonNull(graphql.Float),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(searchHit).hit.Score, nil
+				},
+			},
+			"snippet": {
+				Description: "Excerpt of the text around the matched terms",
+				Type:        graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(searchHit).result().Snippet, nil
+				},
+			},
+			"item": {
+				Type: graphql.NewNonNull(t),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					h := p.Source.(searchHit)
+					return graphItem{coll: h.collection, item: h.hit.Item}, nil
+				},
+			},
+		},
+	})
+}
+
+// searchPage runs a search and returns the requested page of hits
+func searchPage(collections []collection.Collection, args map[string]interface{}) (*graphPage, error) {
+	query := strings.TrimSpace(args["q"].(string))
+	if query == "" {
+		return nil, errors.New("q must not be empty")
+	}
+	limit, offset, err := pageBounds(args, maxSearchLimit)
+	if err != nil {
+		return nil, err
+	}
+	page := newGraphPage(rankedHits(collections, query), limit, offset, func(h searchHit) interface{} { return h })
+	page.query = query
+	return page, nil
+}
+
+// filterArgs returns the filter arguments of the fields selecting items of a collection
+func filterArgs(info collection.Info) graphql.FieldConfigArgument {
+	args := graphql.FieldConfigArgument{
+		"minLength":  {Description: "Minimum text length in characters", Type: graphql.Int},
+		"maxLength":  {Description: "Maximum text length in characters", Type: graphql.Int},
+		"excludeIds": {Description: "IDs to leave out", Type: graphql.NewList(graphql.NewNonNull(graphql.Int))},
+	}
+	for _, f := range info.Fields {
+		args[f.Name] = &graphql.ArgumentConfig{
+			Description: fmt.Sprintf("Only %s with this %s", info.Title, f.Name),
+			Type:        graphql.String,
+		}
+	}
+	return args
+}
+
+// filterFromArgs reads the filter arguments of a field
+func filterFromArgs(args map[string]interface{}, info collection.Info) (collection.Filter, error) {
+	var f collection.Filter
+
+	for _, field := range info.Fields {
+		if v, _ := args[field.Name].(string); v != "" {
+			if f.Fields == nil {
+				f.Fields = make(map[string]string)
+			}
+			f.Fields[field.Name] = v
+		}
+	}
+
+	if v, ok := args["minLength"].(int); ok {
+		if v < 0 {
+			return f, errors.New("minLength must be a non-negative integer")
+		}
+		f.MinLength = v
+	}
+	if v, ok := args["maxLength"].(int); ok {
+		if v < 0 {
+			return f, errors.New("maxLength must be a non-negative integer")
+		}
+		f.MaxLength = v
+	}
+	if f.MaxLength > 0 && f.MinLength > f.MaxLength {
+		return f, errors.New("minLength must not be greater than maxLength")
+	}
+
+	if ids, _ := args["excludeIds"].([]interface{}); len(ids) > 0 {
+		f.ExcludeIDs = make(map[int]bool, len(ids))
+		for _, id := range ids {
+			f.ExcludeIDs[id.(int)] = true
+		}
+	}
+
+	return f, nil
+}
+
+// collectionFields returns the query fields listing, fetching, picking and
+// searching the items of c, whose object type is obj
+func (g *graphSchema) collectionFields(c collection.Collection, obj *graphql.Object) graphql.Fields {
+	info := c.Info()
+	plural := graphFieldName(info.Title)
+	singular := graphFieldName(info.ItemName)
+
+	sortKeys := graphql.EnumValueConfigMap{}
+	for _, key := range info.SortKeys() {
+		sortKeys[strings.ToUpper(key)] = &graphql.EnumValueConfig{Value: key}
+	}
+	sortField := graphql.NewEnum(graphql.EnumConfig{
+		Name:        obj.Name() + "SortField",
+		Description: "A key " + info.Title + " can be sorted by",
+		Values:      sortKeys,
+	})
+
+	listArgs := mergeArgs(filterArgs(info), pageArgs(defaultPageLimit), graphql.FieldConfigArgument{
+		"sort":  {Description: "Key to sort by", Type: sortField, DefaultValue: "id"},
+		"order": {Description: "Sort direction", Type: g.order, DefaultValue: false},
+	})
+
+	randomArgs := mergeArgs(filterArgs(info), graphql.FieldConfigArgument{
+		"count":    {Description: fmt.Sprintf("Number of distinct items, at most %d", maxRandomCount), Type: graphql.Int, DefaultValue: 1},
+		"seed":     {Description: "Seed making the pick repeatable, a non-negative integer", Type: graphql.String},
+		"weighted": {Description: "Draw items in proportion to their weights", Type: graphql.Boolean},
+	})
+
+	periodicArgs := mergeArgs(filterArgs(info), graphql.FieldConfigArgument{
+		"period": {Description: "Period the item stays the same for", Type: g.periods, DefaultValue: collection.Daily},
+		"tz":     {Description: "IANA timezone the period is reckoned in, UTC by default", Type: graphql.String},
+		"date":   {Description: "A time in the period, as YYYY-MM-DD, YYYY-MM-DDTHH or RFC 3339; now by default", Type: graphql.String},
+	})
+
+	page := pageType(obj.Name()+"Page", "A page of "+info.Title, "items", obj)
+	g.pages[obj.Name()] = page
+
+	searchArgs := mergeArgs(graphql.FieldConfigArgument{
+		"q": {Description: "Full-text query", Type: graphql.NewNonNull(graphql.String)},
+	}, pageArgs(defaultSearchLimit))
+
+	random := "random" + capitalize(plural)
+	search := "search" + capitalize(plural)
+	g.cost("Query", plural, "limit", maxPageLimit)
+	g.cost("Query", random, "count", maxRandomCount)
+	g.cost("Query", search, "limit", maxSearchLimit)
+
+	return graphql.Fields{
+		plural: {
+			Description: fmt.Sprintf("Lists %s", info.Title),
+			Type:        graphql.NewNonNull(page),
+			Args:        listArgs,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				filter, err := filterFromArgs(p.Args, info)
+				if err != nil {
+					return nil, err
+				}
+				limit, offset, err := pageBounds(p.Args, maxPageLimit)
+				if err != nil {
+					return nil, err
+				}
+
+				items := c.Items()
+				if !filter.IsEmpty() {
+					items = collection.FilterItems(c, filter)
+				}
+				if key, desc := p.Args["sort"].(string), p.Args["order"].(bool); key != "id" || desc {
+					if items, err = collection.SortItems(info, items, key, desc); err != nil {
+						return nil, err
+					}
+				}
+				return newGraphPage(items, limit, offset, func(item collection.Item) interface{} {
+					return graphItem{coll: c, item: item}
+				}), nil
+			},
+		},
+		singular: {
+			Description: fmt.Sprintf("Returns the %s with an ID, or null", info.ItemName),
+			Type:        obj,
+			Args:        graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.Int)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				item, err := c.ItemByID(p.Args["id"].(int))
+				if err != nil {
+					return nil, nil
+				}
+				return graphItem{coll: c, item: item}, nil
+			},
+		},
+		random: {
+			Description: fmt.Sprintf("Picks distinct random %s", info.Title),
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(obj))),
+			Args:        randomArgs,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return g.randomItems(c, p.Args)
+			},
+		},
+		"periodic" + capitalize(singular): {
+			Description: fmt.Sprintf("Returns the %s of the current, or requested, hour, day or week", info.ItemName),
+			Type:        obj,
+			Args:        periodicArgs,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				filter, err := filterFromArgs(p.Args, info)
+				if err != nil {
+					return nil, err
+				}
+				q := url.Values{}
+				for _, name := range []string{"tz", "date"} {
+					if v, ok := p.Args[name].(string); ok {
+						q.Set(name, v)
+					}
+				}
+				t, err := periodTimeFromValues(q)
+				if err != nil {
+					return nil, err
+				}
+
+				item, err := collection.PeriodicMatch(c, filter, p.Args["period"].(collection.Period), t)
+				if errors.Is(err, collection.ErrNoMatch) {
+					return nil, nil
+				}
+				if err != nil {
+					return nil, err
+				}
+				return graphItem{coll: c, item: item}, nil
+			},
+		},
+		search: {
+			Description: fmt.Sprintf("Searches the text of %s, best matches first", info.Title),
+			Type: graphql.NewNonNull(pageType(obj.Name()+"SearchPage", "A page of "+info.ItemName+" search results", "results",
+				searchResultType(obj.Name()+"SearchResult", obj, g.collections))),
+			Args: searchArgs,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return searchPage([]collection.Collection{c}, p.Args)
+			},
+		},
+	}
+}
+
+// randomItems picks random items like the random endpoint. Without a seed,
+// weighted picks avoid recently served items.
+func (g *graphSchema) randomItems(c collection.Collection, args map[string]interface{}) (interface{}, error) {
+	info := c.Info()
+	filter, err := filterFromArgs(args, info)
+	if err != nil {
+		return nil, err
+	}
+
+	count, _ := args["count"].(int)
+	if count < 1 || count > maxRandomCount {
+		return nil, fmt.Errorf("count must be between 1 and %d", maxRandomCount)
+	}
+
+	seed := collection.NewSeed()
+	seeded := false
+	if v, ok := args["seed"].(string); ok {
+		if seed, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return nil, errors.New("seed must be a non-negative integer")
+		}
+		seeded = true
+	}
+	weighted := ""
+	if v, ok := args["weighted"].(bool); ok {
+		weighted = strconv.FormatBool(v)
+	}
+
+	var items []collection.Item
+	rng := collection.NewSeededRand(seed)
+	if g.server.weightedParam(weighted) {
+		items, err = g.server.weights.RandomItems(c, filter, count, rng, !seeded)
+	} else {
+		items, err = collection.RandomItems(c, filter, count, rng)
+	}
+	if errors.Is(err, collection.ErrNoMatch) {
+		return nil, fmt.Errorf("No %s match the filters", info.Title)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return wrapItems(c, items), nil
+}
+
+// animeQuotes returns the quotes of an anime, of one character when
+// character is not empty
+func animeQuotes(name, character string) []collection.Item {
+	filter := collection.Filter{Fields: map[string]string{"anime": name}}
+	if character != "" {
+		filter.Fields["character"] = character
+	}
+	return collection.FilterItems(anime.Collection, filter)
+}
+
+// charactersOf returns the characters quoted in items, most quoted first
+func charactersOf(items []collection.Item) []characterEntity {
+	counts := make(map[characterEntity]int)
+	var characters []characterEntity
+	for _, item := range items {
+		ch := characterEntity{name: item.Field("character"), anime: item.Field("anime")}
+		if counts[ch] == 0 {
+			characters = append(characters, ch)
+		}
+		counts[ch]++
+	}
+
+	sort.SliceStable(characters, func(i, j int) bool {
+		a, b := characters[i], characters[j]
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		return strings.ToLower(a.name) < strings.ToLower(b.name)
+	})
+	return characters
+}
+
+// animeFields returns the query fields of anime and their characters, and
+// adds the anime and character fields to the anime quote type
+func (g *graphSchema) animeFields(quoteType *graphql.Object) graphql.Fields {
+	quotePage := g.pages[quoteType.Name()]
+	quotesField := func(quotes func(p graphql.ResolveParams) []collection.Item) *graphql.Field {
+		return &graphql.Field{
+			Description: "Quotes, in ID order",
+			Type:        graphql.NewNonNull(quotePage),
+			Args:        pageArgs(defaultPageLimit),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				limit, offset, err := pageBounds(p.Args, maxPageLimit)
+				if err != nil {
+					return nil, err
+				}
+				return newGraphPage(quotes(p), limit, offset, func(item collection.Item) interface{} {
+					return graphItem{coll: anime.Collection, item: item}
+				}), nil
+			},
+		}
+	}
+	wrapCharacter := func(ch characterEntity) interface{} { return ch }
+
+	animeType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Anime",
+		Description: "An anime quoted in the anime quotes",
+		Fields:      graphql.Fields{},
+	})
+	characterType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Character",
+		Description: "A character of an anime quoted in the anime quotes",
+		Fields:      graphql.Fields{},
+	})
+	characterPage := pageType("CharacterPage", "A page of characters", "items", characterType)
+
+	animeType.AddFieldConfig("name", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.String),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(animeEntity).name, nil
+		},
+	})
+	animeType.AddFieldConfig("quoteCount", &graphql.Field{
+		Description: "Number of quotes from the anime",
+		Type:        graphql.NewNonNull(graphql.Int),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return len(anime.Collection.ItemsByField("anime", p.Source.(animeEntity).name)), nil
+		},
+	})
+	animeType.AddFieldConfig("characters", &graphql.Field{
+		Description: "Characters quoted, most quoted first",
+		Type:        graphql.NewNonNull(characterPage),
+		Args:        pageArgs(defaultPageLimit),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			limit, offset, err := pageBounds(p.Args, maxPageLimit)
+			if err != nil {
+				return nil, err
+			}
+			items := anime.Collection.ItemsByField("anime", p.Source.(animeEntity).name)
+			return newGraphPage(charactersOf(items), limit, offset, wrapCharacter), nil
+		},
+	})
+	animeType.AddFieldConfig("quotes", quotesField(func(p graphql.ResolveParams) []collection.Item {
+		return anime.Collection.ItemsByField("anime", p.Source.(animeEntity).name)
+	}))
+	g.cost("Anime", "characters", "limit", maxPageLimit)
+	g.cost("Anime", "quotes", "limit", maxPageLimit)
+
+	characterType.AddFieldConfig("name", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.String),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(characterEntity).name, nil
+		},
+	})
+	characterType.AddFieldConfig("anime", &graphql.Field{
+		Description: "Anime the character appears in",
+		Type:        graphql.NewNonNull(animeType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return animeEntity{name: p.Source.(characterEntity).anime}, nil
+		},
+	})
+	characterType.AddFieldConfig("quoteCount", &graphql.Field{
+		Description: "Number of quotes from the character",
+		Type:        graphql.NewNonNull(graphql.Int),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			ch := p.Source.(characterEntity)
+			return len(animeQuotes(ch.anime, ch.name)), nil
+		},
+	})
+	characterType.AddFieldConfig("quotes", quotesField(func(p graphql.ResolveParams) []collection.Item {
+		ch := p.Source.(characterEntity)
+		return animeQuotes(ch.anime, ch.name)
+	}))
+	g.cost("Character", "quotes", "limit", maxPageLimit)
+
+	quoteType.AddFieldConfig("anime", &graphql.Field{
+		Description: "Anime the quote is from",
+		Type:        graphql.NewNonNull(animeType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return animeEntity{name: itemOf(p).Field("anime")}, nil
+		},
+	})
+	quoteType.AddFieldConfig("character", &graphql.Field{
+		Description: "Character who says the quote",
+		Type:        graphql.NewNonNull(characterType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			item := itemOf(p)
+			return characterEntity{name: item.Field("character"), anime: item.Field("anime")}, nil
+		},
+	})
+
+	g.cost("Query", "allAnime", "limit", maxPageLimit)
+	g.cost("Query", "characters", "limit", maxPageLimit)
+	return graphql.Fields{
+		"anime": {
+			Description: "Returns an anime by name, or null",
+			Type:        animeType,
+			Args:        graphql.FieldConfigArgument{"name": {Type: graphql.NewNonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				items := anime.Collection.ItemsByField("anime", p.Args["name"].(string))
+				if len(items) == 0 {
+					return nil, nil
+				}
+				return animeEntity{name: items[0].Field("anime")}, nil
+			},
+		},
+		"allAnime": {
+			Description: "Lists anime, most quoted first",
+			Type:        graphql.NewNonNull(pageType("AnimePage", "A page of anime", "items", animeType)),
+			Args:        pageArgs(defaultPageLimit),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				limit, offset, err := pageBounds(p.Args, maxPageLimit)
+				if err != nil {
+					return nil, err
+				}
+				return newGraphPage(anime.Collection.Facets("anime"), limit, offset, func(f collection.Facet) interface{} {
+					return animeEntity{name: f.Value}
+				}), nil
+			},
+		},
+		"character": {
+			Description: "Returns a character by name, or null. Without an anime, the most quoted character of that name is returned.",
+			Type:        characterType,
+			Args: graphql.FieldConfigArgument{
+				"name":  {Type: graphql.NewNonNull(graphql.String)},
+				"anime": {Description: "Anime the character appears in", Type: graphql.String},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				filter := collection.Filter{Fields: map[string]string{"character": p.Args["name"].(string)}}
+				if name, _ := p.Args["anime"].(string); name != "" {
+					filter.Fields["anime"] = name
+				}
+				characters := charactersOf(collection.FilterItems(anime.Collection, filter))
+				if len(characters) == 0 {
+					return nil, nil
+				}
+				return characters[0], nil
+			},
+		},
+		"characters": {
+			Description: "Lists characters, most quoted first",
+			Type:        graphql.NewNonNull(characterPage),
+			Args: mergeArgs(graphql.FieldConfigArgument{
+				"anime": {Description: "Only characters of this anime", Type: graphql.String},
+			}, pageArgs(defaultPageLimit)),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				limit, offset, err := pageBounds(p.Args, maxPageLimit)
+				if err != nil {
+					return nil, err
+				}
+				items := anime.Collection.Items()
+				if name, _ := p.Args["anime"].(string); name != "" {
+					items = anime.Collection.ItemsByField("anime", name)
+				}
+				return newGraphPage(charactersOf(items), limit, offset, wrapCharacter), nil
+			},
+		},
+	}
+}
+
+// globalFields returns the query fields spanning every collection
+func (g *graphSchema) globalFields() graphql.Fields {
+	facetType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Facet",
+		Description: "A distinct field value and the number of items that have it",
+		Fields: graphql.Fields{
+			"value": {
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(collection.Facet).Value, nil
+				},
+			},
+			"count": {
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(collection.Facet).Count, nil
+				},
+			},
+		},
+	})
+
+	collectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Collection",
+		Description: "A collection and what can be queried in it",
+		Fields: graphql.Fields{
+			"name": {
+				Type: graphql.NewNonNull(g.collections),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(collection.Collection).Info().Name, nil
+				},
+			},
+			"title": {
+				Description: "Plural display name",
+				Type:        graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(collection.Collection).Info().Title, nil
+				},
+			},
+			"itemName": {
+				Description: "Singular display name",
+				Type:        graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(collection.Collection).Info().ItemName, nil
+				},
+			},
+			"count": {
+				Description: "Number of items",
+				Type:        graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(collection.Collection).Count(), nil
+				},
+			},
+			"fields": {
+				Description: "Filterable fields",
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var names []string
+					for _, f := range p.Source.(collection.Collection).Info().Fields {
+						names = append(names, f.Name)
+					}
+					return names, nil
+				},
+			},
+			"facets": {
+				Description: "Distinct values of a filterable field, most common first",
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(facetType))),
+				Args: graphql.FieldConfigArgument{
+					"field": {Type: graphql.NewNonNull(graphql.String)},
+					"limit": {Description: "Maximum number of values", Type: graphql.Int, DefaultValue: defaultPageLimit},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					c := p.Source.(collection.Collection)
+					field, err := lookupField(c, p.Args["field"].(string))
+					if err != nil {
+						return nil, err
+					}
+					limit, _ := p.Args["limit"].(int)
+					if limit < 1 {
+						return nil, errors.New("limit must be a positive integer")
+					}
+					facets := c.Facets(field.Name)
+					return facets[:min(limit, len(facets))], nil
+				},
+			},
+		},
+	})
+	// The limit of facets is not capped, so it counts in full
+	g.cost("Collection", "facets", "limit", math.MaxInt32)
+	g.cost("Query", "collections", "", len(collection.All()))
+	g.cost("Query", "search", "limit", maxSearchLimit)
+
+	searchResult := searchResultType("SearchResult", g.item, g.collections)
+	return graphql.Fields{
+		"collections": {
+			Description: "Lists the collections",
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(collectionType))),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return collection.All(), nil
+			},
+		},
+		"collection": {
+			Description: "Returns a collection by name",
+			Type:        graphql.NewNonNull(collectionType),
+			Args:        graphql.FieldConfigArgument{"name": {Type: graphql.NewNonNull(g.collections)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				c, _ := collection.Get(p.Args["name"].(string))
+				return c, nil
+			},
+		},
+		"search": {
+			Description: "Searches the text of every collection, or of the listed ones, best matches first",
+			Type:        graphql.NewNonNull(pageType("SearchPage", "A page of search results", "results", searchResult)),
+			Args: mergeArgs(graphql.FieldConfigArgument{
+				"q":           {Description: "Full-text query", Type: graphql.NewNonNull(graphql.String)},
+				"collections": {Description: "Collections to search, all by default", Type: graphql.NewList(graphql.NewNonNull(g.collections))},
+			}, pageArgs(defaultSearchLimit)),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				collections := collection.All()
+				if names, ok := p.Args["collections"].([]interface{}); ok {
+					collections = nil
+					for _, name := range names {
+						c, _ := collection.Get(name.(string))
+						collections = append(collections, c)
+					}
+				}
+				return searchPage(collections, p.Args)
+			},
+		},
+	}
+}
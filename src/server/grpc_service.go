@@ -0,0 +1,354 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/apimgr/quotes/src/database"
+	quotesv1 "github.com/apimgr/quotes/src/proto/quotes/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// grpcPeriods maps the values of the Period enum to periods; unspecified means daily
+var grpcPeriods = map[quotesv1.Period]collection.Period{
+	quotesv1.Period_PERIOD_UNSPECIFIED: collection.Daily,
+	quotesv1.Period_PERIOD_HOURLY:      collection.Hourly,
+	quotesv1.Period_PERIOD_DAILY:       collection.Daily,
+	quotesv1.Period_PERIOD_WEEKLY:      collection.Weekly,
+}
+
+// quotesService implements the QuotesService of the gRPC API
+type quotesService struct {
+	quotesv1.UnimplementedQuotesServiceServer
+	s *Server
+}
+
+// newGRPCServer returns the gRPC server with the QuotesService, health
+// checking and server reflection. When the grpc.require_auth setting is on,
+// QuotesService calls need an admin token.
+func (s *Server) newGRPCServer() (*grpc.Server, *health.Server) {
+	var opts []grpc.ServerOption
+	if s.settingsCache["grpc.require_auth"].(bool) {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				if err := grpcAuthorize(ctx, info.FullMethod); err != nil {
+					return nil, err
+				}
+				return handler(ctx, req)
+			}),
+			grpc.ChainStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				if err := grpcAuthorize(ss.Context(), info.FullMethod); err != nil {
+					return err
+				}
+				return handler(srv, ss)
+			}),
+		)
+	}
+
+	srv := grpc.NewServer(opts...)
+	quotesv1.RegisterQuotesServiceServer(srv, &quotesService{s: s})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(quotesv1.QuotesService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthServer)
+	reflection.Register(srv)
+	return srv, healthServer
+}
+
+// grpcAuthorize accepts QuotesService calls whose authorization metadata
+// holds a valid admin token, the same tokens the admin API takes. Health
+// checks and reflection stay open.
+func grpcAuthorize(ctx context.Context, method string) error {
+	if !strings.HasPrefix(method, "/"+quotesv1.QuotesService_ServiceDesc.ServiceName+"/") {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	var parts []string
+	if values := md.Get("authorization"); len(values) > 0 {
+		parts = strings.Split(values[0], " ")
+	}
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return status.Errorf(codes.Unauthenticated, "missing or invalid authorization metadata")
+	}
+	if _, err := database.ValidateAdminToken(parts[1]); err != nil {
+		return status.Errorf(codes.Unauthenticated, "invalid or expired token")
+	}
+	return nil
+}
+
+// grpcCollection returns a collection by name, quotes when name is empty
+func grpcCollection(name string) (collection.Collection, error) {
+	if name == "" {
+		name = "quotes"
+	}
+	c, ok := collection.Get(name)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown collection %q", name)
+	}
+	return c, nil
+}
+
+// grpcFilter converts a Filter message, validating it like the filter
+// parameters of the REST API
+func grpcFilter(f *quotesv1.Filter, c collection.Collection) (collection.Filter, error) {
+	q := url.Values{}
+	for key, value := range f.GetFields() {
+		field, err := lookupField(c, key)
+		if err != nil {
+			return collection.Filter{}, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		q.Set(field.Name, value)
+	}
+	if f.GetMinLength() != 0 {
+		q.Set("min_length", strconv.Itoa(int(f.GetMinLength())))
+	}
+	if f.GetMaxLength() != 0 {
+		q.Set("max_length", strconv.Itoa(int(f.GetMaxLength())))
+	}
+	if len(f.GetExcludeIds()) > 0 {
+		ids := make([]string, len(f.GetExcludeIds()))
+		for i, id := range f.GetExcludeIds() {
+			ids[i] = strconv.Itoa(int(id))
+		}
+		q.Set("exclude_ids", strings.Join(ids, ","))
+	}
+
+	filter, err := filterFromValues(q, c.Info())
+	if err != nil {
+		return filter, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return filter, nil
+}
+
+// grpcItem returns an item of c as an Item message
+func grpcItem(c collection.Collection, item collection.Item) *quotesv1.Item {
+	info := c.Info()
+	fields := make(map[string]string)
+	for _, f := range info.Fields {
+		if v := item.Field(f.Name); v != "" {
+			fields[f.Name] = v
+		}
+	}
+	return &quotesv1.Item{
+		Id:          int32(item.GetID()),
+		Collection:  info.Name,
+		Text:        item.Field(info.TextField),
+		Fields:      fields,
+		Attribution: info.AttributionOf(item),
+	}
+}
+
+// Random picks random items like the random endpoint. Without a seed,
+// weighted picks avoid recently served items.
+func (q *quotesService) Random(ctx context.Context, req *quotesv1.RandomRequest) (*quotesv1.RandomResponse, error) {
+	c, err := grpcCollection(req.GetCollection())
+	if err != nil {
+		return nil, err
+	}
+	info := c.Info()
+	filter, err := grpcFilter(req.GetFilter(), c)
+	if err != nil {
+		return nil, err
+	}
+	count := req.GetCount()
+	if count == 0 {
+		count = 1
+	}
+	if count < 1 || count > maxRandomCount {
+		return nil, status.Errorf(codes.InvalidArgument, "count must be between 1 and %d", maxRandomCount)
+	}
+
+	seed := collection.NewSeed()
+	if req.Seed != nil {
+		seed = req.GetSeed()
+	}
+	weighted := ""
+	if req.Weighted != nil {
+		weighted = strconv.FormatBool(req.GetWeighted())
+	}
+
+	var items []collection.Item
+	rng := collection.NewSeededRand(seed)
+	if q.s.weightedParam(weighted) {
+		items, err = q.s.weights.RandomItems(c, filter, int(count), rng, req.Seed == nil)
+	} else {
+		items, err = collection.RandomItems(c, filter, int(count), rng)
+	}
+	if errors.Is(err, collection.ErrNoMatch) {
+		return nil, status.Errorf(codes.NotFound, "No %s match the filters", info.Title)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	resp := &quotesv1.RandomResponse{Seed: seed}
+	for _, item := range items {
+		resp.Items = append(resp.Items, grpcItem(c, item))
+	}
+	return resp, nil
+}
+
+// Get returns an item by ID
+func (q *quotesService) Get(ctx context.Context, req *quotesv1.GetRequest) (*quotesv1.Item, error) {
+	c, err := grpcCollection(req.GetCollection())
+	if err != nil {
+		return nil, err
+	}
+	item, err := c.ItemByID(int(req.GetId()))
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	}
+	return grpcItem(c, item), nil
+}
+
+// List streams the items of a collection passing the filter, sorted and
+// paginated. A limit of 0 streams every item after the offset.
+func (q *quotesService) List(req *quotesv1.ListRequest, stream quotesv1.QuotesService_ListServer) error {
+	c, err := grpcCollection(req.GetCollection())
+	if err != nil {
+		return err
+	}
+	info := c.Info()
+	filter, err := grpcFilter(req.GetFilter(), c)
+	if err != nil {
+		return err
+	}
+	limit, offset := int(req.GetLimit()), int(req.GetOffset())
+	if limit < 0 || offset < 0 {
+		return status.Errorf(codes.InvalidArgument, "limit and offset must be non-negative integers")
+	}
+
+	items := c.Items()
+	if !filter.IsEmpty() {
+		items = collection.FilterItems(c, filter)
+	}
+	sortKey := req.GetSort()
+	if sortKey == "" {
+		sortKey = "id"
+	}
+	if sortKey != "id" || req.GetDescending() {
+		if items, err = collection.SortItems(info, items, sortKey, req.GetDescending()); err != nil {
+			return status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+	}
+
+	items = items[min(offset, len(items)):]
+	if limit > 0 {
+		items = items[:min(limit, len(items))]
+	}
+	for _, item := range items {
+		if err := stream.Send(grpcItem(c, item)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Search runs a ranked full-text search over every collection, or the
+// requested ones
+func (q *quotesService) Search(ctx context.Context, req *quotesv1.SearchRequest) (*quotesv1.SearchResponse, error) {
+	query := strings.TrimSpace(req.GetQuery())
+	if query == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "query must not be empty")
+	}
+	limit, offset := int(req.GetLimit()), int(req.GetOffset())
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit < 1 || limit > maxSearchLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxSearchLimit)
+	}
+	if offset < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "offset must be a non-negative integer")
+	}
+
+	collections := collection.All()
+	if len(req.GetCollections()) > 0 {
+		collections = nil
+		for _, name := range req.GetCollections() {
+			c, err := grpcCollection(name)
+			if err != nil {
+				return nil, err
+			}
+			collections = append(collections, c)
+		}
+	}
+
+	hits := rankedHits(collections, query)
+	resp := &quotesv1.SearchResponse{Total: int32(len(hits))}
+	hits = hits[min(offset, len(hits)):]
+	for _, h := range hits[:min(limit, len(hits))] {
+		result := h.result()
+		resp.Results = append(resp.Results, &quotesv1.SearchResult{
+			Collection: result.Collection,
+			Score:      result.Score,
+			Snippet:    result.Snippet,
+			Item:       grpcItem(h.collection, result.Item),
+		})
+	}
+	return resp, nil
+}
+
+// Daily returns the item of the current, or requested, hour, day or week,
+// like the daily, hourly and weekly endpoints
+func (q *quotesService) Daily(ctx context.Context, req *quotesv1.DailyRequest) (*quotesv1.DailyResponse, error) {
+	c, err := grpcCollection(req.GetCollection())
+	if err != nil {
+		return nil, err
+	}
+	info := c.Info()
+	period, ok := grpcPeriods[req.GetPeriod()]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown period %d", req.GetPeriod())
+	}
+	filter, err := grpcFilter(req.GetFilter(), c)
+	if err != nil {
+		return nil, err
+	}
+	values := url.Values{}
+	if req.GetTimezone() != "" {
+		values.Set("tz", req.GetTimezone())
+	}
+	if req.GetDate() != "" {
+		values.Set("date", req.GetDate())
+	}
+	t, err := periodTimeFromValues(values)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	item, err := collection.PeriodicMatch(c, filter, period, t)
+	if errors.Is(err, collection.ErrNoMatch) {
+		return nil, status.Errorf(codes.NotFound, "No %s match the filters", info.Title)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	resp := &quotesv1.DailyResponse{
+		Collection: info.Name,
+		Period:     req.GetPeriod(),
+		Key:        period.Key(t),
+		Timezone:   t.Location().String(),
+		Item:       grpcItem(c, item),
+	}
+	if resp.Period == quotesv1.Period_PERIOD_UNSPECIFIED {
+		resp.Period = quotesv1.Period_PERIOD_DAILY
+	}
+	start, end := period.Bounds(t)
+	resp.StartsAt = start.Format(time.RFC3339)
+	resp.EndsAt = end.Format(time.RFC3339)
+	return resp, nil
+}
@@ -0,0 +1,278 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	quotesv1 "github.com/apimgr/quotes/src/proto/quotes/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// grpcClient serves the gRPC API of the test server in memory and returns
+// a connection to it
+func grpcClient(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	ln := bufconn.Listen(1 << 20)
+	srv, _ := testServer.newGRPCServer()
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// wantCode fails the test unless err carries the status code want
+func wantCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Errorf("status %v (%v), want %v", got, err, want)
+	}
+}
+
+func TestGRPCRandom(t *testing.T) {
+	client := quotesv1.NewQuotesServiceClient(grpcClient(t))
+	ctx := context.Background()
+
+	// Author 1 has nine courage quotes
+	req := &quotesv1.RandomRequest{
+		Filter: &quotesv1.Filter{Fields: map[string]string{"author": "Author 1", "category": "courage"}},
+		Count:  3,
+		Seed:   proto.Uint64(42),
+	}
+	resp, err := client.Random(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Items) != 3 || resp.Seed != 42 {
+		t.Fatalf("response %v", resp)
+	}
+	for _, item := range resp.Items {
+		if item.Collection != "quotes" || item.Fields["author"] != "Author 1" || item.Fields["category"] != "courage" {
+			t.Errorf("item %v does not match the filter", item)
+		}
+	}
+
+	again, err := client.Random(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(resp, again) {
+		t.Errorf("seeded picks differ: %v and %v", resp, again)
+	}
+
+	_, err = client.Random(ctx, &quotesv1.RandomRequest{Count: maxRandomCount + 1})
+	wantCode(t, err, codes.InvalidArgument)
+	_, err = client.Random(ctx, &quotesv1.RandomRequest{Filter: &quotesv1.Filter{Fields: map[string]string{"nope": "x"}}})
+	wantCode(t, err, codes.InvalidArgument)
+	_, err = client.Random(ctx, &quotesv1.RandomRequest{Filter: &quotesv1.Filter{Fields: map[string]string{"author": "Nobody"}}})
+	wantCode(t, err, codes.NotFound)
+	_, err = client.Random(ctx, &quotesv1.RandomRequest{Collection: "missing"})
+	wantCode(t, err, codes.NotFound)
+}
+
+func TestGRPCGet(t *testing.T) {
+	client := quotesv1.NewQuotesServiceClient(grpcClient(t))
+	ctx := context.Background()
+
+	item, err := client.Get(ctx, &quotesv1.GetRequest{Collection: "quotes", Id: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rest quoteItem
+	decodeResponse(t, get(t, "/api/v1/quotes/1"), &rest)
+	if item.Id != 1 || item.Text != rest.Quote || item.Fields["author"] != rest.Author || item.Attribution == "" {
+		t.Errorf("item %v, want %+v", item, rest)
+	}
+
+	_, err = client.Get(ctx, &quotesv1.GetRequest{Collection: "quotes", Id: 999999})
+	wantCode(t, err, codes.NotFound)
+}
+
+func TestGRPCList(t *testing.T) {
+	client := quotesv1.NewQuotesServiceClient(grpcClient(t))
+
+	stream, err := client.List(context.Background(), &quotesv1.ListRequest{
+		Filter:     &quotesv1.Filter{Fields: map[string]string{"author": "Author 1", "category": "courage"}},
+		Descending: true,
+		Limit:      4,
+		Offset:     1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int32
+	for {
+		item, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, item.Id)
+	}
+	want := []int32{4401, 4301, 3701, 3401}
+	if len(ids) != len(want) {
+		t.Fatalf("ids %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("ids %v, want %v", ids, want)
+		}
+	}
+
+	stream, err = client.List(context.Background(), &quotesv1.ListRequest{Sort: "nope"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func TestGRPCSearch(t *testing.T) {
+	client := quotesv1.NewQuotesServiceClient(grpcClient(t))
+	ctx := context.Background()
+
+	resp, err := client.Search(ctx, &quotesv1.SearchRequest{Query: "Naruto", Collections: []string{"anime"}, Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total == 0 || len(resp.Results) == 0 || len(resp.Results) > 5 {
+		t.Fatalf("response %v", resp)
+	}
+	for _, result := range resp.Results {
+		if result.Collection != "anime" || result.Item == nil || result.Score <= 0 {
+			t.Errorf("result %v", result)
+		}
+	}
+
+	_, err = client.Search(ctx, &quotesv1.SearchRequest{Query: " "})
+	wantCode(t, err, codes.InvalidArgument)
+	_, err = client.Search(ctx, &quotesv1.SearchRequest{Query: "x", Limit: maxSearchLimit + 1})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func TestGRPCDaily(t *testing.T) {
+	client := quotesv1.NewQuotesServiceClient(grpcClient(t))
+	ctx := context.Background()
+
+	resp, err := client.Daily(ctx, &quotesv1.DailyRequest{Collection: "quotes", Date: "2024-02-29"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rest periodicData
+	decodeResponse(t, get(t, "/api/v1/quotes/daily?date=2024-02-29"), &rest)
+	if resp.Period != quotesv1.Period_PERIOD_DAILY || resp.Key != rest.Key || resp.Item.GetId() != int32(rest.Item.ID) {
+		t.Errorf("response %v, want the item of %+v", resp, rest)
+	}
+
+	weekly, err := client.Daily(ctx, &quotesv1.DailyRequest{Period: quotesv1.Period_PERIOD_WEEKLY, Date: "2024-02-29", Timezone: "Europe/Paris"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if weekly.Period != quotesv1.Period_PERIOD_WEEKLY || weekly.Timezone != "Europe/Paris" || weekly.StartsAt != "2024-02-26T00:00:00+01:00" {
+		t.Errorf("weekly response %v", weekly)
+	}
+
+	_, err = client.Daily(ctx, &quotesv1.DailyRequest{Period: 9})
+	wantCode(t, err, codes.InvalidArgument)
+	_, err = client.Daily(ctx, &quotesv1.DailyRequest{Timezone: "Mars/Base"})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func TestGRPCAuthorize(t *testing.T) {
+	method := quotesv1.QuotesService_Random_FullMethodName
+	withToken := func(value string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", value))
+	}
+
+	wantCode(t, grpcAuthorize(context.Background(), method), codes.Unauthenticated)
+	wantCode(t, grpcAuthorize(withToken("Bearer wrong"), method), codes.Unauthenticated)
+	wantCode(t, grpcAuthorize(withToken(testAdminToken), method), codes.Unauthenticated)
+	wantCode(t, grpcAuthorize(withToken("Bearer "+testAdminToken), method), codes.OK)
+	wantCode(t, grpcAuthorize(context.Background(), healthpb.Health_Check_FullMethodName), codes.OK)
+}
+
+func TestGRPCHealth(t *testing.T) {
+	client := healthpb.NewHealthClient(grpcClient(t))
+	for _, service := range []string{"", "quotes.v1.QuotesService"} {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("service %q is %v", service, resp.Status)
+		}
+	}
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"})
+	wantCode(t, err, codes.NotFound)
+}
+
+func TestGRPCReflection(t *testing.T) {
+	client := reflectionpb.NewServerReflectionClient(grpcClient(t))
+	stream, err := client.ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.CloseSend()
+
+	ask := func(req *reflectionpb.ServerReflectionRequest) *reflectionpb.ServerReflectionResponse {
+		t.Helper()
+		if err := stream.Send(req); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	services := make(map[string]bool)
+	list := ask(&reflectionpb.ServerReflectionRequest{MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{}})
+	for _, service := range list.GetListServicesResponse().GetService() {
+		services[service.Name] = true
+	}
+	for _, name := range []string{"quotes.v1.QuotesService", "grpc.health.v1.Health", "grpc.reflection.v1.ServerReflection"} {
+		if !services[name] {
+			t.Errorf("services %v lack %s", services, name)
+		}
+	}
+
+	// The served descriptor is the one compiled from quotes.proto
+	resp := ask(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "quotes.v1.QuotesService"},
+	})
+	files := resp.GetFileDescriptorResponse().GetFileDescriptorProto()
+	if len(files) != 1 {
+		t.Fatalf("got %d files", len(files))
+	}
+	var served descriptorpb.FileDescriptorProto
+	if err := proto.Unmarshal(files[0], &served); err != nil {
+		t.Fatal(err)
+	}
+	if want := protodesc.ToFileDescriptorProto(quotesv1.File_quotes_v1_quotes_proto); !proto.Equal(&served, want) {
+		t.Errorf("served descriptor %v, want %v", &served, want)
+	}
+	if served.GetName() != "quotes/v1/quotes.proto" || served.GetPackage() != "quotes.v1" {
+		t.Errorf("served file %s in package %s", served.GetName(), served.GetPackage())
+	}
+}
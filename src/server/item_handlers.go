@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/apimgr/quotes/src/database"
+	"github.com/go-chi/chi/v5"
+)
+
+// maxItemSize is the largest item body the admin API accepts
+const maxItemSize = 64 << 10
+
+// applyItemEdits puts the items added or edited through the admin API back
+// into their collections, on top of the embedded data
+func applyItemEdits() {
+	edits, err := database.GetItemEdits()
+	if err != nil {
+		log.Printf("⚠️  Warning: %v", err)
+		return
+	}
+	for _, edit := range edits {
+		c, ok := collection.Get(edit.Collection)
+		if !ok {
+			log.Printf("⚠️  Warning: edited item %d of unknown collection %s", edit.ID, edit.Collection)
+			continue
+		}
+		if _, _, err := c.PutAt(edit.Data, edit.CreatedAt, edit.UpdatedAt); err != nil {
+			log.Printf("⚠️  Warning: failed to restore %s %d: %v", c.Info().ItemName, edit.ID, err)
+		}
+	}
+}
+
+// readItemFields decodes a request body holding one item as a JSON object
+func readItemFields(w http.ResponseWriter, r *http.Request) (map[string]interface{}, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxItemSize))
+	if err != nil {
+		return nil, fmt.Errorf("request body must be at most %d bytes", maxItemSize)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("request body must be a JSON object")
+	}
+	return fields, nil
+}
+
+// putItem stores an item in a collection and the database, tells
+// WebSocket subscribers about it and responds with the stored item
+func (s *Server) putItem(w http.ResponseWriter, c collection.Collection, fields map[string]interface{}) {
+	data, _ := json.Marshal(fields)
+	item, added, err := c.Put(data)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Save the item as stored, with its ID and without unknown fields
+	stored, _ := json.Marshal(item)
+	if database.GetDB() != nil {
+		times := c.Times(item.GetID())
+		if err := database.SaveItemEdit(c.Info().Name, item.GetID(), stored, times.Created, times.Updated); err != nil {
+			log.Printf("⚠️  Warning: %v", err)
+		}
+	}
+	s.sockets.itemChanged(c, item, added)
+
+	status := http.StatusOK
+	if added {
+		status = http.StatusCreated
+	}
+	respondWithJSON(w, status, APIResponse{
+		Success: true,
+		Data:    item,
+	})
+}
+
+// handleAddItem adds an item to a collection under the next free ID
+func (s *Server) handleAddItem(w http.ResponseWriter, r *http.Request) {
+	c, ok := adminCollection(w, r)
+	if !ok {
+		return
+	}
+	fields, err := readItemFields(w, r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	delete(fields, "id")
+	s.putItem(w, c, fields)
+}
+
+// handleUpdateItem replaces an existing item of a collection
+func (s *Server) handleUpdateItem(w http.ResponseWriter, r *http.Request) {
+	c, ok := adminCollection(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := itemFromPath(w, r, c); !ok {
+		return
+	}
+	fields, err := readItemFields(w, r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// The ID in the path wins over one in the body
+	fields["id"], _ = strconv.Atoi(chi.URLParam(r, "id"))
+	s.putItem(w, c, fields)
+}
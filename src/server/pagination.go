@@ -0,0 +1,246 @@
+package server
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/apimgr/quotes/src/collection"
+)
+
+const (
+	// defaultPageLimit is the page size used when a cursor is given without
+	// a limit. Lists requested without either return every item.
+	defaultPageLimit = 100
+
+	// maxPageLimit is the largest page size a client may request
+	maxPageLimit = 1000
+)
+
+// Pagination describes the page returned by a list endpoint
+type Pagination struct {
+	Total      int    `json:"total"`
+	Count      int    `json:"count"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	Sort       string `json:"sort,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
+}
+
+// listQuery holds the parsed pagination, sort and field selection parameters
+type listQuery struct {
+	limit  int
+	offset int
+	cursor *pageCursor // Takes precedence over offset when set
+	sort   string
+	desc   bool
+	fields []string
+}
+
+// pageCursor marks where a page starts: just after the result with key,
+// or, for previous pages, where it ends, just before it. Keying pages on a
+// result rather than an offset keeps them in place when items are added.
+type pageCursor struct {
+	key    string
+	before bool
+}
+
+// parseListQuery parses limit, offset, cursor, sort and fields from the request
+func parseListQuery(r *http.Request, defaultLimit, maxLimit int) (*listQuery, error) {
+	q := r.URL.Query()
+	lq := &listQuery{limit: defaultLimit, sort: "id"}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("limit must be a positive integer")
+		}
+		if limit > maxLimit {
+			limit = maxLimit
+		}
+		lq.limit = limit
+	}
+
+	if v := q.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("offset must be a non-negative integer")
+		}
+		lq.offset = offset
+	}
+
+	// A cursor takes precedence over an explicit offset
+	if v := q.Get("cursor"); v != "" {
+		cursor, err := decodeCursor(v)
+		if err != nil {
+			return nil, err
+		}
+		lq.cursor = cursor
+	}
+
+	if v := q.Get("sort"); v != "" {
+		lq.sort = strings.TrimPrefix(v, "-")
+		lq.desc = strings.HasPrefix(v, "-")
+	}
+
+	if v := q.Get("fields"); v != "" {
+		for _, f := range strings.Split(v, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				lq.fields = append(lq.fields, f)
+			}
+		}
+	}
+
+	return lq, nil
+}
+
+// encodeCursor returns the opaque cursor for a page starting after, or
+// ending before, the result with key
+func encodeCursor(c pageCursor) string {
+	direction := "a:"
+	if c.before {
+		direction = "b:"
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(direction + c.key))
+}
+
+// decodeCursor returns the page position encoded in a cursor
+func decodeCursor(cursor string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(raw) < 3 || (raw[0] != 'a' && raw[0] != 'b') || raw[1] != ':' {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &pageCursor{key: string(raw[2:]), before: raw[0] == 'b'}, nil
+}
+
+// pageLink returns the request URL moved to the page at cursor
+func pageLink(r *http.Request, cursor string, limit int) string {
+	q := r.URL.Query()
+	q.Del("offset")
+	q.Set("cursor", cursor)
+	q.Set("limit", strconv.Itoa(limit))
+
+	u := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+	return u.String()
+}
+
+// newPagination returns the bounds of the requested page within total
+// results, whose cursor keys key returns, and the pagination metadata
+// describing it. A cursor whose result is no longer listed is an error.
+func newPagination(r *http.Request, lq *listQuery, total int, key func(int) string) (int, int, *Pagination, error) {
+	start := min(lq.offset, total)
+	end := min(start+lq.limit, total)
+	if lq.cursor != nil {
+		at := -1
+		for i := 0; i < total; i++ {
+			if key(i) == lq.cursor.key {
+				at = i
+				break
+			}
+		}
+		if at < 0 {
+			return 0, 0, nil, fmt.Errorf("cursor no longer matches a result; start from the first page")
+		}
+		if lq.cursor.before {
+			start, end = max(at-lq.limit, 0), at
+		} else {
+			start, end = at+1, min(at+1+lq.limit, total)
+		}
+	}
+
+	pagination := &Pagination{
+		Total:  total,
+		Count:  end - start,
+		Limit:  lq.limit,
+		Offset: start,
+	}
+	if end < total && end > 0 {
+		pagination.NextCursor = encodeCursor(pageCursor{key: key(end - 1)})
+		pagination.Next = pageLink(r, pagination.NextCursor, lq.limit)
+	}
+	if start > 0 && start < total {
+		pagination.PrevCursor = encodeCursor(pageCursor{key: key(start), before: true})
+		pagination.Prev = pageLink(r, pagination.PrevCursor, lq.limit)
+	}
+	return start, end, pagination, nil
+}
+
+// paginateItems sorts and slices items according to the list query
+func paginateItems(r *http.Request, info collection.Info, items []collection.Item, lq *listQuery) ([]collection.Item, *Pagination, error) {
+	sorted := items
+	if lq.sort != "id" || lq.desc {
+		var err error
+		sorted, err = collection.SortItems(info, items, lq.sort, lq.desc)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	start, end, pagination, err := newPagination(r, lq, len(sorted), func(i int) string {
+		return strconv.Itoa(sorted[i].GetID())
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if lq.sort != "id" || lq.desc {
+		pagination.Sort = r.URL.Query().Get("sort")
+	}
+	return sorted[start:end], pagination, nil
+}
+
+// projectItems returns the page itself, or only the selected fields of each item
+func projectItems(info collection.Info, page []collection.Item, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return page, nil
+	}
+
+	selected := make([]map[string]interface{}, len(page))
+	for i, item := range page {
+		projected, err := collection.SelectFields(info, item, fields)
+		if err != nil {
+			return nil, err
+		}
+		selected[i] = projected
+	}
+	return selected, nil
+}
+
+// respondWithList sends a paginated list of collection items. Lists are
+// sent whole unless a page is requested with limit or cursor.
+func respondWithList(w http.ResponseWriter, r *http.Request, c collection.Collection, items []collection.Item) {
+	lq, err := parseListQuery(r, defaultPageLimit, maxPageLimit)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if q := r.URL.Query(); !q.Has("limit") && !q.Has("cursor") {
+		lq.limit = max(len(items), 1)
+	}
+
+	info := c.Info()
+	page, pagination, err := paginateItems(r, info, items, lq)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	data, err := projectItems(info, page, lq.fields)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithContent(w, r, APIResponse{
+		Success:    true,
+		Data:       data,
+		Pagination: pagination,
+	}, func() contentBody {
+		return itemContent(info, page, lq.fields)
+	})
+}
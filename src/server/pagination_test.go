@@ -0,0 +1,163 @@
+package server
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestParseListQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		limit   int
+		offset  int
+		cursor  *pageCursor
+		sort    string
+		desc    bool
+		wantErr bool
+	}{
+		{"", defaultPageLimit, 0, nil, "id", false, false},
+		{"limit=10&offset=20", 10, 20, nil, "id", false, false},
+		{"limit=1000", 1000, 0, nil, "id", false, false},
+		{"limit=1001", maxPageLimit, 0, nil, "id", false, false},
+		{"limit=999999999", maxPageLimit, 0, nil, "id", false, false},
+		{"limit=0", 0, 0, nil, "", false, true},
+		{"limit=-5", 0, 0, nil, "", false, true},
+		{"limit=ten", 0, 0, nil, "", false, true},
+		{"offset=-1", 0, 0, nil, "", false, true},
+		{"offset=1.5", 0, 0, nil, "", false, true},
+		{"cursor=" + encodeCursor(pageCursor{key: "40"}) + "&offset=5", defaultPageLimit, 5, &pageCursor{key: "40"}, "id", false, false},
+		{"cursor=" + encodeCursor(pageCursor{key: "7", before: true}), defaultPageLimit, 0, &pageCursor{key: "7", before: true}, "id", false, false},
+		{"cursor=not-a-cursor", 0, 0, nil, "", false, true},
+		{"cursor=" + base64.RawURLEncoding.EncodeToString([]byte("x:3")), 0, 0, nil, "", false, true},
+		{"cursor=" + base64.RawURLEncoding.EncodeToString([]byte("a:")), 0, 0, nil, "", false, true},
+		{"sort=-author", defaultPageLimit, 0, nil, "author", true, false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/quotes?"+tt.query, nil)
+		lq, err := parseListQuery(r, defaultPageLimit, maxPageLimit)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: got %+v, want an error", tt.query, lq)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.query, err)
+			continue
+		}
+		if lq.limit != tt.limit || lq.offset != tt.offset || !reflect.DeepEqual(lq.cursor, tt.cursor) || lq.sort != tt.sort || lq.desc != tt.desc {
+			t.Errorf("%q: got %+v", tt.query, lq)
+		}
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	for _, c := range []pageCursor{{key: "0"}, {key: "123456", before: true}, {key: "anime/42"}} {
+		got, err := decodeCursor(encodeCursor(c))
+		if err != nil || *got != c {
+			t.Errorf("decodeCursor(encodeCursor(%+v)) = %+v, %v", c, got, err)
+		}
+	}
+}
+
+func TestNewPagination(t *testing.T) {
+	key := func(i int) string { return strconv.Itoa(i * 10) }
+	tests := []struct {
+		limit, offset int
+		cursor        *pageCursor
+		total         int
+		start, end    int
+		next, prev    string
+		wantErr       bool
+	}{
+		{10, 0, nil, 25, 0, 10, "90", "", false},
+		{10, 10, nil, 25, 10, 20, "190", "100", false},
+		{10, 20, nil, 25, 20, 25, "", "200", false},
+		{10, 30, nil, 25, 25, 25, "", "", false},
+		{10, 0, nil, 0, 0, 0, "", "", false},
+		{100, 5, nil, 25, 5, 25, "", "50", false},
+		{10, 0, &pageCursor{key: "90"}, 25, 10, 20, "190", "100", false},
+		{10, 3, &pageCursor{key: "40"}, 25, 5, 15, "140", "50", false},
+		{10, 0, &pageCursor{key: "240"}, 25, 25, 25, "", "", false},
+		{10, 0, &pageCursor{key: "100", before: true}, 25, 0, 10, "90", "", false},
+		{10, 0, &pageCursor{key: "150", before: true}, 25, 5, 15, "140", "50", false},
+		{10, 0, &pageCursor{key: "55"}, 25, 0, 0, "", "", true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/quotes", nil)
+		lq := &listQuery{limit: tt.limit, offset: tt.offset, cursor: tt.cursor}
+		start, end, p, err := newPagination(r, lq, tt.total, key)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("cursor %+v: got [%d, %d), want an error", tt.cursor, start, end)
+			}
+			continue
+		}
+		if err != nil || start != tt.start || end != tt.end || p.Offset != start || p.Count != end-start || p.Total != tt.total {
+			t.Errorf("limit %d offset %d cursor %+v of %d: [%d, %d), %+v, %v", tt.limit, tt.offset, tt.cursor, tt.total, start, end, p, err)
+			continue
+		}
+		if next := cursorKey(t, p.NextCursor, false); next != tt.next {
+			t.Errorf("limit %d offset %d cursor %+v: next after %q, want %q", tt.limit, tt.offset, tt.cursor, next, tt.next)
+		}
+		if prev := cursorKey(t, p.PrevCursor, true); prev != tt.prev {
+			t.Errorf("limit %d offset %d cursor %+v: prev before %q, want %q", tt.limit, tt.offset, tt.cursor, prev, tt.prev)
+		}
+	}
+}
+
+// cursorKey returns the key of a cursor, checking its direction
+func cursorKey(t *testing.T, cursor string, before bool) string {
+	t.Helper()
+	if cursor == "" {
+		return ""
+	}
+	c, err := decodeCursor(cursor)
+	if err != nil || c.before != before {
+		t.Errorf("cursor %q: %+v, %v", cursor, c, err)
+		return ""
+	}
+	return c.key
+}
+
+func TestListPages(t *testing.T) {
+	var first []map[string]interface{}
+	resp := decodeResponse(t, get(t, "/api/v1/anime?limit=3"), &first)
+	if len(first) != 3 || resp.Pagination == nil || resp.Pagination.NextCursor == "" {
+		t.Fatalf("first page: %d items, pagination %+v", len(first), resp.Pagination)
+	}
+
+	var second []map[string]interface{}
+	resp = decodeResponse(t, get(t, "/api/v1/anime?limit=3&cursor="+resp.Pagination.NextCursor), &second)
+	if len(second) != 3 || resp.Pagination.Offset != 3 || second[0]["id"] == first[0]["id"] {
+		t.Errorf("second page: %v, pagination %+v", second, resp.Pagination)
+	}
+
+	// The previous page of the second page is the first page again
+	var back []map[string]interface{}
+	decodeResponse(t, get(t, resp.Pagination.Prev), &back)
+	if !reflect.DeepEqual(back, first) {
+		t.Errorf("previous page: %v, want %v", back, first)
+	}
+
+	// Without limit or cursor, lists return every item
+	resp = decodeResponse(t, get(t, "/api/v1/anime"), nil)
+	if resp.Pagination.Count != resp.Pagination.Total || resp.Pagination.NextCursor != "" {
+		t.Errorf("unpaginated list: pagination %+v", resp.Pagination)
+	}
+
+	for _, query := range []string{"limit=-1", "limit=abc", "offset=-2", "cursor=bm9wZQ", "sort=nope"} {
+		if w := get(t, "/api/v1/anime?"+query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, w.Code)
+		}
+	}
+
+	resp = decodeResponse(t, get(t, "/api/v1/anime?limit=5000"), nil)
+	if resp.Pagination.Limit != maxPageLimit || resp.Pagination.Count != maxPageLimit {
+		t.Errorf("limit above the maximum: pagination %+v", resp.Pagination)
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/apimgr/quotes/src/collection"
+)
+
+// pastPeriodMaxAge is how long caches may keep the item of a past period, in
+// seconds. Picks of past periods stay the same as items are added, but not
+// when a new release changes the datasets, so they are not cached forever.
+const pastPeriodMaxAge = 86400
+
+// dateLayouts are the accepted formats of the date parameter
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15", "2006-01-02"}
+
+// PeriodicResponse is the data returned by the daily, hourly and weekly endpoints
+type PeriodicResponse struct {
+	Collection string          `json:"collection"`
+	Period     string          `json:"period"`
+	Key        string          `json:"key"`
+	Timezone   string          `json:"timezone"`
+	StartsAt   time.Time       `json:"starts_at"`
+	EndsAt     time.Time       `json:"ends_at"`
+	Item       collection.Item `json:"item"`
+}
+
+// parsePeriodTime returns the time to pick the periodic item for, honouring
+// the tz and date parameters
+func parsePeriodTime(r *http.Request) (time.Time, error) {
+	return periodTimeFromValues(r.URL.Query())
+}
+
+// periodTimeFromValues returns the time to pick the periodic item for,
+// honouring the tz and date values of q
+func periodTimeFromValues(q url.Values) (time.Time, error) {
+	loc := time.UTC
+	if tz := q.Get("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return time.Time{}, fmt.Errorf("unknown timezone %q", tz)
+		}
+	}
+
+	date := q.Get("date")
+	if date == "" {
+		return time.Now().In(loc), nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, date, loc); err == nil {
+			return t.In(loc), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("date must be formatted as YYYY-MM-DD, YYYY-MM-DDTHH or RFC 3339")
+}
+
+// setPeriodCacheHeaders lets caches keep a periodic response until the period ends
+func setPeriodCacheHeaders(w http.ResponseWriter, start, end time.Time) {
+	now := time.Now()
+	w.Header().Set("Last-Modified", start.UTC().Format(http.TimeFormat))
+
+	if !end.After(now) {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(pastPeriodMaxAge))
+		return
+	}
+
+	maxAge := int(math.Ceil(end.Sub(now).Seconds()))
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+	w.Header().Set("Expires", end.UTC().Format(http.TimeFormat))
+}
+
+// handleCollectionPeriodic returns the item of a collection for the current
+// (or requested) hour, day or week
+func handleCollectionPeriodic(c collection.Collection, period collection.Period) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t, err := parsePeriodTime(r)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		item, err := collection.PeriodicItem(c, period, t)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		start, end := period.Bounds(t)
+		setPeriodCacheHeaders(w, start, end)
+
+		respondWithContent(w, r, APIResponse{
+			Success: true,
+			Data: PeriodicResponse{
+				Collection: c.Info().Name,
+				Period:     string(period),
+				Key:        period.Key(t),
+				Timezone:   t.Location().String(),
+				StartsAt:   start,
+				EndsAt:     end,
+				Item:       item,
+			},
+		}, func() contentBody {
+			return singleItemContent(c.Info(), item)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// periodicData is the data of a periodic response
+type periodicData struct {
+	Period   string    `json:"period"`
+	Key      string    `json:"key"`
+	Timezone string    `json:"timezone"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Item     quoteItem `json:"item"`
+}
+
+func TestPeriodCacheHeaders(t *testing.T) {
+	before := time.Now()
+	w := get(t, "/api/v1/quotes/hourly")
+	var data periodicData
+	decodeResponse(t, w, &data)
+
+	start, end := data.StartsAt, data.EndsAt
+	if end.Sub(start) != time.Hour || before.Before(start) || !before.Before(end) {
+		t.Fatalf("current hour runs from %v to %v", start, end)
+	}
+	if got := w.Header().Get("Last-Modified"); got != start.UTC().Format(http.TimeFormat) {
+		t.Errorf("Last-Modified = %q, want the start of the hour", got)
+	}
+	if got := w.Header().Get("Expires"); got != end.UTC().Format(http.TimeFormat) {
+		t.Errorf("Expires = %q, want the end of the hour", got)
+	}
+	maxAge, err := strconv.Atoi(strings.TrimPrefix(w.Header().Get("Cache-Control"), "public, max-age="))
+	if err != nil || maxAge < 1 || maxAge > int(time.Until(end).Seconds())+1 {
+		t.Errorf("Cache-Control = %q, want max-age until the end of the hour", w.Header().Get("Cache-Control"))
+	}
+
+	// Past periods are cached for a while, but not forever
+	for _, target := range []string{
+		"/api/v1/quotes/daily?date=2024-02-29",
+		"/api/v1/anime/weekly?date=2024-02-29&tz=Asia/Tokyo",
+		"/api/v1/quotes/daily/card.svg?date=2024-02-29",
+	} {
+		w := get(t, target)
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s = %d", target, w.Code)
+			continue
+		}
+		if got := w.Header().Get("Cache-Control"); got != "public, max-age=86400" {
+			t.Errorf("GET %s: Cache-Control = %q", target, got)
+		}
+		if got := w.Header().Get("Expires"); got != "" {
+			t.Errorf("GET %s: Expires = %q for a past period", target, got)
+		}
+	}
+
+	// Future periods expire when they end, like the current one
+	w = get(t, "/api/v1/quotes/daily?date=2999-01-01")
+	if got := w.Header().Get("Expires"); got != "Wed, 02 Jan 2999 00:00:00 GMT" {
+		t.Errorf("future day: Expires = %q", got)
+	}
+}
+
+func TestPeriodicEndpoint(t *testing.T) {
+	var data periodicData
+	decodeResponse(t, get(t, "/api/v1/quotes/daily?date=2025-10-14T23&tz=America/New_York"), &data)
+	if data.Key != "2025-10-14" || data.Timezone != "America/New_York" || data.Period != "daily" {
+		t.Errorf("daily in New York: %+v", data)
+	}
+	if got := data.StartsAt.UTC(); !got.Equal(time.Date(2025, 10, 14, 4, 0, 0, 0, time.UTC)) {
+		t.Errorf("day starts at %v", got)
+	}
+
+	// Everyone gets the same item for the same period
+	first := get(t, "/api/v1/quotes/weekly?date=2025-10-14").Body.String()
+	if again := get(t, "/api/v1/quotes/weekly?date=2025-10-19").Body.String(); again != first {
+		t.Errorf("two days of one week got different items:\n%s\n%s", first, again)
+	}
+
+	for _, query := range []string{"tz=Mars/Olympus", "date=yesterday", "date=2025-13-01"} {
+		if w := get(t, "/api/v1/quotes/daily?"+query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestPeriodicItemSurvivesAppend(t *testing.T) {
+	targets := []string{"/api/v1/quotes/daily", "/api/v1/quotes/hourly", "/api/v1/quotes/daily?date=2025-01-01"}
+	before := make(map[string]int)
+	for _, target := range targets {
+		var data periodicData
+		decodeResponse(t, get(t, target), &data)
+		before[target] = data.Item.ID
+	}
+
+	for i := 0; i < 20; i++ {
+		w := serve(t, http.MethodPost, "/api/v1/admin/items/quotes", `{"quote": "A newly added quote.", "author": "Tester", "category": "testing"}`, adminHeader())
+		if w.Code != http.StatusCreated {
+			t.Fatalf("adding an item: status %d: %s", w.Code, w.Body)
+		}
+	}
+
+	for _, target := range targets {
+		var data periodicData
+		decodeResponse(t, get(t, target), &data)
+		if data.Item.ID != before[target] {
+			t.Errorf("GET %s: item %d became %d after items were added", target, before[target], data.Item.ID)
+		}
+	}
+}
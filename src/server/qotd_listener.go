@@ -0,0 +1,66 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/apimgr/quotes/src/fortune"
+	"github.com/apimgr/quotes/src/qotd"
+)
+
+// qotdModes are the accepted values of the qotd.mode setting
+var qotdModes = map[string]bool{"random": true, "daily": true}
+
+// qotdSource returns the collection and mode of the Quote of the Day,
+// from the qotd.collection and qotd.mode settings. Unknown values fall
+// back to a random quote.
+func (s *Server) qotdSource() (collection.Collection, string) {
+	s.settingsMutex.RLock()
+	name := s.settingsCache["qotd.collection"].(string)
+	mode := s.settingsCache["qotd.mode"].(string)
+	s.settingsMutex.RUnlock()
+
+	c, ok := collection.Get(name)
+	if !ok {
+		log.Printf("⚠️  Warning: unknown qotd.collection %q, using quotes", name)
+		c, _ = collection.Get("quotes")
+	}
+	if !qotdModes[mode] {
+		log.Printf("⚠️  Warning: qotd.mode must be random or daily, not %q; using random", mode)
+		mode = "random"
+	}
+	return c, mode
+}
+
+// qotdQuote returns the quote of a QOTD client: a random item, or the item
+// of the UTC day, in fortune style
+func (s *Server) qotdQuote(c collection.Collection, mode string) string {
+	var item collection.Item
+	var err error
+	if mode == "daily" {
+		item, err = collection.PeriodicItem(c, collection.Daily, time.Now().UTC())
+	} else {
+		item, err = s.randomItem(c)
+	}
+	if err != nil {
+		return fmt.Sprintf("No %s available", c.Info().Title)
+	}
+	return fortune.Entry(c.Info(), item)
+}
+
+// startQOTD serves the Quote of the Day on the QOTD port, over TCP and UDP
+func (s *Server) startQOTD() error {
+	c, mode := s.qotdSource()
+	addr := net.JoinHostPort(s.address, s.ports.QOTD)
+
+	var err error
+	s.qotd, err = qotd.Listen(addr, func() string { return s.qotdQuote(c, mode) })
+	if err != nil {
+		return fmt.Errorf("failed to listen for QOTD on %s: %w", addr, err)
+	}
+	log.Printf("Quote of the Day (%s %s) listening on %s, TCP and UDP", mode, c.Info().Title, addr)
+	return nil
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/apimgr/quotes/src/collection"
+)
+
+// maxRandomCount is the largest number of random items one request may ask for
+const maxRandomCount = 100
+
+// parseFilter reads the field, length and exclusion filters from the query string
+func parseFilter(r *http.Request, info collection.Info) (collection.Filter, error) {
+	return filterFromValues(r.URL.Query(), info)
+}
+
+// filterFromValues reads the field, length and exclusion filters from q
+func filterFromValues(q url.Values, info collection.Info) (collection.Filter, error) {
+	var f collection.Filter
+
+	for _, field := range info.Fields {
+		if v := q.Get(field.Name); v != "" {
+			if f.Fields == nil {
+				f.Fields = make(map[string]string)
+			}
+			f.Fields[field.Name] = v
+		}
+	}
+
+	var err error
+	if v := q.Get("min_length"); v != "" {
+		if f.MinLength, err = strconv.Atoi(v); err != nil || f.MinLength < 0 {
+			return f, fmt.Errorf("min_length must be a non-negative integer")
+		}
+	}
+	if v := q.Get("max_length"); v != "" {
+		if f.MaxLength, err = strconv.Atoi(v); err != nil || f.MaxLength < 0 {
+			return f, fmt.Errorf("max_length must be a non-negative integer")
+		}
+	}
+	if f.MaxLength > 0 && f.MinLength > f.MaxLength {
+		return f, fmt.Errorf("min_length must not be greater than max_length")
+	}
+
+	if v := q.Get("exclude_ids"); v != "" {
+		f.ExcludeIDs = make(map[int]bool)
+		for _, s := range strings.Split(v, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				return f, fmt.Errorf("exclude_ids must be a comma-separated list of IDs")
+			}
+			f.ExcludeIDs[id] = true
+		}
+	}
+
+	return f, nil
+}
+
+// parseCount reads the count parameter, returning 0 when it is absent
+func parseCount(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("count")
+	if v == "" {
+		return 0, nil
+	}
+
+	count, err := strconv.Atoi(v)
+	if err != nil || count < 1 || count > maxRandomCount {
+		return 0, fmt.Errorf("count must be between 1 and %d", maxRandomCount)
+	}
+	return count, nil
+}
+
+// parseSeed reads the seed parameter, or picks a new seed when it is absent
+func parseSeed(r *http.Request) (uint64, error) {
+	v := r.URL.Query().Get("seed")
+	if v == "" {
+		return collection.NewSeed(), nil
+	}
+
+	seed, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("seed must be a non-negative integer")
+	}
+	return seed, nil
+}
+
+// handleCollectionRandom returns a random item from a collection. Filter
+// parameters narrow the selection; count returns a list of distinct items.
+// The seed used is returned so the same request can be replayed with ?seed=.
+// With weighted=true items are drawn in proportion to their weights, and
+// with norepeat=true the client instead walks a shuffled rotation and sees
+// every matching item before any repeats.
+func (s *Server) handleCollectionRandom(c collection.Collection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseFilter(r, c.Info())
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		count, err := parseCount(r)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var items []collection.Item
+		var seed *uint64
+		var rotationInfo *RotationInfo
+		if isNoRepeat(r) {
+			items, rotationInfo, err = s.nextRotationItems(w, r, c, filter, max(count, 1))
+		} else {
+			var value uint64
+			if value, err = parseSeed(r); err != nil {
+				respondWithError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			seed = &value
+			rng := collection.NewSeededRand(value)
+			if s.isWeighted(r) {
+				// Replaying an explicit seed must not depend on what was served since
+				avoidRecent := !r.URL.Query().Has("seed")
+				items, err = s.weights.RandomItems(c, filter, max(count, 1), rng, avoidRecent)
+			} else {
+				items, err = collection.RandomItems(c, filter, max(count, 1), rng)
+			}
+		}
+		if errors.Is(err, collection.ErrNoMatch) {
+			respondWithError(w, http.StatusNotFound, fmt.Sprintf("No %s match the filters", c.Info().Title))
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		var data interface{} = items[0]
+		if count > 0 {
+			data = items
+		}
+
+		if seed != nil {
+			w.Header().Set("X-Random-Seed", strconv.FormatUint(*seed, 10))
+		}
+		respondWithContent(w, r, APIResponse{
+			Success:  true,
+			Data:     data,
+			Seed:     seed,
+			Rotation: rotationInfo,
+		}, func() contentBody {
+			if count > 0 {
+				return itemContent(c.Info(), items, nil)
+			}
+			return singleItemContent(c.Info(), items[0])
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"unicode/utf8"
+)
+
+// quoteItem is a general quote as the API returns it
+type quoteItem struct {
+	ID       int    `json:"id"`
+	Quote    string `json:"quote"`
+	Author   string `json:"author"`
+	Category string `json:"category"`
+}
+
+func TestRandomFieldAndExcludeFilters(t *testing.T) {
+	// Author 1 has nine courage quotes; exclude all but one
+	target := "/api/v1/quotes/random?author=author%201&category=Courage&exclude_ids=1,301,1901,%202301,3401,3701,4301,4401"
+	for i := 0; i < 10; i++ {
+		var item quoteItem
+		decodeResponse(t, get(t, target), &item)
+		if item.ID != 5101 {
+			t.Fatalf("GET %s = %+v, want item 5101", target, item)
+		}
+	}
+
+	// With the last one excluded too nothing is left
+	w := get(t, target+",5101")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("every match excluded: status %d, want 404", w.Code)
+	}
+
+	// count returns every remaining match once
+	var items []quoteItem
+	decodeResponse(t, get(t, "/api/v1/quotes/random?author=Author%201&category=courage&exclude_ids=1,301&count=20"), &items)
+	seen := make(map[int]bool)
+	for _, item := range items {
+		if item.ID == 1 || item.ID == 301 || seen[item.ID] || item.Author != "Author 1" || item.Category != "courage" {
+			t.Errorf("count=20: unexpected %+v", item)
+		}
+		seen[item.ID] = true
+	}
+	if len(items) != 7 {
+		t.Errorf("count=20: %d items, want the 7 remaining matches", len(items))
+	}
+}
+
+func TestRandomLengthFilter(t *testing.T) {
+	tests := []struct {
+		min, max int
+	}{
+		{0, 37},
+		{46, 0},
+		{41, 41},
+		{38, 40},
+	}
+	for _, tt := range tests {
+		target := "/api/v1/quotes/random?count=100"
+		if tt.min > 0 {
+			target += fmt.Sprintf("&min_length=%d", tt.min)
+		}
+		if tt.max > 0 {
+			target += fmt.Sprintf("&max_length=%d", tt.max)
+		}
+		var items []quoteItem
+		decodeResponse(t, get(t, target), &items)
+		if len(items) == 0 {
+			t.Errorf("GET %s: no items", target)
+		}
+		for _, item := range items {
+			n := utf8.RuneCountInString(item.Quote)
+			if n < tt.min || (tt.max > 0 && n > tt.max) {
+				t.Errorf("GET %s: %q has %d characters", target, item.Quote, n)
+			}
+		}
+	}
+
+	if w := get(t, "/api/v1/quotes/random?min_length=1000"); w.Code != http.StatusNotFound {
+		t.Errorf("min_length=1000: status %d, want 404", w.Code)
+	}
+}
+
+func TestRandomFilterErrors(t *testing.T) {
+	for _, query := range []string{
+		"min_length=-1",
+		"max_length=short",
+		"min_length=50&max_length=40",
+		"exclude_ids=1,two",
+		"count=0",
+		"count=101",
+		"seed=-4",
+	} {
+		if w := get(t, "/api/v1/quotes/random?"+query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestRandomSeedReplay(t *testing.T) {
+	for _, query := range []string{
+		"seed=42",
+		"seed=42&category=wisdom",
+		"seed=7&min_length=40&max_length=43&exclude_ids=1,2,3",
+		"seed=18446744073709551615&count=5",
+		"seed=42&weighted=true&count=3",
+		"seed=0&author=Author%2012",
+	} {
+		target := "/api/v1/quotes/random?" + query
+		first := get(t, target)
+		if first.Code != http.StatusOK {
+			t.Errorf("GET %s = %d: %s", target, first.Code, first.Body)
+			continue
+		}
+		for i := 0; i < 3; i++ {
+			if again := get(t, target); again.Body.String() != first.Body.String() {
+				t.Errorf("GET %s is not repeatable:\n%s\n%s", target, first.Body, again.Body)
+				break
+			}
+		}
+	}
+
+	// Different seeds pick different items
+	seen := make(map[string]bool)
+	for seed := 0; seed < 10; seed++ {
+		var item quoteItem
+		decodeResponse(t, get(t, fmt.Sprintf("/api/v1/quotes/random?seed=%d", seed)), &item)
+		seen[item.Quote] = true
+	}
+	if len(seen) < 5 {
+		t.Errorf("10 seeds picked only %d distinct quotes", len(seen))
+	}
+}
+
+func TestRandomSeedEcho(t *testing.T) {
+	// An explicit seed is echoed in the header and the envelope
+	w := get(t, "/api/v1/quotes/random?seed=12345")
+	resp := decodeResponse(t, w, nil)
+	if w.Header().Get("X-Random-Seed") != "12345" || resp.Seed == nil || *resp.Seed != 12345 {
+		t.Errorf("explicit seed: header %q, seed %v", w.Header().Get("X-Random-Seed"), resp.Seed)
+	}
+
+	// A generated seed replays the same pick
+	w = get(t, "/api/v1/anime/random?count=3")
+	resp = decodeResponse(t, w, nil)
+	header := w.Header().Get("X-Random-Seed")
+	if resp.Seed == nil || header != strconv.FormatUint(*resp.Seed, 10) {
+		t.Fatalf("generated seed: header %q, seed %v", header, resp.Seed)
+	}
+	replay := get(t, "/api/v1/anime/random?count=3&seed="+header)
+	var first, second []map[string]interface{}
+	decodeResponse(t, w, &first)
+	decodeResponse(t, replay, &second)
+	if fmt.Sprint(first) != fmt.Sprint(second) {
+		t.Errorf("replaying seed %s gave %v, want %v", header, second, first)
+	}
+
+	// Rotations report their progress instead of a seed
+	w = get(t, "/api/v1/anime/random?norepeat=true")
+	if resp := decodeResponse(t, w, nil); w.Header().Get("X-Random-Seed") != "" || resp.Seed != nil {
+		t.Errorf("norepeat: header %q, seed %v", w.Header().Get("X-Random-Seed"), resp.Seed)
+	}
+}
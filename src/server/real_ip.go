@@ -0,0 +1,85 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// parseTrustedProxies parses a space- or comma-separated list of proxy
+// addresses and networks, such as "127.0.0.1 10.0.0.0/8", skipping
+// invalid entries
+func parseTrustedProxies(list string) []netip.Prefix {
+	var proxies []netip.Prefix
+	for _, entry := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			proxies = append(proxies, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(entry); err == nil {
+			proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		} else {
+			log.Printf("⚠️  Warning: Ignoring invalid trusted proxy %q", entry)
+		}
+	}
+	return proxies
+}
+
+// trustedProxy reports whether addr belongs to one of proxies
+func trustedProxy(proxies []netip.Prefix, addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range proxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// realIPMiddleware sets the remote address of requests relayed by a
+// trusted proxy to the client address in their X-Forwarded-For or
+// X-Real-IP header. Those headers are ignored on requests from anyone
+// else, so clients cannot choose the address that rate limits and rating
+// votes are counted against.
+func (s *Server) realIPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.settingsMutex.RLock()
+		proxies := s.settingsCache["server.trusted_proxies"].([]netip.Prefix)
+		s.settingsMutex.RUnlock()
+
+		if ip := forwardedIP(r, proxies); ip.IsValid() {
+			r.RemoteAddr = ip.String()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// forwardedIP returns the client address a trusted proxy forwarded a
+// request for: the last address in X-Forwarded-For that is not itself a
+// trusted proxy, or else X-Real-IP. It returns the zero address when the
+// peer is not trusted or names no valid address.
+func forwardedIP(r *http.Request, proxies []netip.Prefix) netip.Addr {
+	peer, err := netip.ParseAddr(clientIP(r))
+	if err != nil || !trustedProxy(proxies, peer) {
+		return netip.Addr{}
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		addr, err := netip.ParseAddr(hop)
+		if err != nil {
+			return netip.Addr{}
+		}
+		if !trustedProxy(proxies, addr) || i == 0 {
+			return addr.Unmap()
+		}
+	}
+
+	if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return addr.Unmap()
+	}
+	return netip.Addr{}
+}
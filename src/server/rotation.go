@@ -0,0 +1,148 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/apimgr/quotes/src/database"
+	"github.com/apimgr/quotes/src/rotation"
+)
+
+const (
+	// sessionCookieName is the cookie identifying browser clients in no-repeat mode
+	sessionCookieName = "quotes_session"
+
+	// sessionMaxAge is how long session cookies last
+	sessionMaxAge = 30 * 24 * time.Hour
+
+	// maxStoredRotations is how many rotations the database keeps; the least
+	// recently used ones beyond it are deleted with the unused ones
+	maxStoredRotations = 100000
+
+	// rotationPruneInterval is how often rotations unused for sessionMaxAge
+	// are deleted from the database
+	rotationPruneInterval = time.Hour
+)
+
+// RotationInfo reports the progress of a client through a no-repeat rotation
+type RotationInfo struct {
+	Position int `json:"position"`
+	Total    int `json:"total"`
+}
+
+// rotationStore persists no-repeat rotations in the SQLite database
+type rotationStore struct{}
+
+// LoadRotation returns the saved rotation state for key
+func (rotationStore) LoadRotation(key string) (rotation.State, bool, error) {
+	r, found, err := database.GetRotation(key)
+	if err != nil || !found {
+		return rotation.State{}, false, err
+	}
+	return rotation.State(r), true, nil
+}
+
+// SaveRotation saves the rotation state for key
+func (rotationStore) SaveRotation(key string, state rotation.State) error {
+	return database.SaveRotation(key, database.Rotation(state))
+}
+
+// pruneRotations deletes the saved rotations nobody used for as long as a
+// session cookie lasts, and the least recently used ones beyond
+// maxStoredRotations, every rotationPruneInterval until shutdown
+func (s *Server) pruneRotations() {
+	ticker := time.NewTicker(rotationPruneInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := database.PruneRotations(time.Now().Add(-sessionMaxAge), maxStoredRotations); err != nil {
+			log.Printf("⚠️  Warning: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-s.shutdown:
+			return
+		}
+	}
+}
+
+// isNoRepeat reports whether the request asks for no-repeat random selection
+func isNoRepeat(r *http.Request) bool {
+	switch strings.ToLower(r.URL.Query().Get("norepeat")) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// requestAPIKey returns the API key sent as a bearer token or X-API-Key header
+func requestAPIKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	parts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
+		return parts[1]
+	}
+	return ""
+}
+
+// rotationClient identifies the caller of a no-repeat request by its session
+// parameter, API key or session cookie, setting a new cookie if it has none
+func rotationClient(w http.ResponseWriter, r *http.Request) string {
+	if session := r.URL.Query().Get("session"); session != "" {
+		// Sessions are hashed so that long names do not grow the rotations kept
+		return "session:" + hashClientKey(session)
+	}
+
+	if key := requestAPIKey(r); key != "" {
+		// Never keep API keys themselves in memory or the database
+		return "key:" + hashClientKey(key)
+	}
+
+	if cookie, err := r.Cookie(sessionCookieName); err == nil && cookie.Value != "" {
+		return "cookie:" + cookie.Value
+	}
+
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	id := hex.EncodeToString(bytes)
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int(sessionMaxAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return "cookie:" + id
+}
+
+// hashClientKey returns a fixed-length digest of a client-chosen key
+func hashClientKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
+
+// nextRotationItems returns the next count items of the client's rotation
+// through the items of c that pass the filter
+func (s *Server) nextRotationItems(w http.ResponseWriter, r *http.Request, c collection.Collection, filter collection.Filter, count int) ([]collection.Item, *RotationInfo, error) {
+	candidates := collection.FilterItems(c, filter)
+	if len(candidates) == 0 {
+		return nil, nil, collection.ErrNoMatch
+	}
+
+	key := rotationClient(w, r) + "|" + c.Info().Name + "|" + filter.Key()
+	positions, state := s.rotations.Next(key, len(candidates), count)
+
+	items := make([]collection.Item, len(positions))
+	for i, pos := range positions {
+		items[i] = candidates[pos]
+	}
+	return items, &RotationInfo{Position: state.Position, Total: state.Size}, nil
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apimgr/quotes/src/database"
+	"github.com/apimgr/quotes/src/rotation"
+)
+
+func TestRotationStore(t *testing.T) {
+	var store rotationStore
+	state := rotation.State{Seed: 1 << 52, Position: 3, Size: 10, Cursor: 4, Deferred: []int{7}, Last: []int{2, 5}, Version: 100}
+	if err := store.SaveRotation("test|store", state); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := store.LoadRotation("test|store")
+	if err != nil || !ok || !reflect.DeepEqual(got, state) {
+		t.Fatalf("LoadRotation = %+v, %v, %v; want %+v", got, ok, err, state)
+	}
+
+	// A slow save of an older state does not overwrite a newer one
+	older := state
+	older.Position, older.Version = 2, 99
+	if err := store.SaveRotation("test|store", older); err != nil {
+		t.Fatal(err)
+	}
+	if got, _, _ := store.LoadRotation("test|store"); got.Position != 3 {
+		t.Errorf("older save overwrote the rotation: %+v", got)
+	}
+
+	if _, ok, err := store.LoadRotation("test|missing"); ok || err != nil {
+		t.Errorf("LoadRotation(missing) = %v, %v", ok, err)
+	}
+}
+
+func TestPruneRotations(t *testing.T) {
+	var store rotationStore
+	for _, key := range []string{"test|old", "test|new"} {
+		if err := store.SaveRotation(key, rotation.State{Size: 5, Version: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := database.GetDB().Exec(`UPDATE rotations SET updated_at = datetime('now', '-31 days') WHERE key = 'test|old'`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := database.PruneRotations(time.Now().Add(-sessionMaxAge), maxStoredRotations); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := store.LoadRotation("test|old"); ok {
+		t.Error("rotation unused for 31 days was kept")
+	}
+	if _, ok, _ := store.LoadRotation("test|new"); !ok {
+		t.Error("rotation used now was pruned")
+	}
+}
+
+func TestNoRepeatEndpoint(t *testing.T) {
+	// Author 1 has nine courage quotes
+	target := "/api/v1/quotes/random?norepeat=true&session=rotation-test&author=Author%201&category=courage"
+	seen := make(map[int]bool)
+	for len(seen) < 9 {
+		var items []quoteItem
+		resp := decodeResponse(t, get(t, target+"&count=4"), &items)
+		if resp.Rotation == nil || resp.Rotation.Total != 9 {
+			t.Fatalf("rotation %+v", resp.Rotation)
+		}
+		request := make(map[int]bool)
+		for _, item := range items {
+			if request[item.ID] {
+				t.Fatalf("request repeats item %d: %+v", item.ID, items)
+			}
+			request[item.ID] = true
+			if len(seen) < 9 {
+				if seen[item.ID] {
+					t.Fatalf("item %d repeated before the rotation ended", item.ID)
+				}
+				seen[item.ID] = true
+			}
+		}
+	}
+
+	// Session names of any length take the same room
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/quotes/random?norepeat=true&session="+strings.Repeat("x", 10000), nil)
+	if key := rotationClient(httptest.NewRecorder(), r); len(key) != len("session:")+32 {
+		t.Errorf("rotation key of a long session is %d bytes", len(key))
+	}
+
+	// Clients without a session get a cookie
+	w := get(t, "/api/v1/quotes/random?norepeat=true")
+	if w.Code != http.StatusOK || len(w.Result().Cookies()) != 1 || w.Result().Cookies()[0].Name != sessionCookieName {
+		t.Errorf("status %d, cookies %v", w.Code, w.Result().Cookies())
+	}
+}
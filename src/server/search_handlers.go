@@ -0,0 +1,152 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apimgr/quotes/src/collection"
+)
+
+const (
+	// defaultSearchLimit is the page size of search results when no limit is given
+	defaultSearchLimit = 50
+
+	// maxSearchLimit is the largest search page size a client may request
+	maxSearchLimit = 500
+)
+
+// SearchResult is a single ranked hit returned by the search endpoints
+type SearchResult struct {
+	Collection string          `json:"collection"`
+	Score      float64         `json:"score"`
+	Snippet    string          `json:"snippet"`
+	Item       collection.Item `json:"item"`
+}
+
+// SearchResponse is the data returned by the search endpoints
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Total   int            `json:"total"`
+	Results []SearchResult `json:"results"`
+}
+
+// searchHit is a hit tagged with the collection it came from
+type searchHit struct {
+	collection collection.Collection
+	hit        collection.SearchHit
+	terms      []string
+}
+
+// rankedHits runs query against collections and merges the hits, best first.
+// Each index scales its scores by the best score the query can reach in it,
+// so hits from collections of different sizes and vocabularies compare.
+func rankedHits(collections []collection.Collection, query string) []searchHit {
+	var hits []searchHit
+	for _, c := range collections {
+		results, terms := c.Search(query)
+		for _, hit := range results {
+			hits = append(hits, searchHit{collection: c, hit: hit, terms: terms})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].hit.Score > hits[j].hit.Score
+	})
+	return hits
+}
+
+// result builds the search result of a hit
+func (h searchHit) result() SearchResult {
+	info := h.collection.Info()
+	return SearchResult{
+		Collection: info.Name,
+		Score:      h.hit.Score,
+		Snippet:    collection.Snippet(info, h.hit.Item, h.terms),
+		Item:       h.hit.Item,
+	}
+}
+
+// handleCollectionSearch returns ranked full-text search results for one collection
+func handleCollectionSearch(c collection.Collection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondWithSearch(w, r, []collection.Collection{c})
+	}
+}
+
+// handleSearch returns ranked full-text search results across collections.
+// The optional collections parameter restricts the search to a comma-separated list.
+func handleSearch(w http.ResponseWriter, r *http.Request) {
+	collections := collection.All()
+	if names := r.URL.Query().Get("collections"); names != "" {
+		collections = nil
+		for _, name := range strings.Split(names, ",") {
+			c, ok := collection.Get(strings.TrimSpace(name))
+			if !ok {
+				respondWithError(w, http.StatusBadRequest, "Unknown collection: "+name)
+				return
+			}
+			collections = append(collections, c)
+		}
+	}
+
+	respondWithSearch(w, r, collections)
+}
+
+// respondWithSearch runs the query in the q parameter against collections
+// and sends one page of merged results
+func respondWithSearch(w http.ResponseWriter, r *http.Request, collections []collection.Collection) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		respondWithError(w, http.StatusBadRequest, "Query parameter q is required")
+		return
+	}
+
+	lq, err := parseListQuery(r, defaultSearchLimit, maxSearchLimit)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	hits := rankedHits(collections, query)
+	start, end, pagination, err := newPagination(r, lq, len(hits), func(i int) string {
+		return hits[i].collection.Info().Name + "/" + strconv.Itoa(hits[i].hit.Item.GetID())
+	})
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	results := make([]SearchResult, 0, end-start)
+	for _, h := range hits[start:end] {
+		results = append(results, h.result())
+	}
+
+	respondWithContent(w, r, APIResponse{
+		Success: true,
+		Data: SearchResponse{
+			Query:   query,
+			Total:   len(hits),
+			Results: results,
+		},
+		Pagination: pagination,
+	}, func() contentBody {
+		return searchContent(results)
+	})
+}
+
+// searchContent returns the content of search results: the collection,
+// score and snippet of each hit followed by the fields of its item
+func searchContent(results []SearchResult) contentBody {
+	records := make([]record, len(results))
+	for i, result := range results {
+		c, _ := collection.Get(result.Collection)
+		rec := itemRecord(c.Info(), result.Item, nil)
+		rec.fields = append([]recordField{
+			{"collection", result.Collection},
+			{"score", result.Score},
+			{"snippet", result.Snippet},
+		}, rec.fields...)
+		records[i] = rec
+	}
+	return contentBody{records: records}
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSearchMergesCollections(t *testing.T) {
+	var data struct {
+		Results []struct {
+			Collection string  `json:"collection"`
+			Score      float64 `json:"score"`
+		} `json:"results"`
+	}
+	decodeResponse(t, get(t, "/api/v1/search?q=7&limit=500"), &data)
+	if len(data.Results) == 0 {
+		t.Fatal("no results")
+	}
+	seen := make(map[string]bool)
+	for i, result := range data.Results {
+		seen[result.Collection] = true
+		if result.Score <= 0 || result.Score > 1 {
+			t.Errorf("result %d scores %v, want a score in (0, 1]", i, result.Score)
+		}
+		if i > 0 && result.Score > data.Results[i-1].Score {
+			t.Errorf("result %d scores %v, above the previous %v", i, result.Score, data.Results[i-1].Score)
+		}
+	}
+	if len(seen) < 2 {
+		t.Errorf("results come from %v, want several collections", seen)
+	}
+
+	if w := get(t, "/api/v1/search?q=life&collections=quotes,nope"); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown collection: status %d, want 400", w.Code)
+	}
+	if w := get(t, "/api/v1/quotes/search"); w.Code != http.StatusBadRequest {
+		t.Errorf("missing query: status %d, want 400", w.Code)
+	}
+}
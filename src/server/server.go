@@ -3,15 +3,29 @@
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/apimgr/quotes/src/database"
+	"github.com/apimgr/quotes/src/dns"
+	"github.com/apimgr/quotes/src/lineserver"
+	"github.com/apimgr/quotes/src/qotd"
+	"github.com/apimgr/quotes/src/quotes"
+	"github.com/apimgr/quotes/src/rotation"
+	"github.com/apimgr/quotes/src/weighting"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/httprate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 )
 
 //go:embed static templates
@@ -26,28 +40,97 @@
 
 // Server represents the HTTP server with SPEC-compliant configuration
 type Server struct {
-	router         *chi.Mux
-	port           string
-	address        string
-	settingsCache  map[string]interface{}
-	settingsMutex  sync.RWMutex
-	rateLimiters   map[string]*httprate.RateLimiter
-	server         *http.Server
+	router        *chi.Mux
+	port          string
+	address       string
+	ports         ProtocolPorts
+	settingsCache map[string]interface{}
+	settingsMutex sync.RWMutex
+	rateLimiters  map[string]*httprate.RateLimiter
+	rotations     *rotation.Manager
+	weights       *weighting.Manager
+	votes         *ratingVotes   // Recent ratings, one per client and item
+	streams       atomic.Int64   // Number of open event streams
+	sockets       *socketHub     // Connected WebSocket clients
+	graphql       *graphQLSchema // Schema of the GraphQL API
+	grpc          *grpc.Server   // gRPC QuotesService, health and reflection
+	grpcHealth    *health.Server // Serving status of the gRPC services
+	shutdown      chan struct{}  // Closed on shutdown to end long-lived streams
+	shutdownOnce  sync.Once      // Closes shutdown
+	stopOnce      sync.Once      // Stops the listeners next to HTTP
+	server        *http.Server
+	qotd          *qotd.Server
+	gopher        *lineserver.Server
+	finger        *lineserver.Server
+	dns           *dns.Server
 }
 
-// NewServer creates a new server instance with Chi router
-func NewServer(port, address string) *Server {
+// ProtocolPorts are the ports of the APIs served next to HTTP. An empty
+// port disables its API.
+type ProtocolPorts struct {
+	GRPC   string // gRPC QuotesService
+	QOTD   string // RFC 865 Quote of the Day, over TCP and UDP
+	Gopher string // RFC 1436 Gopher menus of the collections
+	Finger string // RFC 1288 Finger, answering with random items
+	DNS    string // TXT records of the items, over UDP and TCP
+}
+
+// NewServer creates a new server instance with Chi router, and the other
+// APIs on their ports
+func NewServer(port, address string, ports ProtocolPorts) *Server {
 	s := &Server{
 		router:        chi.NewRouter(),
 		port:          port,
 		address:       address,
+		ports:         ports,
 		settingsCache: make(map[string]interface{}),
 		rateLimiters:  make(map[string]*httprate.RateLimiter),
+		shutdown:      make(chan struct{}),
+		sockets:       newSocketHub(),
+		votes:         newRatingVotes(),
 	}
 
 	// Initialize default settings
 	s.initDefaultSettings()
 
+	// Items added or edited through the admin API
+	if database.GetDB() != nil {
+		applyItemEdits()
+	}
+
+	// No-repeat random rotations, optionally persisted in the database
+	var store rotation.Store
+	if s.settingsCache["rotation.persist"].(bool) {
+		store = rotationStore{}
+		go s.pruneRotations()
+	}
+	s.rotations = rotation.NewManager(s.settingsCache["rotation.capacity"].(int), store)
+
+	// Weighted random selection, persisted when the database is open
+	var weights weighting.Store
+	if database.GetDB() != nil {
+		weights = weightStore{}
+	}
+	s.weights = weighting.NewManager(
+		s.settingsCache["weighting.recent_window"].(int),
+		s.settingsCache["weighting.recent_penalty"].(float64),
+		weights,
+	)
+	if err := s.weights.Load(); err != nil {
+		log.Printf("⚠️  Warning: %v", err)
+	}
+
+	// GraphQL schema over the registered collections
+	var err error
+	if s.graphql, err = s.newGraphQLSchema(); err != nil {
+		log.Fatalf("Failed to build GraphQL schema: %v", err)
+	}
+
+	// gRPC API over the same collections, when enabled
+	if ports.GRPC != "" {
+		s.grpc, s.grpcHealth = s.newGRPCServer()
+	}
+
 	// Setup middleware and routes
 	s.setupMiddleware()
 	s.setupRoutes()
@@ -67,6 +150,9 @@ func (s *Server) initDefaultSettings() {
 	s.settingsCache["server.cors_headers"] = []string{"Content-Type", "Authorization"}
 	s.settingsCache["server.cors_credentials"] = false
 
+	// Proxies whose X-Forwarded-For and X-Real-IP headers are believed (default: loopback)
+	s.settingsCache["server.trusted_proxies"] = parseTrustedProxies(storedSetting("server.trusted_proxies", "127.0.0.0/8 ::1"))
+
 	// Rate limiting (default: enabled)
 	s.settingsCache["rate.enabled"] = true
 	s.settingsCache["rate.global_rps"] = 100
@@ -76,10 +162,68 @@ func (s *Server) initDefaultSettings() {
 	s.settingsCache["rate.admin_rps"] = 10
 	s.settingsCache["rate.admin_burst"] = 20
 
+	// No-repeat rotation (default: 10000 clients in memory, not persisted)
+	s.settingsCache["rotation.capacity"] = 10000
+	s.settingsCache["rotation.persist"] = storedBoolSetting("rotation.persist", false)
+
+	// Weighted random selection (default: off, last 100 picks kept 20% of the time)
+	s.settingsCache["random.weighted"] = storedBoolSetting("random.weighted", false)
+	s.settingsCache["weighting.recent_window"] = 100
+	s.settingsCache["weighting.recent_penalty"] = 0.2
+
+	// Event streams (default: 100 clients, reconnecting every hour)
+	s.settingsCache["stream.max_clients"] = 100
+	s.settingsCache["stream.max_duration"] = time.Hour
+
+	// WebSocket API (default: 200 clients, no API key required)
+	s.settingsCache["ws.max_clients"] = 200
+	s.settingsCache["ws.api_key"] = storedSetting("ws.api_key", "")
+
+	// Embeddable widget (default: any site may frame it)
+	s.settingsCache["embed.frame_ancestors"] = storedSetting("embed.frame_ancestors", "*")
+
+	// gRPC API (default: no token required)
+	s.settingsCache["grpc.require_auth"] = storedBoolSetting("grpc.require_auth", false)
+
+	// Quote of the Day (default: a random quote)
+	s.settingsCache["qotd.collection"] = storedSetting("qotd.collection", "quotes")
+	s.settingsCache["qotd.mode"] = storedSetting("qotd.mode", "random")
+
+	// Gopher (default: menus link to this host and the port listened on)
+	s.settingsCache["gopher.hostname"] = storedSetting("gopher.hostname", "")
+	s.settingsCache["gopher.port"] = storedSetting("gopher.port", "")
+
+	// DNS (names such as daily.quotes.internal)
+	s.settingsCache["dns.zone"] = storedSetting("dns.zone", "internal")
+
 	// Initialize rate limiters
 	s.rateLimiters["global"] = httprate.NewRateLimiter(100, time.Second)
 	s.rateLimiters["api"] = httprate.NewRateLimiter(50, time.Second)
 	s.rateLimiters["admin"] = httprate.NewRateLimiter(10, time.Second)
+	s.rateLimiters["rating"] = httprate.NewRateLimiter(10, time.Minute)
+}
+
+// storedSetting returns a setting saved through the admin API, or
+// defaultValue when it is unset or the database is not open
+func storedSetting(key, defaultValue string) string {
+	if database.GetDB() == nil {
+		return defaultValue
+	}
+	value, err := database.GetSetting(key)
+	if err != nil || value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// storedBoolSetting returns a boolean setting saved through the admin API,
+// or defaultValue when it is unset, invalid or the database is not open
+func storedBoolSetting(key string, defaultValue bool) bool {
+	enabled, err := strconv.ParseBool(storedSetting(key, strconv.FormatBool(defaultValue)))
+	if err != nil {
+		return defaultValue
+	}
+	return enabled
 }
 
 // setupMiddleware configures all middleware
@@ -90,15 +234,12 @@ func (s *Server) setupMiddleware() {
 	// Request ID middleware
 	s.router.Use(middleware.RequestID)
 
-	// Real IP middleware
-	s.router.Use(middleware.RealIP)
+	// Real IP middleware (only from trusted proxies)
+	s.router.Use(s.realIPMiddleware)
 
 	// Logger middleware
 	s.router.Use(middleware.Logger)
 
-	// Timeout middleware (60 seconds)
-	s.router.Use(middleware.Timeout(60 * time.Second))
-
 	// Throttle concurrent requests (max 1000)
 	s.router.Use(middleware.Throttle(1000))
 
@@ -112,10 +253,33 @@ func (s *Server) setupMiddleware() {
 	s.router.Use(s.rateLimitMiddleware("global"))
 }
 
+// contentSecurityPolicy is the Content-Security-Policy of every response
+const contentSecurityPolicy = "default-src 'self'; " +
+	"script-src 'self' 'unsafe-inline'; " +
+	"style-src 'self' 'unsafe-inline'; " +
+	"img-src 'self' data:; " +
+	"connect-src 'self'"
+
+// allowFramingMiddleware lets other sites frame a route. It replaces the
+// DENY frame options with a frame-ancestors directive listing the sources
+// of the embed.frame_ancestors setting, since X-Frame-Options cannot name
+// several origins.
+func (s *Server) allowFramingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.settingsMutex.RLock()
+		ancestors := s.settingsCache["embed.frame_ancestors"].(string)
+		s.settingsMutex.RUnlock()
+
+		w.Header().Del("X-Frame-Options")
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy+"; frame-ancestors "+ancestors)
+		next.ServeHTTP(w, r)
+	})
+}
+
 // securityHeadersMiddleware adds security headers to all responses
 func (s *Server) securityHeadersMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Prevent clickjacking
+		// Prevent clickjacking; routes meant to be framed override this
 		w.Header().Set("X-Frame-Options", "DENY")
 
 		// Prevent MIME sniffing
@@ -125,12 +289,7 @@ func (s *Server) securityHeadersMiddleware(next http.Handler) http.Handler {
 		w.Header().Set("X-XSS-Protection", "1; mode=block")
 
 		// Content Security Policy
-		w.Header().Set("Content-Security-Policy",
-			"default-src 'self'; "+
-			"script-src 'self' 'unsafe-inline'; "+
-			"style-src 'self' 'unsafe-inline'; "+
-			"img-src 'self' data:; "+
-			"connect-src 'self'")
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy)
 
 		// Referrer Policy
 		w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
@@ -230,74 +389,105 @@ func (s *Server) rateLimitMiddleware(limiterName string) func(http.Handler) http
 	}
 }
 
+// requestTimeout is how long requests may take, except event streams and
+// WebSocket connections, which stay open and manage their own write deadlines
+const requestTimeout = 60 * time.Second
+
 // setupRoutes configures all the routes
 func (s *Server) setupRoutes() {
 	// API routes with stricter rate limiting
 	s.router.Route("/api/v1", func(r chi.Router) {
 		r.Use(s.rateLimitMiddleware("api"))
 
-		// Quotes endpoints
-		r.Get("/random", handleRandomQuote)
-		r.Get("/quotes", handleAllQuotes)
-		r.Get("/quotes/{id:[0-9]+}", handleQuoteByID)
-		r.Get("/quotes/category/{category}", handleQuotesByCategory)
-		r.Get("/quotes/author/{author}", handleQuotesByAuthor)
-		r.Get("/status", handleStatus)
-
-		// Anime endpoints
-		r.Get("/anime", handleAllAnimeQuotes)
-		r.Get("/anime/random", handleRandomAnimeQuote)
-		r.Get("/anime/{id:[0-9]+}", handleAnimeQuoteByID)
-		r.Get("/anime/category/{category}", handleAnimeQuotesByCategory)
-		r.Get("/anime/show/{anime}", handleAnimeQuotesByAnime)
-		r.Get("/anime/character/{character}", handleAnimeQuotesByCharacter)
-
-		// Chuck Norris endpoints
-		r.Get("/chucknorris", handleAllChuckNorrisJokes)
-		r.Get("/chucknorris/random", handleRandomChuckNorrisJoke)
-
-		// Dad Jokes endpoints
-		r.Get("/dadjokes", handleAllDadJokes)
-		r.Get("/dadjokes/random", handleRandomDadJoke)
-
-		// Programming Jokes endpoints
-		r.Get("/programming", handleAllProgrammingJokes)
-		r.Get("/programming/random", handleRandomProgrammingJoke)
-
-		// JSON file endpoints
-		r.Get("/{file:.*\\.json}", handleJSONFile)
-
-		// Admin routes (most restrictive rate limiting)
-		r.Route("/admin", func(r chi.Router) {
-			r.Use(s.rateLimitMiddleware("admin"))
-			r.Use(authMiddleware)
-			r.Get("/settings", handleGetSettings)
-			r.Post("/settings", handleSetSetting)
-			r.Delete("/settings/{key}", handleDeleteSetting)
+		// Event streams
+		r.Get("/stream", s.handleCollectionStream(quotes.Collection))
+		for _, c := range collection.All() {
+			r.Get("/"+c.Info().Name+"/stream", s.handleCollectionStream(c))
+		}
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.Timeout(requestTimeout))
+
+			// Default collection and status endpoints
+			r.With(formatMiddleware).Get("/random", s.handleCollectionRandom(quotes.Collection))
+			r.With(formatMiddleware).Get("/daily", handleCollectionPeriodic(quotes.Collection, collection.Daily))
+			r.Get("/status", handleStatus)
+			r.Get("/collections", handleCollections)
+			r.With(formatMiddleware).Get("/search", handleSearch)
+			r.Get("/graphql", s.handleGraphQL)
+			r.Post("/graphql", s.handleGraphQL)
+			for kind := range cardFormats {
+				r.Get("/random/card."+kind, s.handleCollectionRandomCard(quotes.Collection, kind))
+				r.Get("/daily/card."+kind, handleCollectionPeriodicCard(quotes.Collection, collection.Daily, kind))
+			}
+
+			// Collection endpoints
+			for _, c := range collection.All() {
+				s.mountCollection(r, c)
+			}
+
+			// JSON file endpoints
+			r.Get("/{file:.*\\.json}", handleJSONFile)
+
+			// Admin routes (most restrictive rate limiting)
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(s.rateLimitMiddleware("admin"))
+				r.Use(authMiddleware)
+				r.Get("/settings", handleGetSettings)
+				r.Post("/settings", handleSetSetting)
+				r.Delete("/settings/{key}", handleDeleteSetting)
+				r.Get("/weights/{collection}", s.handleGetWeights)
+				r.Put("/weights/{collection}/{id:[0-9]+}", s.handleSetWeight)
+				r.Delete("/weights/{collection}/{id:[0-9]+}", s.handleDeleteWeight)
+				r.Post("/items/{collection}", s.handleAddItem)
+				r.Put("/items/{collection}/{id:[0-9]+}", s.handleUpdateItem)
+			})
 		})
 	})
 
-	// Shorthand routes (without /api/v1 prefix)
-	s.router.Get("/anime", handleAllAnimeQuotes)
-	s.router.Get("/anime/random", handleRandomAnimeQuote)
-	s.router.Get("/chucknorris", handleAllChuckNorrisJokes)
-	s.router.Get("/chucknorris/random", handleRandomChuckNorrisJoke)
-	s.router.Get("/dadjokes", handleAllDadJokes)
-	s.router.Get("/dadjokes/random", handleRandomDadJoke)
-	s.router.Get("/programming", handleAllProgrammingJokes)
-	s.router.Get("/programming/random", handleRandomProgrammingJoke)
-
-	// Static files
-	fileServer := http.FileServer(http.FS(content))
-	s.router.Handle("/static/*", http.StripPrefix("/static/", fileServer))
-
-	// Web UI routes
-	s.router.Get("/", handleHome)
-	s.router.Get("/admin", handleAdminPage)
-
-	// Health check
-	s.router.Get("/health", handleHealth)
-	s.router.Get("/healthz", handleHealth)
+	// WebSocket API
+	s.router.Get("/ws", s.handleWebSocket)
+
+	s.router.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(requestTimeout))
+
+		// Shorthand routes (without /api/v1 prefix), which the general quotes
+		// collection never had
+		for _, c := range collection.All() {
+			name := c.Info().Name
+			if c != quotes.Collection {
+				r.With(formatMiddleware).Get("/"+name, handleCollectionAll(c))
+				r.With(formatMiddleware).Get("/"+name+"/random", s.handleCollectionRandom(c))
+			}
+
+			for kind := range feedFormats {
+				r.Get("/feeds/"+name+"."+kind, handleCollectionFeed(c, kind))
+			}
+		}
+
+		// Embeddable widget and oEmbed provider
+		r.Get("/widget.js", handleWidgetScript)
+		r.Get("/oembed", handleOEmbed)
+		r.Route("/embed", func(r chi.Router) {
+			r.Use(s.allowFramingMiddleware)
+			for _, c := range collection.All() {
+				r.Get("/"+c.Info().Name, handleEmbed(c))
+				r.Get("/"+c.Info().Name+"/{id:[0-9]+}", handleEmbed(c))
+			}
+		})
+
+		// Static files
+		fileServer := http.FileServer(http.FS(content))
+		r.Handle("/static/*", http.StripPrefix("/static/", fileServer))
+
+		// Web UI routes
+		r.Get("/", handleHome)
+		r.Get("/admin", handleAdminPage)
+
+		// Health check
+		r.Get("/health", handleHealth)
+		r.Get("/healthz", handleHealth)
+	})
 }
 
 // Start starts the HTTP server with graceful shutdown support
@@ -319,17 +509,120 @@ func (s *Server) Start() error {
 	log.Printf("Web UI: http://%s/", addr)
 	log.Printf("Admin panel: http://%s/admin", addr)
 
-	return s.server.ListenAndServe()
+	// A listener that fails to start stops those started before it
+	if err := s.startListeners(); err != nil {
+		s.stopListeners(context.Background())
+		return err
+	}
+
+	err := s.server.ListenAndServe()
+	if !errors.Is(err, http.ErrServerClosed) {
+		s.stopListeners(context.Background())
+	}
+	return err
 }
 
-// Shutdown gracefully shuts down the server
+// startListeners starts the enabled APIs served next to HTTP
+func (s *Server) startListeners() error {
+	if s.ports.GRPC != "" {
+		if err := s.startGRPC(); err != nil {
+			return err
+		}
+	}
+	if s.ports.QOTD != "" {
+		if err := s.startQOTD(); err != nil {
+			return err
+		}
+	}
+	if s.ports.Gopher != "" {
+		if err := s.startGopher(); err != nil {
+			return err
+		}
+	}
+	if s.ports.Finger != "" {
+		if err := s.startFinger(); err != nil {
+			return err
+		}
+	}
+	if s.ports.DNS != "" {
+		if err := s.startDNS(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startGRPC listens on the gRPC port and serves the gRPC API in the
+// background. Clients connect without TLS, as they do to any plaintext gRPC
+// server; put a TLS-terminating proxy in front for TLS.
+func (s *Server) startGRPC() error {
+	addr := net.JoinHostPort(s.address, s.ports.GRPC)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for gRPC on %s: %w", addr, err)
+	}
+
+	log.Printf("gRPC API listening on %s", addr)
+	go func() {
+		if err := s.grpc.Serve(ln); err != nil {
+			log.Printf("⚠️  Warning: gRPC server stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown gracefully shuts down the server. It may be called more than once.
 func (s *Server) Shutdown(ctx context.Context) error {
+	s.shutdownOnce.Do(func() { close(s.shutdown) })
+	s.stopListeners(ctx)
 	if s.server != nil {
 		return s.server.Shutdown(ctx)
 	}
 	return nil
 }
 
+// stopListeners stops the APIs served next to HTTP that were started,
+// once; later calls do nothing
+func (s *Server) stopListeners(ctx context.Context) {
+	s.stopOnce.Do(func() { s.closeListeners(ctx) })
+}
+
+// closeListeners closes the gRPC, QOTD, Gopher, Finger and DNS servers
+func (s *Server) closeListeners(ctx context.Context) {
+	if s.grpc != nil {
+		s.grpcHealth.Shutdown()
+		stopped := make(chan struct{})
+		go func() {
+			s.grpc.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			log.Printf("⚠️  Warning: gRPC server shutdown: %v", ctx.Err())
+			s.grpc.Stop()
+		}
+	}
+	if s.qotd != nil {
+		if err := s.qotd.Close(); err != nil {
+			log.Printf("⚠️  Warning: QOTD server shutdown: %v", err)
+		}
+	}
+	for name, ls := range map[string]*lineserver.Server{"Gopher": s.gopher, "Finger": s.finger} {
+		if ls == nil {
+			continue
+		}
+		if err := ls.Close(); err != nil {
+			log.Printf("⚠️  Warning: %s server shutdown: %v", name, err)
+		}
+	}
+	if s.dns != nil {
+		if err := s.dns.Close(); err != nil {
+			log.Printf("⚠️  Warning: DNS server shutdown: %v", err)
+		}
+	}
+}
+
 // handleHealth handles health check requests
 func handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
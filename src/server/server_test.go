@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apimgr/quotes/src/anime"
+	"github.com/apimgr/quotes/src/chucknorris"
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/apimgr/quotes/src/dadjokes"
+	"github.com/apimgr/quotes/src/database"
+	"github.com/apimgr/quotes/src/programming"
+	"github.com/apimgr/quotes/src/quotes"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// testAdminToken is the token of the admin created for the tests
+const testAdminToken = "test-admin-token-0123456789abcdef"
+
+// testServer is the server the tests send requests to, over the embedded
+// datasets and a database in a temporary directory
+var testServer *Server
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	middleware.DefaultLogger = middleware.RequestLogger(&middleware.DefaultLogFormatter{Logger: log.New(io.Discard, "", 0)})
+
+	dir, err := os.MkdirTemp("", "quotes-server-test")
+	if err != nil {
+		log.Fatal(err)
+	}
+	os.Setenv("CONFIG_DIR", dir)
+	os.Setenv("DATA_DIR", dir)
+	if err := database.InitDB(filepath.Join(dir, "quotes.db")); err != nil {
+		log.Fatal(err)
+	}
+	if err := database.CreateAdmin("administrator", "password", testAdminToken); err != nil {
+		log.Fatal(err)
+	}
+
+	datasets := map[string]collection.Collection{
+		"quotes.json":      quotes.Collection,
+		"anime.json":       anime.Collection,
+		"chucknorris.json": chucknorris.Collection,
+		"dadjokes.json":    dadjokes.Collection,
+		"programming.json": programming.Collection,
+	}
+	for _, file := range []string{"quotes.json", "anime.json", "chucknorris.json", "dadjokes.json", "programming.json"} {
+		data, err := os.ReadFile(filepath.Join("..", "data", file))
+		if err != nil {
+			log.Fatal(err)
+		}
+		c := datasets[file]
+		if err := c.Load(data); err != nil {
+			log.Fatal(err)
+		}
+		if err := collection.Register(c); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	testServer = NewServer("0", "127.0.0.1", ProtocolPorts{})
+	// Tests send many requests from the same address
+	testServer.settingsCache["rate.enabled"] = false
+
+	code := m.Run()
+	database.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// serve sends a request to the test server and returns the response
+func serve(t *testing.T, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, r)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	testServer.router.ServeHTTP(w, req)
+	return w
+}
+
+// get sends a GET request to the test server
+func get(t *testing.T, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	return serve(t, http.MethodGet, target, "", nil)
+}
+
+// adminHeader returns the headers of a request authenticated as the test admin
+func adminHeader() http.Header {
+	return http.Header{
+		"Authorization": {"Bearer " + testAdminToken},
+		"Content-Type":  {"application/json"},
+	}
+}
+
+// decodeResponse decodes a JSON response envelope and its data into data
+func decodeResponse(t *testing.T, w *httptest.ResponseRecorder, data interface{}) APIResponse {
+	t.Helper()
+	var resp APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding %q: %v", w.Body.String(), err)
+	}
+	if data != nil {
+		raw, err := json.Marshal(resp.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(raw, data); err != nil {
+			t.Fatalf("decoding data %s: %v", raw, err)
+		}
+	}
+	return resp
+}
+
+func TestShorthandRoutes(t *testing.T) {
+	for _, path := range []string{"/anime", "/anime/random", "/chucknorris/random", "/dadjokes", "/programming/random"} {
+		if w := get(t, path); w.Code != http.StatusOK {
+			t.Errorf("GET %s = %d, want 200", path, w.Code)
+		}
+	}
+	// The general quotes collection has no shorthand routes
+	for _, path := range []string{"/quotes", "/quotes/random"} {
+		if w := get(t, path); w.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, w.Code)
+		}
+	}
+}
+
+// freePort returns a TCP port nothing listens on
+func freePort(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	return port
+}
+
+func TestStartFailureStopsListeners(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+	_, busyPort, _ := net.SplitHostPort(busy.Addr().String())
+
+	ports := ProtocolPorts{GRPC: freePort(t), QOTD: freePort(t), Gopher: busyPort}
+	srv := NewServer(freePort(t), "127.0.0.1", ports)
+	if err := srv.Start(); err == nil || !strings.Contains(err.Error(), "Gopher") {
+		t.Fatalf("Start() = %v, want a Gopher listen error", err)
+	}
+
+	// The gRPC and QOTD ports were released
+	for _, port := range []string{ports.GRPC, ports.QOTD} {
+		ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", port))
+		if err != nil {
+			t.Errorf("port %s is still in use: %v", port, err)
+			continue
+		}
+		ln.Close()
+	}
+
+	// Shutting down again, as a deferred cleanup would, is harmless
+	for i := 0; i < 2; i++ {
+		if err := srv.Shutdown(context.Background()); err != nil {
+			t.Errorf("Shutdown() #%d = %v", i+1, err)
+		}
+	}
+}
+
+func TestGRPCDisabled(t *testing.T) {
+	if testServer.grpc != nil || testServer.grpcHealth != nil {
+		t.Error("the gRPC server is built although its port is disabled")
+	}
+}
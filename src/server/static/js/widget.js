@@ -0,0 +1,53 @@
+/**
+ * Quotes API - Embeddable widget
+ * Replaces its own script tag with a widget iframe:
+ *
+ *   <script src="https://quotes.example.com/widget.js"
+ *           data-collection="quotes" data-category="wisdom"
+ *           data-theme="dark" data-refresh="60" async></script>
+ */
+
+(function() {
+  const script = document.currentScript;
+  if (!script) {
+    return;
+  }
+
+  const origin = new URL(script.src).origin;
+  const collection = script.dataset.collection || 'quotes';
+
+  const params = new URLSearchParams();
+  for (const name of ['category', 'theme', 'refresh']) {
+    if (script.dataset[name]) {
+      params.set(name, script.dataset[name]);
+    }
+  }
+
+  let src = `${origin}/embed/${encodeURIComponent(collection)}`;
+  if (script.dataset.id) {
+    src += `/${encodeURIComponent(script.dataset.id)}`;
+  }
+  if (params.toString()) {
+    src += `?${params}`;
+  }
+
+  const iframe = document.createElement('iframe');
+  iframe.src = src;
+  iframe.title = 'Quotes API widget';
+  iframe.loading = 'lazy';
+  iframe.style.border = '0';
+  iframe.style.width = script.dataset.width || '100%';
+  iframe.style.height = '200px';
+
+  // The widget page reports its height whenever its content changes
+  window.addEventListener('message', function(event) {
+    if (event.origin !== origin || event.source !== iframe.contentWindow) {
+      return;
+    }
+    if (event.data && event.data.type === 'quotes-widget:height') {
+      iframe.style.height = `${event.data.height}px`;
+    }
+  });
+
+  script.parentNode.insertBefore(iframe, script);
+})();
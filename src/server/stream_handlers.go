@@ -0,0 +1,268 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/apimgr/quotes/src/sse"
+)
+
+const (
+	// defaultStreamInterval, minStreamInterval and maxStreamInterval bound
+	// the time between random items of a stream
+	defaultStreamInterval = 30 * time.Second
+	minStreamInterval     = 5 * time.Second
+	maxStreamInterval     = time.Hour
+
+	// streamWriteTimeout is how long one event may take to reach the client
+	streamWriteTimeout = 10 * time.Second
+
+	// streamRetry is the reconnection delay suggested to clients
+	streamRetry = 5 * time.Second
+)
+
+// streamHeartbeat is the time between heartbeat comments, a variable so
+// tests need not wait for it
+var streamHeartbeat = 15 * time.Second
+
+// streamPosition is where a stream is in its sequence of random items and
+// which period it last announced. It is sent as the event ID, so a client
+// reconnecting with Last-Event-ID continues the same sequence.
+type streamPosition struct {
+	seed      uint64
+	seq       uint64
+	periodKey string
+}
+
+// String encodes the position as an event ID, e.g. "42/7/2025-10-14"
+func (p streamPosition) String() string {
+	return fmt.Sprintf("%d/%d/%s", p.seed, p.seq, p.periodKey)
+}
+
+// parseStreamPosition decodes an event ID sent back in Last-Event-ID
+func parseStreamPosition(id string) (streamPosition, bool) {
+	parts := strings.SplitN(id, "/", 3)
+	if len(parts) != 3 {
+		return streamPosition{}, false
+	}
+	seed, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return streamPosition{}, false
+	}
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return streamPosition{}, false
+	}
+	return streamPosition{seed: seed, seq: seq, periodKey: parts[2]}, true
+}
+
+// parseStreamInterval reads the interval parameter, in seconds
+func parseStreamInterval(r *http.Request) (time.Duration, error) {
+	v := r.URL.Query().Get("interval")
+	if v == "" {
+		return defaultStreamInterval, nil
+	}
+
+	seconds, err := strconv.Atoi(v)
+	interval := time.Duration(seconds) * time.Second
+	if err != nil || interval < minStreamInterval || interval > maxStreamInterval {
+		return 0, fmt.Errorf("interval must be between %d and %d seconds",
+			int(minStreamInterval.Seconds()), int(maxStreamInterval.Seconds()))
+	}
+	return interval, nil
+}
+
+// parseStreamPeriod reads the period parameter, defaulting to daily
+func parseStreamPeriod(r *http.Request) (collection.Period, error) {
+	v := r.URL.Query().Get("period")
+	if v == "" {
+		return collection.Daily, nil
+	}
+	for _, period := range collection.Periods {
+		if string(period) == v {
+			return period, nil
+		}
+	}
+	return "", fmt.Errorf("period must be hourly, daily or weekly")
+}
+
+// acquireStream reserves a stream slot, failing when stream.max_clients
+// streams are already open
+func (s *Server) acquireStream() bool {
+	s.settingsMutex.RLock()
+	limit := s.settingsCache["stream.max_clients"].(int)
+	s.settingsMutex.RUnlock()
+
+	if s.streams.Add(1) > int64(limit) {
+		s.streams.Add(-1)
+		return false
+	}
+	return true
+}
+
+// handleCollectionStream is a Server-Sent Events stream pushing a random
+// item, honouring the filter parameters of the random endpoint, every
+// interval seconds. The item of the period is sent on connect and again as
+// soon as the period changes. Streams close after stream.max_duration; the
+// client then reconnects with Last-Event-ID and carries on where it was.
+func (s *Server) handleCollectionStream(c collection.Collection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		info := c.Info()
+		filter, err := parseFilter(r, info)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		interval, err := parseStreamInterval(r)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		period, err := parseStreamPeriod(r)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		t, err := parsePeriodTime(r)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		loc := t.Location()
+
+		// Reject filters that match nothing before opening the stream
+		if _, err := collection.RandomItems(c, filter, 1, nil); errors.Is(err, collection.ErrNoMatch) {
+			respondWithError(w, http.StatusNotFound, fmt.Sprintf("No %s match the filters", info.Title))
+			return
+		}
+
+		pos, resumed := parseStreamPosition(r.Header.Get("Last-Event-ID"))
+		if !resumed {
+			if pos.seed, err = parseSeed(r); err != nil {
+				respondWithError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		if !s.acquireStream() {
+			w.Header().Set("Retry-After", strconv.Itoa(int(streamRetry.Seconds())))
+			respondWithError(w, http.StatusServiceUnavailable, "Too many open streams, try again later")
+			return
+		}
+		defer s.streams.Add(-1)
+
+		stream, err := sse.NewWriter(w, streamWriteTimeout)
+		if err != nil {
+			log.Printf("⚠️  Warning: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Streaming is not supported")
+			return
+		}
+
+		s.settingsMutex.RLock()
+		maxDuration := s.settingsCache["stream.max_duration"].(time.Duration)
+		s.settingsMutex.RUnlock()
+
+		weighted := s.isWeighted(r)
+
+		// sendItem sends the next random item of the sequence. Each item is
+		// drawn from its own generator, so a resumed stream need not replay
+		// the items before it.
+		sendItem := func() error {
+			pos.seq++
+			rng := collection.NewSeededRand(pos.seed ^ pos.seq*0x9e3779b97f4a7c15)
+			var items []collection.Item
+			var err error
+			if weighted {
+				items, err = s.weights.RandomItems(c, filter, 1, rng, false)
+			} else {
+				items, err = collection.RandomItems(c, filter, 1, rng)
+			}
+			if err != nil {
+				return err
+			}
+			data, err := json.Marshal(items[0])
+			if err != nil {
+				return err
+			}
+			return stream.Event(pos.String(), "item", data)
+		}
+
+		// sendPeriodic sends the item of the period containing now among the
+		// items passing the filter
+		sendPeriodic := func(now time.Time) error {
+			item, err := collection.PeriodicMatch(c, filter, period, now)
+			if err != nil {
+				return err
+			}
+			start, end := period.Bounds(now)
+			pos.periodKey = period.Key(now)
+			data, err := json.Marshal(PeriodicResponse{
+				Collection: info.Name,
+				Period:     string(period),
+				Key:        pos.periodKey,
+				Timezone:   loc.String(),
+				StartsAt:   start,
+				EndsAt:     end,
+				Item:       item,
+			})
+			if err != nil {
+				return err
+			}
+			return stream.Event(pos.String(), string(period), data)
+		}
+
+		now := time.Now().In(loc)
+		if err := stream.Retry(streamRetry); err != nil {
+			return
+		}
+		// A resumed stream only hears about the period if it changed meanwhile
+		if !resumed || pos.periodKey != period.Key(now) {
+			if err := sendPeriodic(now); err != nil {
+				return
+			}
+		}
+		if err := sendItem(); err != nil {
+			return
+		}
+
+		items := time.NewTicker(interval)
+		defer items.Stop()
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+		_, boundary := period.Bounds(now)
+		periodChange := time.NewTimer(time.Until(boundary))
+		defer periodChange.Stop()
+		expiry := time.NewTimer(maxDuration)
+		defer expiry.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-s.shutdown:
+				return
+			case <-expiry.C:
+				return
+			case <-items.C:
+				err = sendItem()
+			case <-heartbeat.C:
+				err = stream.Comment("heartbeat")
+			case <-periodChange.C:
+				now := time.Now().In(loc)
+				_, boundary := period.Bounds(now)
+				periodChange.Reset(time.Until(boundary))
+				err = sendPeriodic(now)
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// streamEvent is one event, comment or retry field read from an event stream
+type streamEvent struct {
+	id      string
+	name    string
+	data    string
+	comment string
+	retry   string
+}
+
+// openStream connects to an event stream of the test server and returns its
+// events as they arrive, until the test ends
+func openStream(t *testing.T, target string, header http.Header) (*http.Response, <-chan streamEvent) {
+	t.Helper()
+
+	ts := httptest.NewServer(testServer.router)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		ts.Close()
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+target, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan streamEvent)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+		scanner := bufio.NewScanner(resp.Body)
+		var event streamEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+				event = streamEvent{}
+			case strings.HasPrefix(line, ": "):
+				event.comment = strings.TrimPrefix(line, ": ")
+			case strings.HasPrefix(line, "retry: "):
+				event.retry = strings.TrimPrefix(line, "retry: ")
+			case strings.HasPrefix(line, "id: "):
+				event.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				event.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				if event.data != "" {
+					event.data += "\n"
+				}
+				event.data += strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return resp, events
+}
+
+// nextEvent returns the next event of a stream, failing after a timeout
+func nextEvent(t *testing.T, events <-chan streamEvent) streamEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("stream closed")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event within 5 seconds")
+	}
+	return streamEvent{}
+}
+
+func TestCollectionStream(t *testing.T) {
+	heartbeat := streamHeartbeat
+	streamHeartbeat = 50 * time.Millisecond
+	t.Cleanup(func() { streamHeartbeat = heartbeat })
+
+	// Author 1 has nine courage quotes
+	target := "/api/v1/quotes/stream?author=Author%201&category=courage&seed=9"
+	resp, events := openStream(t, target, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("Content-Type %q", got)
+	}
+
+	if event := nextEvent(t, events); event.retry != "5000" {
+		t.Errorf("first event %+v, want retry 5000", event)
+	}
+
+	daily := nextEvent(t, events)
+	if daily.name != "daily" {
+		t.Fatalf("second event %+v, want the daily item", daily)
+	}
+	var periodic periodicData
+	if err := json.Unmarshal([]byte(daily.data), &periodic); err != nil {
+		t.Fatalf("decoding %q: %v", daily.data, err)
+	}
+	if periodic.Item.Author != "Author 1" || periodic.Item.Category != "courage" {
+		t.Errorf("daily item %+v does not match the filter", periodic.Item)
+	}
+	if want := "9/0/" + periodic.Key; daily.id != want {
+		t.Errorf("daily event ID %q, want %q", daily.id, want)
+	}
+
+	item := nextEvent(t, events)
+	if item.name != "item" || item.id != "9/1/"+periodic.Key {
+		t.Fatalf("third event %+v, want item 1", item)
+	}
+	var quote quoteItem
+	if err := json.Unmarshal([]byte(item.data), &quote); err != nil {
+		t.Fatalf("decoding %q: %v", item.data, err)
+	}
+	if quote.Author != "Author 1" || quote.Category != "courage" {
+		t.Errorf("item %+v does not match the filter", quote)
+	}
+
+	if event := nextEvent(t, events); event.comment != "heartbeat" {
+		t.Errorf("fourth event %+v, want a heartbeat", event)
+	}
+
+	// A client resuming in the same period continues the item sequence
+	// without hearing about the period again
+	_, resumed := openStream(t, target, http.Header{"Last-Event-ID": {item.id}})
+	nextEvent(t, resumed)
+	if event := nextEvent(t, resumed); event.name != "item" || event.id != "9/2/"+periodic.Key {
+		t.Errorf("resumed stream sent %+v, want item 2", event)
+	}
+}
+
+func TestCollectionStreamErrors(t *testing.T) {
+	for _, target := range []string{
+		"/api/v1/quotes/stream?interval=1",
+		"/api/v1/quotes/stream?period=monthly",
+		"/api/v1/quotes/stream?tz=Mars/Base",
+	} {
+		if w := get(t, target); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", target, w.Code)
+		}
+	}
+	if w := get(t, "/api/v1/quotes/stream?author=Nobody"); w.Code != http.StatusNotFound {
+		t.Errorf("unmatched filter: status %d, want 404", w.Code)
+	}
+}
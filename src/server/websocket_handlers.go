@@ -0,0 +1,533 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/apimgr/quotes/src/websocket"
+)
+
+const (
+	// socketMaxMessageSize is the largest message a client may send
+	socketMaxMessageSize = 16 << 10
+
+	// socketPingInterval is the time between pings to the client
+	socketPingInterval = 30 * time.Second
+
+	// socketIdleTimeout is how long a client may send nothing, pongs included
+	socketIdleTimeout = 75 * time.Second
+
+	// socketSendQueue is the number of messages queued for a client before
+	// it is dropped as too slow
+	socketSendQueue = 64
+
+	// socketMessageRate and socketMessageBurst limit the requests of a client
+	socketMessageRate  = 10
+	socketMessageBurst = 20
+
+	// defaultSocketSearchLimit is the number of search results when no limit is given
+	defaultSocketSearchLimit = 10
+)
+
+// SocketRequest is a message sent by a WebSocket client. The ID is
+// optional and echoed in the reply; params hold strings or numbers.
+type SocketRequest struct {
+	ID         json.RawMessage            `json:"id,omitempty"`
+	Type       string                     `json:"type"`
+	Collection string                     `json:"collection,omitempty"`
+	Params     map[string]json.RawMessage `json:"params,omitempty"`
+}
+
+// SocketMessage is a message sent to a WebSocket client: a welcome, the
+// result or error of a request, or an event of a subscription
+type SocketMessage struct {
+	ID         json.RawMessage `json:"id,omitempty"`
+	Type       string          `json:"type"`
+	Event      string          `json:"event,omitempty"`
+	Collection string          `json:"collection,omitempty"`
+	Data       interface{}     `json:"data,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// SocketWelcome is the data of the welcome message sent on connect
+type SocketWelcome struct {
+	Version     string   `json:"version"`
+	Collections []string `json:"collections"`
+}
+
+// SocketSubscription is the data of a subscribe result
+type SocketSubscription struct {
+	Collection string `json:"collection"`
+	Interval   int    `json:"interval,omitempty"`
+}
+
+// socketHub keeps track of the connected WebSocket clients
+type socketHub struct {
+	mutex   sync.Mutex
+	clients map[*socketClient]struct{}
+}
+
+// newSocketHub creates an empty hub
+func newSocketHub() *socketHub {
+	return &socketHub{clients: make(map[*socketClient]struct{})}
+}
+
+// add registers a client, failing when limit clients are already connected
+func (h *socketHub) add(c *socketClient, limit int) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if len(h.clients) >= limit {
+		return false
+	}
+	h.clients[c] = struct{}{}
+	return true
+}
+
+// remove unregisters a client
+func (h *socketHub) remove(c *socketClient) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.clients, c)
+}
+
+// itemChanged tells the clients subscribed to a collection, whose filter
+// matches the item, that an admin added or edited it
+func (h *socketHub) itemChanged(c collection.Collection, item collection.Item, added bool) {
+	event := "item.updated"
+	if added {
+		event = "item.added"
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for client := range h.clients {
+		if client.subscribed(c, item) {
+			client.send(SocketMessage{Type: "event", Event: event, Collection: c.Info().Name, Data: item})
+		}
+	}
+}
+
+// socketSubscription is a client's subscription to a collection
+type socketSubscription struct {
+	filter collection.Filter
+	stop   chan struct{} // Closed to end the random item ticker, if any
+}
+
+// socketClient is one WebSocket connection
+type socketClient struct {
+	server   *Server
+	conn     *websocket.Conn
+	outbox   chan []byte
+	done     chan struct{}
+	doneOnce sync.Once
+
+	mutex         sync.Mutex
+	subscriptions map[string]*socketSubscription
+
+	tokens   float64
+	lastSeen time.Time
+}
+
+// newSocketClient creates a client, whose connection is set once upgraded
+func newSocketClient(s *Server) *socketClient {
+	return &socketClient{
+		server:        s,
+		outbox:        make(chan []byte, socketSendQueue),
+		done:          make(chan struct{}),
+		subscriptions: make(map[string]*socketSubscription),
+		tokens:        socketMessageBurst,
+		lastSeen:      time.Now(),
+	}
+}
+
+// send queues a message, dropping the client when its queue is full
+func (c *socketClient) send(msg SocketMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("⚠️  Warning: failed to encode WebSocket message: %v", err)
+		return
+	}
+	select {
+	case c.outbox <- data:
+	default:
+		c.conn.Close()
+	}
+}
+
+// stop ends the client's goroutines and subscriptions
+func (c *socketClient) stop() {
+	c.doneOnce.Do(func() {
+		close(c.done)
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		for name, sub := range c.subscriptions {
+			if sub.stop != nil {
+				close(sub.stop)
+			}
+			delete(c.subscriptions, name)
+		}
+	})
+}
+
+// subscribed reports whether the client wants to hear about an item
+func (c *socketClient) subscribed(coll collection.Collection, item collection.Item) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	sub, ok := c.subscriptions[coll.Info().Name]
+	return ok && sub.filter.Matches(coll.Info(), item)
+}
+
+// allow takes a token from the client's message budget
+func (c *socketClient) allow() bool {
+	now := time.Now()
+	c.tokens = min(socketMessageBurst, c.tokens+now.Sub(c.lastSeen).Seconds()*socketMessageRate)
+	c.lastSeen = now
+	if c.tokens < 1 {
+		return false
+	}
+	c.tokens--
+	return true
+}
+
+// writeLoop sends queued messages and pings until the client is done,
+// closing the connection when the server shuts down
+func (c *socketClient) writeLoop() {
+	ping := time.NewTicker(socketPingInterval)
+	defer ping.Stop()
+
+	for {
+		var err error
+		select {
+		case <-c.done:
+			return
+		case <-c.server.shutdown:
+			c.conn.WriteClose(websocket.CloseGoingAway, "server shutting down")
+			c.conn.Close()
+			return
+		case data := <-c.outbox:
+			err = c.conn.WriteMessage(websocket.OpText, data)
+		case <-ping.C:
+			err = c.conn.Ping(nil)
+		}
+		if err != nil {
+			c.conn.Close()
+			return
+		}
+	}
+}
+
+// readLoop answers requests until the connection closes
+func (c *socketClient) readLoop() {
+	for {
+		op, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if op != websocket.OpText {
+			c.conn.WriteClose(websocket.CloseUnsupportedData, "messages must be JSON text")
+			return
+		}
+
+		if !c.allow() {
+			c.send(SocketMessage{Type: "error", Error: "Rate limit exceeded"})
+			continue
+		}
+		var req SocketRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			c.send(SocketMessage{Type: "error", Error: "Invalid message: must be a JSON object"})
+			continue
+		}
+		c.handle(req)
+	}
+}
+
+// socketParams converts request params to query values, so they are read
+// like the parameters of the REST endpoints
+func socketParams(params map[string]json.RawMessage) url.Values {
+	values := make(url.Values, len(params))
+	for name, raw := range params {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			s = string(raw)
+		}
+		values.Set(name, s)
+	}
+	return values
+}
+
+// handle answers one request
+func (c *socketClient) handle(req SocketRequest) {
+	reply := SocketMessage{ID: req.ID, Type: "result", Collection: req.Collection}
+	params := socketParams(req.Params)
+
+	var err error
+	switch req.Type {
+	case "ping":
+		reply.Type = "pong"
+	case "subscribe":
+		reply.Data, err = c.subscribe(req.Collection, params)
+	case "unsubscribe":
+		err = c.unsubscribe(req.Collection)
+	case "random":
+		reply.Data, err = c.random(req.Collection, params)
+	case "get":
+		reply.Data, err = c.get(req.Collection, params)
+	case "search":
+		reply.Data, err = c.search(req.Collection, params)
+	default:
+		err = fmt.Errorf("Unknown message type %q", req.Type)
+	}
+
+	if err != nil {
+		reply = SocketMessage{ID: req.ID, Type: "error", Collection: req.Collection, Error: err.Error()}
+	}
+	c.send(reply)
+}
+
+// socketCollection returns the collection named in a request
+func socketCollection(name string) (collection.Collection, error) {
+	if name == "" {
+		return nil, errors.New("collection is required")
+	}
+	coll, ok := collection.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("Unknown collection: %s", name)
+	}
+	return coll, nil
+}
+
+// subscribe starts or replaces the subscription to a collection. Filter
+// params narrow the events; interval pushes a random item every interval
+// seconds.
+func (c *socketClient) subscribe(name string, params url.Values) (interface{}, error) {
+	coll, err := socketCollection(name)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := filterFromValues(params, coll.Info())
+	if err != nil {
+		return nil, err
+	}
+
+	var interval time.Duration
+	if v := params.Get("interval"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		interval = time.Duration(seconds) * time.Second
+		if err != nil || interval < minStreamInterval || interval > maxStreamInterval {
+			return nil, fmt.Errorf("interval must be between %d and %d seconds",
+				int(minStreamInterval.Seconds()), int(maxStreamInterval.Seconds()))
+		}
+		if _, err := collection.RandomItems(coll, filter, 1, nil); errors.Is(err, collection.ErrNoMatch) {
+			return nil, fmt.Errorf("No %s match the filters", coll.Info().Title)
+		}
+	}
+
+	sub := &socketSubscription{filter: filter}
+	if interval > 0 {
+		sub.stop = make(chan struct{})
+		go c.pushRandom(coll, filter, interval, c.server.weightedParam(params.Get("weighted")), sub.stop)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if old, ok := c.subscriptions[name]; ok && old.stop != nil {
+		close(old.stop)
+	}
+	c.subscriptions[name] = sub
+	return SocketSubscription{Collection: name, Interval: int(interval.Seconds())}, nil
+}
+
+// pushRandom sends a random item event every interval until stopped
+func (c *socketClient) pushRandom(coll collection.Collection, filter collection.Filter, interval time.Duration, weighted bool, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-c.done:
+			return
+		case <-ticker.C:
+			var items []collection.Item
+			var err error
+			if weighted {
+				items, err = c.server.weights.RandomItems(coll, filter, 1, nil, true)
+			} else {
+				items, err = collection.RandomItems(coll, filter, 1, nil)
+			}
+			if err != nil {
+				continue
+			}
+			c.send(SocketMessage{Type: "event", Event: "item.random", Collection: coll.Info().Name, Data: items[0]})
+		}
+	}
+}
+
+// unsubscribe ends the subscription to a collection
+func (c *socketClient) unsubscribe(name string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	sub, ok := c.subscriptions[name]
+	if !ok {
+		return fmt.Errorf("Not subscribed to %s", name)
+	}
+	if sub.stop != nil {
+		close(sub.stop)
+	}
+	delete(c.subscriptions, name)
+	return nil
+}
+
+// random returns random items like the random endpoint: one item, or a
+// list when count is given
+func (c *socketClient) random(name string, params url.Values) (interface{}, error) {
+	coll, err := socketCollection(name)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := filterFromValues(params, coll.Info())
+	if err != nil {
+		return nil, err
+	}
+
+	count := 0
+	if v := params.Get("count"); v != "" {
+		if count, err = strconv.Atoi(v); err != nil || count < 1 || count > maxRandomCount {
+			return nil, fmt.Errorf("count must be between 1 and %d", maxRandomCount)
+		}
+	}
+
+	var items []collection.Item
+	if c.server.weightedParam(params.Get("weighted")) {
+		items, err = c.server.weights.RandomItems(coll, filter, max(count, 1), nil, true)
+	} else {
+		items, err = collection.RandomItems(coll, filter, max(count, 1), nil)
+	}
+	if errors.Is(err, collection.ErrNoMatch) {
+		return nil, fmt.Errorf("No %s match the filters", coll.Info().Title)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if count > 0 {
+		return items, nil
+	}
+	return items[0], nil
+}
+
+// get returns the item with the id param
+func (c *socketClient) get(name string, params url.Values) (interface{}, error) {
+	coll, err := socketCollection(name)
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.Atoi(params.Get("id"))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s ID", coll.Info().ItemName)
+	}
+	return coll.ItemByID(id)
+}
+
+// search returns the best search results for the q param, in one
+// collection or, without one, in all of them
+func (c *socketClient) search(name string, params url.Values) (interface{}, error) {
+	collections := collection.All()
+	if name != "" {
+		coll, err := socketCollection(name)
+		if err != nil {
+			return nil, err
+		}
+		collections = []collection.Collection{coll}
+	}
+
+	query := strings.TrimSpace(params.Get("q"))
+	if query == "" {
+		return nil, errors.New("q is required")
+	}
+	limit := defaultSocketSearchLimit
+	if v := params.Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > maxSearchLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", maxSearchLimit)
+		}
+	}
+
+	hits := rankedHits(collections, query)
+	results := make([]SearchResult, 0, min(limit, len(hits)))
+	for _, h := range hits[:min(limit, len(hits))] {
+		results = append(results, h.result())
+	}
+	return SearchResponse{Query: query, Total: len(hits), Results: results}, nil
+}
+
+// socketAuthorized reports whether a request carries the ws.api_key
+// setting, when one is configured. Browsers cannot set headers on
+// WebSocket requests, so the key may also be sent as the api_key parameter.
+func (s *Server) socketAuthorized(r *http.Request) bool {
+	s.settingsMutex.RLock()
+	want := s.settingsCache["ws.api_key"].(string)
+	s.settingsMutex.RUnlock()
+	if want == "" {
+		return true
+	}
+
+	got := requestAPIKey(r)
+	if got == "" {
+		got = r.URL.Query().Get("api_key")
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// handleWebSocket upgrades to a WebSocket speaking the JSON protocol of
+// SocketRequest and SocketMessage. Clients subscribe to collections to
+// hear about admin edits and optionally receive random items, and may make
+// random, get and search requests over the same connection.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !s.socketAuthorized(r) {
+		respondWithError(w, http.StatusUnauthorized, "Invalid or missing API key")
+		return
+	}
+
+	s.settingsMutex.RLock()
+	limit := s.settingsCache["ws.max_clients"].(int)
+	s.settingsMutex.RUnlock()
+
+	client := newSocketClient(s)
+	if !s.sockets.add(client, limit) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(streamRetry.Seconds())))
+		respondWithError(w, http.StatusServiceUnavailable, "Too many open connections, try again later")
+		return
+	}
+	defer s.sockets.remove(client)
+
+	conn, err := websocket.Upgrade(w, r, socketMaxMessageSize)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	defer client.stop()
+
+	client.conn = conn
+	conn.SetIdleTimeout(socketIdleTimeout)
+	go client.writeLoop()
+
+	names := make([]string, 0, len(collection.All()))
+	for _, coll := range collection.All() {
+		names = append(names, coll.Info().Name)
+	}
+	client.send(SocketMessage{Type: "welcome", Data: SocketWelcome{Version: Version, Collections: names}})
+
+	client.readLoop()
+}
@@ -0,0 +1,296 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apimgr/quotes/src/collection"
+	"github.com/apimgr/quotes/src/database"
+	"github.com/apimgr/quotes/src/weighting"
+	"github.com/go-chi/chi/v5"
+)
+
+// RatingInfo summarizes the user ratings of an item
+type RatingInfo struct {
+	ID      int     `json:"id"`
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+// weightStore persists item weights and ratings in the SQLite database
+type weightStore struct{}
+
+// LoadWeights returns all admin-assigned weights
+func (weightStore) LoadWeights() (map[string]map[int]float64, error) {
+	return database.GetItemWeights()
+}
+
+// SaveWeight saves the admin-assigned weight of an item
+func (weightStore) SaveWeight(name string, id int, weight float64) error {
+	return database.SetItemWeight(name, id, weight)
+}
+
+// DeleteWeight removes the admin-assigned weight of an item
+func (weightStore) DeleteWeight(name string, id int) error {
+	return database.DeleteItemWeight(name, id)
+}
+
+// LoadRatings returns all item ratings
+func (weightStore) LoadRatings() (map[string]map[int]weighting.Rating, error) {
+	stored, err := database.GetItemRatings()
+	if err != nil {
+		return nil, err
+	}
+
+	ratings := make(map[string]map[int]weighting.Rating, len(stored))
+	for name, byID := range stored {
+		ratings[name] = make(map[int]weighting.Rating, len(byID))
+		for id, rating := range byID {
+			ratings[name][id] = weighting.Rating{Sum: rating.Sum, Count: rating.Count}
+		}
+	}
+	return ratings, nil
+}
+
+// AddRating records one user rating of an item
+func (weightStore) AddRating(name string, id int, rating int) error {
+	return database.AddItemRating(name, id, rating)
+}
+
+// isWeighted reports whether a random request uses weighted selection,
+// following the weighted parameter or else the random.weighted setting
+func (s *Server) isWeighted(r *http.Request) bool {
+	return s.weightedParam(r.URL.Query().Get("weighted"))
+}
+
+// weightedParam reports whether a weighted parameter value asks for
+// weighted selection, following the random.weighted setting when it is empty
+func (s *Server) weightedParam(v string) bool {
+	if v != "" {
+		weighted, err := strconv.ParseBool(v)
+		return err == nil && weighted
+	}
+
+	s.settingsMutex.RLock()
+	defer s.settingsMutex.RUnlock()
+	return s.settingsCache["random.weighted"].(bool)
+}
+
+// randomItem picks a random item of c, weighted when the random.weighted
+// setting is on, for the listeners whose requests have no parameters
+func (s *Server) randomItem(c collection.Collection) (collection.Item, error) {
+	rng := collection.NewSeededRand(collection.NewSeed())
+	var items []collection.Item
+	var err error
+	if s.weightedParam("") {
+		items, err = s.weights.RandomItems(c, collection.Filter{}, 1, rng, true)
+	} else {
+		items, err = collection.RandomItems(c, collection.Filter{}, 1, rng)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+// ratingInfo builds the rating summary of an item
+func ratingInfo(id int, rating weighting.Rating) RatingInfo {
+	return RatingInfo{ID: id, Average: rating.Average(), Count: rating.Count}
+}
+
+// handleCollectionRating returns the user ratings of an item
+func (s *Server) handleCollectionRating(c collection.Collection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		item, ok := itemFromPath(w, r, c)
+		if !ok {
+			return
+		}
+
+		respondWithJSON(w, http.StatusOK, APIResponse{
+			Success: true,
+			Data:    ratingInfo(item.GetID(), s.weights.Rating(c.Info().Name, item.GetID())),
+		})
+	}
+}
+
+// handleCollectionRate records a user rating of an item
+func (s *Server) handleCollectionRate(c collection.Collection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		item, ok := itemFromPath(w, r, c)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			Rating int `json:"rating"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		if req.Rating < weighting.MinRating || req.Rating > weighting.MaxRating {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("rating must be between %d and %d", weighting.MinRating, weighting.MaxRating))
+			return
+		}
+		if !s.votes.allow(clientIP(r), c.Info().Name, item.GetID()) {
+			respondWithError(w, http.StatusConflict, "You already rated this item")
+			return
+		}
+
+		rating, err := s.weights.Rate(c.Info().Name, item.GetID(), req.Rating)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		respondWithJSON(w, http.StatusOK, APIResponse{
+			Success: true,
+			Data:    ratingInfo(item.GetID(), rating),
+		})
+	}
+}
+
+// adminCollection returns the collection named by the collection URL
+// parameter, responding with an error and false when there is none
+func adminCollection(w http.ResponseWriter, r *http.Request) (collection.Collection, bool) {
+	c, ok := collection.Get(chi.URLParam(r, "collection"))
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "Collection not found")
+	}
+	return c, ok
+}
+
+// handleGetWeights returns the admin-assigned weights of a collection
+func (s *Server) handleGetWeights(w http.ResponseWriter, r *http.Request) {
+	c, ok := adminCollection(w, r)
+	if !ok {
+		return
+	}
+
+	weights := s.weights.Weights(c.Info().Name)
+	data := make(map[string]float64, len(weights))
+	for id, weight := range weights {
+		data[strconv.Itoa(id)] = weight
+	}
+
+	respondWithJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// handleSetWeight assigns a weight to an item
+func (s *Server) handleSetWeight(w http.ResponseWriter, r *http.Request) {
+	c, ok := adminCollection(w, r)
+	if !ok {
+		return
+	}
+	item, ok := itemFromPath(w, r, c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Weight float64 `json:"weight"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Weight < weighting.MinWeight || req.Weight > weighting.MaxWeight {
+		respondWithError(w, http.StatusBadRequest,
+			fmt.Sprintf("Weight must be between %g and %g", float64(weighting.MinWeight), float64(weighting.MaxWeight)))
+		return
+	}
+	if err := s.weights.SetWeight(c.Info().Name, item.GetID(), req.Weight); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to set weight")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": "Weight updated successfully"},
+	})
+}
+
+// handleDeleteWeight restores the default weight of an item
+func (s *Server) handleDeleteWeight(w http.ResponseWriter, r *http.Request) {
+	c, ok := adminCollection(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s ID", c.Info().ItemName))
+		return
+	}
+
+	if err := s.weights.ResetWeight(c.Info().Name, id); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete weight")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"message": "Weight deleted successfully"},
+	})
+}
+
+// ratingCooldown is how long a client must wait to rate the same item again
+const ratingCooldown = 24 * time.Hour
+
+// maxRatingVotes bounds the votes remembered for the cooldown
+const maxRatingVotes = 100000
+
+// ratingVotes remembers recent votes, allowing one per client and item
+// within ratingCooldown
+type ratingVotes struct {
+	mutex sync.Mutex
+	votes map[string]time.Time // When each client last rated each item
+}
+
+// newRatingVotes creates an empty vote record
+func newRatingVotes() *ratingVotes {
+	return &ratingVotes{votes: make(map[string]time.Time)}
+}
+
+// allow records a vote of client for an item, reporting false when the
+// client has rated it within the cooldown or too many votes are remembered
+func (v *ratingVotes) allow(client, name string, id int) bool {
+	key := fmt.Sprintf("%s|%s|%d", client, name, id)
+	now := time.Now()
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if at, ok := v.votes[key]; ok && now.Sub(at) < ratingCooldown {
+		return false
+	}
+	if len(v.votes) >= maxRatingVotes {
+		for k, at := range v.votes {
+			if now.Sub(at) >= ratingCooldown {
+				delete(v.votes, k)
+			}
+		}
+		if len(v.votes) >= maxRatingVotes {
+			return false
+		}
+	}
+	v.votes[key] = now
+	return true
+}
+
+// clientIP returns the address of the client without its port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
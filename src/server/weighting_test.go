@@ -0,0 +1,108 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRatingEndpoints(t *testing.T) {
+	target := "/api/v1/quotes/77/rating"
+	// vote posts a rating from the peer address remote, forwarded for the
+	// addresses in forwardedFor when it is not empty
+	vote := func(remote, path, body, forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.RemoteAddr = remote + ":1234"
+		req.Header.Set("Content-Type", "application/json")
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		w := httptest.NewRecorder()
+		testServer.router.ServeHTTP(w, req)
+		return w
+	}
+	// client posts a rating through a reverse proxy on the loopback address
+	client := func(ip, path, body string) *httptest.ResponseRecorder {
+		return vote("127.0.0.1", path, body, ip)
+	}
+
+	var rating RatingInfo
+	if resp := decodeResponse(t, get(t, target), &rating); !resp.Success || rating.ID != 77 || rating.Count != 0 {
+		t.Fatalf("unrated item: %+v %+v", resp, rating)
+	}
+
+	for _, body := range []string{`{"rating": 0}`, `{"rating": 6}`, `not json`} {
+		if w := client("198.51.100.1", target, body); w.Code != http.StatusBadRequest {
+			t.Errorf("POST %s returned %d, want 400", body, w.Code)
+		}
+	}
+
+	// Invalid votes do not count towards the one vote per client
+	w := client("198.51.100.1", target, `{"rating": 5}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST returned %d: %s", w.Code, w.Body)
+	}
+	if decodeResponse(t, w, &rating); rating.Count != 1 || rating.Average != 5 {
+		t.Errorf("rating after one vote: %+v", rating)
+	}
+
+	if w := client("198.51.100.1", target, `{"rating": 1}`); w.Code != http.StatusConflict {
+		t.Errorf("repeated vote returned %d, want 409", w.Code)
+	}
+	if w := client("198.51.100.1", "/api/v1/quotes/78/rating", `{"rating": 1}`); w.Code != http.StatusOK {
+		t.Errorf("vote for another item returned %d, want 200", w.Code)
+	}
+	if w := client("198.51.100.2", target, `{"rating": 2}`); w.Code != http.StatusOK {
+		t.Errorf("vote from another client returned %d, want 200", w.Code)
+	}
+
+	// Forwarding headers only count from trusted proxies
+	if w := vote("203.0.113.1", target, `{"rating": 4}`, ""); w.Code != http.StatusOK {
+		t.Errorf("direct vote returned %d, want 200", w.Code)
+	}
+	if w := vote("203.0.113.1", target, `{"rating": 4}`, "198.51.100.9"); w.Code != http.StatusConflict {
+		t.Errorf("vote with a spoofed X-Forwarded-For returned %d, want 409", w.Code)
+	}
+	if w := client("203.0.113.1, 127.0.0.1", target, `{"rating": 4}`); w.Code != http.StatusConflict {
+		t.Errorf("vote relayed by two trusted proxies returned %d, want 409", w.Code)
+	}
+
+	decodeResponse(t, get(t, target), &rating)
+	if rating.Count != 3 || rating.Average != 11.0/3 {
+		t.Errorf("rating after three votes: %+v", rating)
+	}
+
+	if w := client("198.51.100.1", "/api/v1/quotes/999999/rating", `{"rating": 3}`); w.Code != http.StatusNotFound {
+		t.Errorf("vote for a missing item returned %d, want 404", w.Code)
+	}
+}
+
+func TestAdminWeights(t *testing.T) {
+	target := "/api/v1/admin/weights/quotes"
+	if w := serve(t, http.MethodPut, target+"/79", `{"weight": 0}`, adminHeader()); w.Code != http.StatusBadRequest {
+		t.Errorf("weight 0 returned %d, want 400", w.Code)
+	}
+	if w := serve(t, http.MethodPut, target+"/79", `{"weight": 3}`, adminHeader()); w.Code != http.StatusOK {
+		t.Fatalf("PUT returned %d: %s", w.Code, w.Body)
+	}
+
+	var weights map[string]float64
+	decodeResponse(t, serve(t, http.MethodGet, target, "", adminHeader()), &weights)
+	if weights["79"] != 3 {
+		t.Errorf("weights = %v, want item 79 weighted 3", weights)
+	}
+
+	if w := serve(t, http.MethodDelete, target+"/79", "", adminHeader()); w.Code != http.StatusOK {
+		t.Fatalf("DELETE returned %d: %s", w.Code, w.Body)
+	}
+	weights = nil
+	decodeResponse(t, serve(t, http.MethodGet, target, "", adminHeader()), &weights)
+	if _, ok := weights["79"]; ok {
+		t.Errorf("weights = %v, want item 79 reset", weights)
+	}
+
+	if w := serve(t, http.MethodGet, target, "", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated GET returned %d, want 401", w.Code)
+	}
+}
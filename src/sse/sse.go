@@ -0,0 +1,83 @@
+package sse
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ContentType is the media type of event streams
+const ContentType = "text/event-stream"
+
+// Writer writes Server-Sent Events to a response. Every write gets its own
+// deadline, overriding the server's write timeout, so a stream may stay open
+// indefinitely while a client that stops reading is still dropped.
+type Writer struct {
+	w            http.ResponseWriter
+	rc           *http.ResponseController
+	writeTimeout time.Duration
+}
+
+// NewWriter sends the event stream headers and returns a writer for the
+// stream. It fails when the response cannot be flushed incrementally.
+func NewWriter(w http.ResponseWriter, writeTimeout time.Duration) (*Writer, error) {
+	sw := &Writer{w: w, rc: http.NewResponseController(w), writeTimeout: writeTimeout}
+	if err := sw.extendDeadline(); err != nil {
+		return nil, fmt.Errorf("failed to set stream write deadline: %w", err)
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Keep reverse proxies from buffering events
+	w.WriteHeader(http.StatusOK)
+
+	if err := sw.rc.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to flush stream: %w", err)
+	}
+	return sw, nil
+}
+
+// extendDeadline moves the write deadline writeTimeout into the future
+func (sw *Writer) extendDeadline() error {
+	return sw.rc.SetWriteDeadline(time.Now().Add(sw.writeTimeout))
+}
+
+// write sends raw stream data and flushes it to the client
+func (sw *Writer) write(data string) error {
+	if err := sw.extendDeadline(); err != nil {
+		return err
+	}
+	if _, err := sw.w.Write([]byte(data)); err != nil {
+		return err
+	}
+	return sw.rc.Flush()
+}
+
+// Event sends an event. Empty id and name fields are left out; multi-line
+// data is split over several data fields as the format requires.
+func (sw *Writer) Event(id, name string, data []byte) error {
+	var b strings.Builder
+	if id != "" {
+		b.WriteString("id: " + id + "\n")
+	}
+	if name != "" {
+		b.WriteString("event: " + name + "\n")
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	return sw.write(b.String())
+}
+
+// Comment sends a comment line, which clients ignore; used as a heartbeat
+// to keep idle connections and proxies alive
+func (sw *Writer) Comment(text string) error {
+	return sw.write(": " + text + "\n\n")
+}
+
+// Retry tells the client how long to wait before reconnecting
+func (sw *Writer) Retry(d time.Duration) error {
+	return sw.write(fmt.Sprintf("retry: %d\n\n", d.Milliseconds()))
+}
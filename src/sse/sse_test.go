@@ -0,0 +1,121 @@
+package sse
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// deadlineRecorder is a response recorder that, like a server connection,
+// supports write deadlines
+type deadlineRecorder struct {
+	*httptest.ResponseRecorder
+	deadlines []time.Time
+	err       error // Returned by SetWriteDeadline when set
+}
+
+func (r *deadlineRecorder) SetWriteDeadline(t time.Time) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.deadlines = append(r.deadlines, t)
+	return nil
+}
+
+func newRecorder() *deadlineRecorder {
+	return &deadlineRecorder{ResponseRecorder: httptest.NewRecorder()}
+}
+
+func TestNewWriter(t *testing.T) {
+	rec := newRecorder()
+	start := time.Now()
+	if _, err := NewWriter(rec, 10*time.Second); err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+
+	if rec.Code != http.StatusOK || !rec.Flushed {
+		t.Errorf("status %d, flushed %v; want 200 sent at once", rec.Code, rec.Flushed)
+	}
+	for name, want := range map[string]string{
+		"Content-Type":      ContentType,
+		"Cache-Control":     "no-cache",
+		"X-Accel-Buffering": "no",
+	} {
+		if got := rec.Header().Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if len(rec.deadlines) != 1 || rec.deadlines[0].Before(start.Add(10*time.Second)) {
+		t.Errorf("deadlines %v, want one 10s from now", rec.deadlines)
+	}
+}
+
+func TestNewWriterWithoutDeadlines(t *testing.T) {
+	// Plain recorders cannot set write deadlines, so the stream could not
+	// outlive the server's write timeout
+	if _, err := NewWriter(httptest.NewRecorder(), time.Second); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("NewWriter error = %v, want ErrNotSupported", err)
+	}
+
+	rec := newRecorder()
+	rec.err = errors.New("connection closed")
+	if _, err := NewWriter(rec, time.Second); !errors.Is(err, rec.err) {
+		t.Errorf("NewWriter error = %v, want the deadline error", err)
+	}
+	if rec.Flushed || rec.Header().Get("Content-Type") != "" {
+		t.Errorf("failed writer started the stream: %v", rec.Header())
+	}
+}
+
+func TestWriterEvents(t *testing.T) {
+	rec := newRecorder()
+	sw, err := NewWriter(rec, time.Second)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+
+	steps := []struct {
+		write func() error
+		want  string
+	}{
+		{func() error { return sw.Event("7", "quote", []byte(`{"id":7}`)) }, "id: 7\nevent: quote\ndata: {\"id\":7}\n\n"},
+		{func() error { return sw.Event("", "", []byte("first\nsecond")) }, "data: first\ndata: second\n\n"},
+		{func() error { return sw.Event("", "", nil) }, "data: \n\n"},
+		{func() error { return sw.Comment("heartbeat") }, ": heartbeat\n\n"},
+		{func() error { return sw.Retry(1500 * time.Millisecond) }, "retry: 1500\n\n"},
+	}
+	for i, step := range steps {
+		rec.Body.Reset()
+		rec.Flushed = false
+		if err := step.write(); err != nil {
+			t.Fatalf("write %d failed: %v", i, err)
+		}
+		if got := rec.Body.String(); got != step.want || !rec.Flushed {
+			t.Errorf("write %d sent %q (flushed %v), want %q", i, got, rec.Flushed, step.want)
+		}
+	}
+
+	// Every write moves the deadline on
+	if len(rec.deadlines) != len(steps)+1 {
+		t.Errorf("%d deadlines set, want %d", len(rec.deadlines), len(steps)+1)
+	}
+}
+
+func TestWriterDeadlineError(t *testing.T) {
+	rec := newRecorder()
+	sw, err := NewWriter(rec, time.Second)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+
+	rec.Body.Reset()
+	rec.err = errors.New("connection closed")
+	if err := sw.Comment("heartbeat"); !errors.Is(err, rec.err) {
+		t.Errorf("Comment error = %v, want the deadline error", err)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("wrote %q after the deadline could not be set", rec.Body.String())
+	}
+}
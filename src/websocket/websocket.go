@@ -0,0 +1,331 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Frame opcodes (RFC 6455 section 5.2)
+const (
+	OpContinuation = 0x0
+	OpText         = 0x1
+	OpBinary       = 0x2
+	OpClose        = 0x8
+	OpPing         = 0x9
+	OpPong         = 0xa
+)
+
+// Close status codes (RFC 6455 section 7.4.1)
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseNoStatus        = 1005
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseTooLarge        = 1009
+	CloseInternalError   = 1011
+	CloseTryAgainLater   = 1013
+)
+
+const (
+	// acceptGUID is appended to the client key to compute Sec-WebSocket-Accept
+	acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	// maxControlPayload is the largest payload of a control frame
+	maxControlPayload = 125
+
+	// writeTimeout is how long one frame may take to reach the client
+	writeTimeout = 10 * time.Second
+)
+
+// CloseError is returned by ReadMessage once the connection is closing,
+// with the status code sent by the client or by the server on a protocol error
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket closed: %d %s", e.Code, e.Reason)
+}
+
+// AcceptKey returns the Sec-WebSocket-Accept value for a Sec-WebSocket-Key
+func AcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerContains reports whether a comma-separated header has the token,
+// ignoring case
+func headerContains(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Conn is a server-side WebSocket connection. ReadMessage must be called
+// from one goroutine; writes may come from any number of goroutines.
+type Conn struct {
+	conn           net.Conn
+	br             *bufio.Reader
+	maxMessageSize int
+	idleTimeout    time.Duration
+
+	writeMutex sync.Mutex
+	closeSent  bool
+}
+
+// Upgrade performs the opening handshake and takes over the connection.
+// Messages larger than maxMessageSize bytes are refused with CloseTooLarge.
+// When the request is not a valid WebSocket handshake, an HTTP error has
+// been sent and the error describes why.
+func Upgrade(w http.ResponseWriter, r *http.Request, maxMessageSize int) (*Conn, error) {
+	var problem string
+	switch {
+	case r.Method != http.MethodGet:
+		problem = "method must be GET"
+	case !headerContains(r.Header, "Connection", "upgrade"):
+		problem = "Connection header must contain upgrade"
+	case !headerContains(r.Header, "Upgrade", "websocket"):
+		problem = "Upgrade header must be websocket"
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		// Tell the client which version is supported (RFC 6455 section 4.4)
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, fmt.Errorf("unsupported WebSocket version %q", r.Header.Get("Sec-WebSocket-Version"))
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); problem == "" && (err != nil || len(decoded) != 16) {
+		problem = "Sec-WebSocket-Key must be 16 base64-encoded bytes"
+	}
+	if problem != "" {
+		http.Error(w, "bad WebSocket handshake: "+problem, http.StatusBadRequest)
+		return nil, errors.New("bad WebSocket handshake: " + problem)
+	}
+
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "WebSocket upgrade is not supported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("failed to hijack connection: %w", err)
+	}
+
+	// Clear the server's read and write timeouts, which apply to requests
+	conn.SetDeadline(time.Time{})
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + AcceptKey(key) + "\r\n\r\n"
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := conn.Write([]byte(response)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send handshake: %w", err)
+	}
+
+	return &Conn{conn: conn, br: brw.Reader, maxMessageSize: maxMessageSize}, nil
+}
+
+// RemoteAddr returns the network address of the client
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// SetIdleTimeout makes ReadMessage fail when no frame, pongs included,
+// arrives for d. Zero waits forever.
+func (c *Conn) SetIdleTimeout(d time.Duration) {
+	c.idleTimeout = d
+}
+
+// Close closes the underlying connection without a closing handshake
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// writeFrame sends a single unmasked frame with the FIN bit set
+func (c *Conn) writeFrame(op int, payload []byte) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	if c.closeSent {
+		return net.ErrClosed
+	}
+	if op == OpClose {
+		c.closeSent = true
+	}
+
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | byte(op)
+	switch n := len(payload); {
+	case n <= 125:
+		header[1] = byte(n)
+	case n <= 0xffff:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// WriteMessage sends a text or binary message
+func (c *Conn) WriteMessage(op int, data []byte) error {
+	if op != OpText && op != OpBinary {
+		return fmt.Errorf("invalid message opcode %d", op)
+	}
+	return c.writeFrame(op, data)
+}
+
+// Ping sends a ping frame; the client answers with a pong
+func (c *Conn) Ping(data []byte) error {
+	if len(data) > maxControlPayload {
+		return fmt.Errorf("ping payload must be at most %d bytes", maxControlPayload)
+	}
+	return c.writeFrame(OpPing, data)
+}
+
+// WriteClose starts or completes the closing handshake with a status code
+func (c *Conn) WriteClose(code int, reason string) error {
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	payload = append(payload, reason...)
+	if len(payload) > maxControlPayload {
+		payload = payload[:maxControlPayload]
+	}
+	return c.writeFrame(OpClose, payload)
+}
+
+// fail closes the connection after a protocol violation by the client
+func (c *Conn) fail(code int, reason string) error {
+	c.WriteClose(code, reason)
+	return &CloseError{Code: code, Reason: reason}
+}
+
+// ReadMessage returns the next text or binary message, reassembling
+// fragmented messages and answering pings along the way. Once the client
+// closes the connection or breaks the protocol, the closing handshake is
+// answered and a *CloseError is returned.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	var message []byte
+	messageOp := -1
+
+	for {
+		if c.idleTimeout > 0 {
+			c.conn.SetReadDeadline(time.Now().Add(c.idleTimeout))
+		}
+		var head [2]byte
+		if _, err := io.ReadFull(c.br, head[:]); err != nil {
+			return 0, nil, err
+		}
+		fin := head[0]&0x80 != 0
+		op := int(head[0] & 0x0f)
+		masked := head[1]&0x80 != 0
+		length := uint64(head[1] & 0x7f)
+
+		if head[0]&0x70 != 0 {
+			return 0, nil, c.fail(CloseProtocolError, "reserved bits set")
+		}
+		if !masked {
+			return 0, nil, c.fail(CloseProtocolError, "client frames must be masked")
+		}
+
+		switch length {
+		case 126:
+			var ext [2]byte
+			if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+				return 0, nil, err
+			}
+			length = uint64(binary.BigEndian.Uint16(ext[:]))
+		case 127:
+			var ext [8]byte
+			if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+				return 0, nil, err
+			}
+			length = binary.BigEndian.Uint64(ext[:])
+		}
+
+		control := op >= OpClose
+		switch {
+		case control && (!fin || length > maxControlPayload):
+			return 0, nil, c.fail(CloseProtocolError, "invalid control frame")
+		case op > OpBinary && !control || op > OpPong:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		case op == OpContinuation && messageOp < 0:
+			return 0, nil, c.fail(CloseProtocolError, "continuation without a message")
+		case (op == OpText || op == OpBinary) && messageOp >= 0:
+			return 0, nil, c.fail(CloseProtocolError, "new message before the last one ended")
+		case !control && uint64(len(message))+length > uint64(c.maxMessageSize):
+			return 0, nil, c.fail(CloseTooLarge, "message too large")
+		}
+
+		var mask [4]byte
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return 0, nil, err
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(c.br, payload); err != nil {
+			return 0, nil, err
+		}
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+
+		switch op {
+		case OpPing:
+			if err := c.writeFrame(OpPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case OpPong:
+			continue
+		case OpClose:
+			code, reason := CloseNoStatus, ""
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+				reason = string(payload[2:])
+			}
+			// Echo the status code to complete the closing handshake
+			if code == CloseNoStatus {
+				c.writeFrame(OpClose, nil)
+			} else {
+				c.WriteClose(code, "")
+			}
+			return 0, nil, &CloseError{Code: code, Reason: reason}
+		}
+
+		if op != OpContinuation {
+			messageOp = op
+		}
+		message = append(message, payload...)
+		if !fin {
+			continue
+		}
+
+		if messageOp == OpText && !utf8.Valid(message) {
+			return 0, nil, c.fail(CloseInvalidPayload, "text message is not valid UTF-8")
+		}
+		return messageOp, message, nil
+	}
+}
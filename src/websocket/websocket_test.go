@@ -0,0 +1,228 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The handshake example of RFC 6455 section 1.3
+func TestAcceptKey(t *testing.T) {
+	if got, want := AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="), "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="; got != want {
+		t.Errorf("AcceptKey() = %q, want %q", got, want)
+	}
+}
+
+// echoServer starts a server echoing every message until the connection closes
+func echoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, 1024)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			op, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(op, data)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// testClient is a minimal client speaking raw frames
+type testClient struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+// dial connects to srv and completes the opening handshake
+func dial(t *testing.T, srv *httptest.Server) *testClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	io.WriteString(conn, "GET / HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: "+key+"\r\nSec-WebSocket-Version: 13\r\n\r\n")
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("failed to read handshake response: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake status = %d, want 101", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != AcceptKey(key) {
+		t.Fatalf("Sec-WebSocket-Accept = %q, want %q", got, AcceptKey(key))
+	}
+	return &testClient{conn: conn, br: br}
+}
+
+// send writes a frame, masked unless unmasked is set
+func (c *testClient) send(fin bool, op int, payload []byte, unmasked bool) {
+	b0 := byte(op)
+	if fin {
+		b0 |= 0x80
+	}
+	frame := []byte{b0}
+	maskBit := byte(0x80)
+	if unmasked {
+		maskBit = 0
+	}
+	switch {
+	case len(payload) <= 125:
+		frame = append(frame, maskBit|byte(len(payload)))
+	default:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	}
+	if unmasked {
+		c.conn.Write(append(frame, payload...))
+		return
+	}
+	mask := []byte{0x12, 0x34, 0x56, 0x78}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	c.conn.Write(frame)
+}
+
+// receive reads one unfragmented, unmasked frame
+func (c *testClient) receive(t *testing.T) (int, []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		t.Fatalf("failed to read frame: %v", err)
+	}
+	if head[1]&0x80 != 0 {
+		t.Fatalf("server frame is masked")
+	}
+	length := int(head[1] & 0x7f)
+	if length == 126 {
+		var ext [2]byte
+		io.ReadFull(c.br, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		t.Fatalf("failed to read payload: %v", err)
+	}
+	return int(head[0] & 0x0f), payload
+}
+
+// expectClose reads a close frame and checks its status code
+func (c *testClient) expectClose(t *testing.T, code int) {
+	t.Helper()
+	op, payload := c.receive(t)
+	if op != OpClose || len(payload) < 2 {
+		t.Fatalf("got frame %d %q, want a close frame", op, payload)
+	}
+	if got := int(binary.BigEndian.Uint16(payload)); got != code {
+		t.Errorf("close code = %d, want %d", got, code)
+	}
+}
+
+func TestEcho(t *testing.T) {
+	c := dial(t, echoServer(t))
+
+	c.send(true, OpText, []byte("hello"), false)
+	if op, data := c.receive(t); op != OpText || string(data) != "hello" {
+		t.Errorf("echo = %d %q, want text %q", op, data, "hello")
+	}
+
+	long := strings.Repeat("x", 300)
+	c.send(true, OpBinary, []byte(long), false)
+	if op, data := c.receive(t); op != OpBinary || string(data) != long {
+		t.Errorf("echo of a 300 byte message = %d, %d bytes", op, len(data))
+	}
+}
+
+func TestFragmentsAndPing(t *testing.T) {
+	c := dial(t, echoServer(t))
+
+	c.send(false, OpText, []byte("frag"), false)
+	c.send(true, OpPing, []byte("are you there"), false)
+	c.send(true, OpContinuation, []byte("mented"), false)
+
+	if op, data := c.receive(t); op != OpPong || string(data) != "are you there" {
+		t.Errorf("got %d %q, want the pong first", op, data)
+	}
+	if op, data := c.receive(t); op != OpText || string(data) != "fragmented" {
+		t.Errorf("got %d %q, want the reassembled message", op, data)
+	}
+}
+
+func TestClosingHandshake(t *testing.T) {
+	c := dial(t, echoServer(t))
+	c.send(true, OpClose, binary.BigEndian.AppendUint16(nil, CloseGoingAway), false)
+	c.expectClose(t, CloseGoingAway)
+}
+
+func TestProtocolErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		send func(c *testClient)
+		code int
+	}{
+		{"unmasked", func(c *testClient) { c.send(true, OpText, []byte("hi"), true) }, CloseProtocolError},
+		{"unknown opcode", func(c *testClient) { c.send(true, 0x3, nil, false) }, CloseProtocolError},
+		{"stray continuation", func(c *testClient) { c.send(true, OpContinuation, []byte("x"), false) }, CloseProtocolError},
+		{"fragmented ping", func(c *testClient) { c.send(false, OpPing, nil, false) }, CloseProtocolError},
+		{"too large", func(c *testClient) { c.send(true, OpText, make([]byte, 2000), false) }, CloseTooLarge},
+		{"invalid UTF-8", func(c *testClient) { c.send(true, OpText, []byte{0xff, 0xfe}, false) }, CloseInvalidPayload},
+	}
+
+	srv := echoServer(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := dial(t, srv)
+			tt.send(c)
+			c.expectClose(t, tt.code)
+		})
+	}
+}
+
+func TestBadHandshake(t *testing.T) {
+	srv := echoServer(t)
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("plain GET status = %d, want 400", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "8")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUpgradeRequired || resp.Header.Get("Sec-WebSocket-Version") != "13" {
+		t.Errorf("old version status = %d, version header %q; want 426 and 13",
+			resp.StatusCode, resp.Header.Get("Sec-WebSocket-Version"))
+	}
+}
@@ -0,0 +1,462 @@
+package weighting
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/apimgr/quotes/src/collection"
+)
+
+const (
+	// MinWeight and MaxWeight bound admin-assigned weights. Zero is not
+	// allowed so boosting some items never removes the rest.
+	MinWeight = 0.01
+	MaxWeight = 1000
+
+	// MinRating and MaxRating bound user ratings
+	MinRating = 1
+	MaxRating = 5
+
+	// ratingPrior is the number of neutral ratings every item starts with,
+	// so a single vote cannot swing an item's weight
+	ratingPrior = 5
+
+	// maxRejections bounds the redraws spent avoiding recently served items
+	maxRejections = 32
+)
+
+// Rating is the sum and number of user ratings of an item
+type Rating struct {
+	Sum   int
+	Count int
+}
+
+// Average returns the mean rating, or 0 for an unrated item
+func (r Rating) Average() float64 {
+	if r.Count == 0 {
+		return 0
+	}
+	return float64(r.Sum) / float64(r.Count)
+}
+
+// factor returns the weight multiplier for the rating: 1 for neutral
+// ratings, up to 5/3 for top ratings and down to 1/3 for bottom ratings
+func (r Rating) factor() float64 {
+	neutral := float64(MinRating+MaxRating) / 2
+	mean := (float64(r.Sum) + ratingPrior*neutral) / float64(r.Count+ratingPrior)
+	return mean / neutral
+}
+
+// Store persists weights and ratings outside of memory
+type Store interface {
+	// LoadWeights returns all admin-assigned weights by collection and item ID
+	LoadWeights() (map[string]map[int]float64, error)
+
+	// SaveWeight saves the admin-assigned weight of an item
+	SaveWeight(name string, id int, weight float64) error
+
+	// DeleteWeight removes the admin-assigned weight of an item
+	DeleteWeight(name string, id int) error
+
+	// LoadRatings returns all ratings by collection and item ID
+	LoadRatings() (map[string]map[int]Rating, error)
+
+	// AddRating records one user rating of an item
+	AddRating(name string, id int, rating int) error
+}
+
+// collectionState holds the weights of one collection and its sampler
+type collectionState struct {
+	mutex   sync.Mutex // Guards the weights, ratings and recent picks
+	weights map[int]float64
+	ratings map[int]Rating
+
+	// version counts the changes to weights and ratings, so picks notice
+	// when their sampler is stale
+	version atomic.Uint64
+
+	// sampler is the latest snapshot of the weights, replaced as a whole so
+	// picks read it without locking; building is set while one is rebuilt
+	sampler  atomic.Pointer[sampler]
+	building atomic.Bool
+
+	// recent is a ring of recently served item IDs, counted in recentCount
+	recent      []int
+	recentNext  int
+	recentCount map[int]int
+}
+
+// sampler is an immutable snapshot of the weights of a collection's items
+type sampler struct {
+	table    *collection.AliasTable // Samples items in proportion to their weight
+	items    []collection.Item
+	factors  map[int]float64 // Weights of the weighted or rated items
+	loadedAt time.Time       // When the collection had the items of the table
+	version  uint64          // Version of the weights and ratings used
+}
+
+// weight returns the selection weight of an item, ignoring recency
+func (s *sampler) weight(id int) float64 {
+	if factor, ok := s.factors[id]; ok {
+		return factor
+	}
+	return 1
+}
+
+// Manager combines admin-assigned weights, user ratings and a penalty for
+// recently served items into weighted random selection. The weight of an
+// item is its admin weight (default 1) times its rating factor; items among
+// the last window picks of their collection are kept with probability penalty.
+//
+// Each collection has its own lock, held only to read or change its weights
+// and recent picks. Picks sample from an immutable snapshot of the weights,
+// which is rebuilt in the background after the weights or items change.
+type Manager struct {
+	mutex       sync.RWMutex // Guards collections
+	collections map[string]*collectionState
+	window      int
+	penalty     float64
+	store       Store
+}
+
+// NewManager creates a manager remembering the last window picks of each
+// collection. store may be nil to keep weights and ratings in memory only.
+func NewManager(window int, penalty float64, store Store) *Manager {
+	return &Manager{
+		collections: make(map[string]*collectionState),
+		window:      window,
+		penalty:     penalty,
+		store:       store,
+	}
+}
+
+// Load reads the saved weights and ratings from the store
+func (m *Manager) Load() error {
+	if m.store == nil {
+		return nil
+	}
+
+	weights, err := m.store.LoadWeights()
+	if err != nil {
+		return fmt.Errorf("failed to load weights: %w", err)
+	}
+	ratings, err := m.store.LoadRatings()
+	if err != nil {
+		return fmt.Errorf("failed to load ratings: %w", err)
+	}
+
+	for name, byID := range weights {
+		state := m.state(name)
+		state.mutex.Lock()
+		for id, weight := range byID {
+			state.weights[id] = weight
+		}
+		state.version.Add(1)
+		state.mutex.Unlock()
+	}
+	for name, byID := range ratings {
+		state := m.state(name)
+		state.mutex.Lock()
+		for id, rating := range byID {
+			state.ratings[id] = rating
+		}
+		state.version.Add(1)
+		state.mutex.Unlock()
+	}
+	return nil
+}
+
+// state returns the state of a collection, creating it if needed
+func (m *Manager) state(name string) *collectionState {
+	m.mutex.RLock()
+	state, ok := m.collections[name]
+	m.mutex.RUnlock()
+	if ok {
+		return state
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if state, ok = m.collections[name]; !ok {
+		state = &collectionState{
+			weights:     make(map[int]float64),
+			ratings:     make(map[int]Rating),
+			recent:      make([]int, 0, m.window),
+			recentCount: make(map[int]int),
+		}
+		m.collections[name] = state
+	}
+	return state
+}
+
+// weight returns the selection weight of an item, ignoring recency.
+// The caller holds the state lock.
+func (s *collectionState) weight(id int) float64 {
+	weight, ok := s.weights[id]
+	if !ok {
+		weight = 1
+	}
+	return weight * s.ratings[id].factor()
+}
+
+// Weights returns the admin-assigned weights of a collection by item ID
+func (m *Manager) Weights(name string) map[int]float64 {
+	state := m.state(name)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	result := make(map[int]float64, len(state.weights))
+	for id, weight := range state.weights {
+		result[id] = weight
+	}
+	return result
+}
+
+// SetWeight assigns a weight to an item
+func (m *Manager) SetWeight(name string, id int, weight float64) error {
+	if weight < MinWeight || weight > MaxWeight {
+		return fmt.Errorf("weight must be between %g and %g", float64(MinWeight), float64(MaxWeight))
+	}
+	if m.store != nil {
+		if err := m.store.SaveWeight(name, id, weight); err != nil {
+			return err
+		}
+	}
+
+	state := m.state(name)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	state.weights[id] = weight
+	state.version.Add(1)
+	return nil
+}
+
+// ResetWeight restores the default weight of an item
+func (m *Manager) ResetWeight(name string, id int) error {
+	if m.store != nil {
+		if err := m.store.DeleteWeight(name, id); err != nil {
+			return err
+		}
+	}
+
+	state := m.state(name)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	delete(state.weights, id)
+	state.version.Add(1)
+	return nil
+}
+
+// Rating returns the ratings of an item
+func (m *Manager) Rating(name string, id int) Rating {
+	state := m.state(name)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	return state.ratings[id]
+}
+
+// Rate records a user rating of an item and returns its updated ratings
+func (m *Manager) Rate(name string, id int, rating int) (Rating, error) {
+	if rating < MinRating || rating > MaxRating {
+		return Rating{}, fmt.Errorf("rating must be between %d and %d", MinRating, MaxRating)
+	}
+	if m.store != nil {
+		if err := m.store.AddRating(name, id, rating); err != nil {
+			return Rating{}, err
+		}
+	}
+
+	// Picks keep the current sampler until a rebuild in the background
+	// catches up, so a rating costs constant time
+	state := m.state(name)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	updated := state.ratings[id]
+	updated.Sum += rating
+	updated.Count++
+	state.ratings[id] = updated
+	state.version.Add(1)
+	return updated, nil
+}
+
+// RandomItems returns up to count distinct items of c that pass the filter,
+// drawn in proportion to their weights. A single unfiltered pick is constant
+// time from the alias table of the collection's sampler; other picks weigh
+// every candidate. No lock is held while sampling. When avoidRecent is set,
+// recently served items are penalized and the picks are remembered.
+func (m *Manager) RandomItems(c collection.Collection, f collection.Filter, count int, rng *rand.Rand, avoidRecent bool) ([]collection.Item, error) {
+	info := c.Info()
+	if c.Count() == 0 {
+		return nil, fmt.Errorf("no %s available", info.Title)
+	}
+
+	var candidates []collection.Item
+	if !f.IsEmpty() {
+		candidates = collection.FilterItems(c, f)
+		if len(candidates) == 0 {
+			return nil, collection.ErrNoMatch
+		}
+	}
+
+	state := m.state(info.Name)
+	snapshot := m.sampler(state, c)
+
+	var result []collection.Item
+	if candidates == nil && count == 1 && len(snapshot.items) > 0 {
+		result = []collection.Item{m.pickOne(state, snapshot, rng, avoidRecent)}
+	} else {
+		if candidates == nil {
+			candidates = c.Items()
+		}
+		var recent map[int]bool
+		if avoidRecent {
+			recent = state.recentSet()
+		}
+		weights := make([]float64, len(candidates))
+		for i, item := range candidates {
+			weights[i] = snapshot.weight(item.GetID())
+			if recent[item.GetID()] {
+				weights[i] *= m.penalty
+			}
+		}
+		result = collection.WeightedSample(candidates, weights, count, rng)
+	}
+
+	if avoidRecent {
+		state.mutex.Lock()
+		for _, item := range result {
+			m.remember(state, item.GetID())
+		}
+		state.mutex.Unlock()
+	}
+	return result, nil
+}
+
+// sampler returns the sampler of a collection. It is built on the first
+// pick and again when the items change, so deleted items are never served.
+// When only weights or ratings have changed, picks keep using the current
+// sampler while a new one is built in the background.
+func (m *Manager) sampler(state *collectionState, c collection.Collection) *sampler {
+	snapshot := state.sampler.Load()
+	if snapshot == nil || !snapshot.loadedAt.Equal(c.LoadedAt()) {
+		snapshot = buildSampler(state, c)
+		state.publish(snapshot)
+		return snapshot
+	}
+
+	if snapshot.version != state.version.Load() && state.building.CompareAndSwap(false, true) {
+		go func() {
+			defer state.building.Store(false)
+			state.publish(buildSampler(state, c))
+		}()
+	}
+	return snapshot
+}
+
+// publish replaces the sampler unless a newer one was published meanwhile
+func (s *collectionState) publish(snapshot *sampler) {
+	for {
+		current := s.sampler.Load()
+		if current != nil && (current.loadedAt.After(snapshot.loadedAt) ||
+			current.loadedAt.Equal(snapshot.loadedAt) && current.version > snapshot.version) {
+			return
+		}
+		if s.sampler.CompareAndSwap(current, snapshot) {
+			return
+		}
+	}
+}
+
+// buildSampler snapshots the weights of the items of c and builds their
+// alias table, holding the state lock only while copying the weights
+func buildSampler(state *collectionState, c collection.Collection) *sampler {
+	state.mutex.Lock()
+	snapshot := &sampler{
+		factors: make(map[int]float64, len(state.weights)+len(state.ratings)),
+		version: state.version.Load(),
+	}
+	for id := range state.weights {
+		snapshot.factors[id] = state.weight(id)
+	}
+	for id := range state.ratings {
+		snapshot.factors[id] = state.weight(id)
+	}
+	state.mutex.Unlock()
+
+	// Read the time first, so items changed in between make the sampler
+	// look stale rather than current
+	snapshot.loadedAt = c.LoadedAt()
+	snapshot.items = c.Items()
+	weights := make([]float64, len(snapshot.items))
+	for i, item := range snapshot.items {
+		weights[i] = snapshot.weight(item.GetID())
+	}
+	snapshot.table = collection.NewAliasTable(weights)
+	return snapshot
+}
+
+// recentSet returns the IDs of the recently served items
+func (s *collectionState) recentSet() map[int]bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	set := make(map[int]bool, len(s.recentCount))
+	for id := range s.recentCount {
+		set[id] = true
+	}
+	return set
+}
+
+// isRecent reports whether an item was served recently
+func (s *collectionState) isRecent(id int) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.recentCount[id] > 0
+}
+
+// pickOne draws one item from the sampler's alias table. Recently served
+// items are redrawn with probability 1-penalty, which scales their weight
+// by penalty without rebuilding the table.
+func (m *Manager) pickOne(state *collectionState, snapshot *sampler, rng *rand.Rand, avoidRecent bool) collection.Item {
+	var item collection.Item
+	for attempt := 0; attempt < maxRejections; attempt++ {
+		item = snapshot.items[snapshot.table.Pick(rng)]
+		if !avoidRecent || !state.isRecent(item.GetID()) {
+			break
+		}
+		if randFloat(rng) < m.penalty {
+			break
+		}
+	}
+	return item
+}
+
+// randFloat returns a random float in [0, 1) from rng, or from the shared
+// generator when rng is nil
+func randFloat(rng *rand.Rand) float64 {
+	if rng == nil {
+		return rand.Float64()
+	}
+	return rng.Float64()
+}
+
+// remember records a served item, forgetting the oldest once the window is
+// full. The caller holds the state lock.
+func (m *Manager) remember(state *collectionState, id int) {
+	if m.window <= 0 {
+		return
+	}
+	if len(state.recent) < m.window {
+		state.recent = append(state.recent, id)
+	} else {
+		oldest := state.recent[state.recentNext]
+		if state.recentCount[oldest]--; state.recentCount[oldest] <= 0 {
+			delete(state.recentCount, oldest)
+		}
+		state.recent[state.recentNext] = id
+		state.recentNext = (state.recentNext + 1) % m.window
+	}
+	state.recentCount[id]++
+}
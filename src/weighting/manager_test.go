@@ -0,0 +1,282 @@
+package weighting
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apimgr/quotes/src/collection"
+)
+
+// testItem is a minimal item used to build synthetic collections
+type testItem struct {
+	ID       int    `json:"id"`
+	Text     string `json:"text"`
+	Category string `json:"category"`
+}
+
+func (t testItem) GetID() int {
+	return t.ID
+}
+
+func (t testItem) Field(name string) string {
+	switch name {
+	case "text":
+		return t.Text
+	case "category":
+		return t.Category
+	}
+	return ""
+}
+
+// newTestStore builds a loaded collection with size items in two categories
+func newTestStore(tb testing.TB, size int) *collection.Store[testItem] {
+	tb.Helper()
+
+	items := make([]testItem, size)
+	for i := range items {
+		items[i] = testItem{
+			ID:       i + 1,
+			Text:     fmt.Sprintf("item %d", i+1),
+			Category: fmt.Sprintf("Category %d", i%2),
+		}
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		tb.Fatalf("failed to marshal items: %v", err)
+	}
+
+	store := collection.New[testItem](collection.Info{
+		Name:      "test",
+		Title:     "test items",
+		ItemName:  "test item",
+		TextField: "text",
+		Fields:    []collection.Field{{Name: "category", Path: "category"}},
+	})
+	if err := store.Load(data); err != nil {
+		tb.Fatalf("Load failed: %v", err)
+	}
+	return store
+}
+
+// share returns how often item id is picked out of draws single picks
+func share(t *testing.T, m *Manager, c collection.Collection, f collection.Filter, id, draws int) float64 {
+	t.Helper()
+
+	rng := collection.NewSeededRand(1)
+	hits := 0
+	for i := 0; i < draws; i++ {
+		items, err := m.RandomItems(c, f, 1, rng, false)
+		if err != nil {
+			t.Fatalf("RandomItems failed: %v", err)
+		}
+		if items[0].GetID() == id {
+			hits++
+		}
+	}
+	return float64(hits) / float64(draws)
+}
+
+// waitForSampler waits until the sampler of the collection uses the
+// current weights and ratings
+func waitForSampler(t *testing.T, m *Manager, c collection.Collection) {
+	t.Helper()
+
+	state := m.state(c.Info().Name)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		m.sampler(state, c)
+		if state.sampler.Load().version == state.version.Load() {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("sampler not rebuilt after the weights changed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRatingFactor(t *testing.T) {
+	tests := []struct {
+		rating Rating
+		want   float64
+	}{
+		{Rating{}, 1},
+		{Rating{Sum: 3, Count: 1}, 1},
+		{Rating{Sum: 5000, Count: 1000}, 5.0 / 3},
+		{Rating{Sum: 1000, Count: 1000}, 1.0 / 3},
+	}
+	for _, tt := range tests {
+		if got := tt.rating.factor(); math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("factor of %+v = %.3f, want %.3f", tt.rating, got, tt.want)
+		}
+	}
+}
+
+func TestSetWeightBounds(t *testing.T) {
+	m := NewManager(0, 1, nil)
+	if err := m.SetWeight("test", 1, 0); err == nil {
+		t.Error("weight 0 accepted")
+	}
+	if err := m.SetWeight("test", 1, MaxWeight+1); err == nil {
+		t.Error("weight above the maximum accepted")
+	}
+	if err := m.SetWeight("test", 1, 2); err != nil {
+		t.Fatalf("SetWeight failed: %v", err)
+	}
+	if got := m.Weights("test"); got[1] != 2 {
+		t.Errorf("Weights = %v, want item 1 weighted 2", got)
+	}
+	if err := m.ResetWeight("test", 1); err != nil {
+		t.Fatalf("ResetWeight failed: %v", err)
+	}
+	if got := m.Weights("test"); len(got) != 0 {
+		t.Errorf("Weights = %v after reset, want none", got)
+	}
+}
+
+func TestRate(t *testing.T) {
+	m := NewManager(0, 1, nil)
+	if _, err := m.Rate("test", 1, MaxRating+1); err == nil {
+		t.Error("rating above the maximum accepted")
+	}
+	m.Rate("test", 1, 5)
+	rating, err := m.Rate("test", 1, 2)
+	if err != nil {
+		t.Fatalf("Rate failed: %v", err)
+	}
+	if rating.Count != 2 || rating.Average() != 3.5 {
+		t.Errorf("rating = %+v, want 2 votes averaging 3.5", rating)
+	}
+	if got := m.Rating("test", 1); got != rating {
+		t.Errorf("Rating = %+v, want %+v", got, rating)
+	}
+}
+
+func TestWeightsChangePicks(t *testing.T) {
+	store := newTestStore(t, 10)
+	m := NewManager(0, 1, nil)
+
+	if got := share(t, m, store, collection.Filter{}, 1, 20000); math.Abs(got-0.1) > 0.02 {
+		t.Errorf("unweighted item picked %.3f of the time, want 0.1", got)
+	}
+
+	// Weight 10 among nine items of weight 1: 10/19 of the picks
+	m.SetWeight("test", 1, 10)
+	waitForSampler(t, m, store)
+	if got := share(t, m, store, collection.Filter{}, 1, 20000); math.Abs(got-10.0/19) > 0.02 {
+		t.Errorf("weighted item picked %.3f of the time, want %.3f", got, 10.0/19)
+	}
+
+	// The filtered path weighs the candidates the same way: 10/14 within
+	// the five items of its category
+	f := collection.Filter{Fields: map[string]string{"category": "Category 0"}}
+	if got := share(t, m, store, f, 1, 20000); math.Abs(got-10.0/14) > 0.02 {
+		t.Errorf("weighted item picked %.3f of the filtered picks, want %.3f", got, 10.0/14)
+	}
+}
+
+func TestRatingsRebuildSampler(t *testing.T) {
+	store := newTestStore(t, 10)
+	m := NewManager(0, 1, nil)
+	share(t, m, store, collection.Filter{}, 1, 1)
+
+	state := m.state("test")
+	before := state.sampler.Load()
+	for i := 0; i < 1000; i++ {
+		m.Rate("test", 1, MinRating)
+	}
+	if state.sampler.Load() != before {
+		t.Error("rating rebuilt the sampler synchronously")
+	}
+
+	// A factor of about 1/3 among nine items of weight 1: 1/28 of the picks
+	waitForSampler(t, m, store)
+	if got := share(t, m, store, collection.Filter{}, 1, 20000); math.Abs(got-1.0/28) > 0.01 {
+		t.Errorf("low-rated item picked %.3f of the time, want %.3f", got, 1.0/28)
+	}
+}
+
+func TestItemChangesRebuildSampler(t *testing.T) {
+	store := newTestStore(t, 3)
+	m := NewManager(0, 1, nil)
+	share(t, m, store, collection.Filter{}, 1, 1)
+
+	// Reloading with fewer items must never serve a removed one
+	data, _ := json.Marshal([]testItem{{ID: 1, Text: "only", Category: "Category 0"}})
+	if err := store.Load(data); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := share(t, m, store, collection.Filter{}, 1, 100); got != 1 {
+		t.Errorf("remaining item picked %.2f of the time after a reload, want 1", got)
+	}
+}
+
+func TestRecentPenalty(t *testing.T) {
+	store := newTestStore(t, 5)
+	m := NewManager(4, 0, nil)
+	rng := collection.NewSeededRand(1)
+
+	// With a zero penalty and a window of four, every five picks in a row
+	// are the five distinct items
+	seen := make(map[int]bool)
+	for i := 0; i < 5; i++ {
+		items, err := m.RandomItems(store, collection.Filter{}, 1, rng, true)
+		if err != nil {
+			t.Fatalf("RandomItems failed: %v", err)
+		}
+		seen[items[0].GetID()] = true
+	}
+	if len(seen) != 5 {
+		t.Errorf("five picks returned %d distinct items, want 5", len(seen))
+	}
+}
+
+func TestRandomItemsErrors(t *testing.T) {
+	store := newTestStore(t, 4)
+	m := NewManager(0, 1, nil)
+
+	f := collection.Filter{Fields: map[string]string{"category": "missing"}}
+	if _, err := m.RandomItems(store, f, 1, collection.NewSeededRand(1), false); err != collection.ErrNoMatch {
+		t.Errorf("err = %v, want ErrNoMatch", err)
+	}
+
+	items, err := m.RandomItems(store, collection.Filter{}, 10, collection.NewSeededRand(1), false)
+	if err != nil {
+		t.Fatalf("RandomItems failed: %v", err)
+	}
+	if len(items) != 4 {
+		t.Errorf("got %d items, want all 4", len(items))
+	}
+}
+
+func TestConcurrentPicksAndRatings(t *testing.T) {
+	store := newTestStore(t, 100)
+	m := NewManager(10, 0.1, nil)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			rng := collection.NewSeededRand(uint64(g))
+			for i := 0; i < 500; i++ {
+				switch i % 4 {
+				case 0:
+					m.Rate("test", i%100+1, i%MaxRating+1)
+				case 1:
+					m.SetWeight("test", i%100+1, 2)
+				default:
+					if _, err := m.RandomItems(store, collection.Filter{}, i%3+1, rng, true); err != nil {
+						t.Errorf("RandomItems failed: %v", err)
+						return
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}